@@ -0,0 +1,153 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+)
+
+type ancestryKey struct{}
+
+// Ancestor a field resolved above the current resolver
+type Ancestor struct {
+	FieldName  string                 // the schema field name
+	Alias      string                 // the response key, the field name when not aliased
+	ParentType string                 // the type the field belongs to
+	Args       map[string]interface{} // the coerced field arguments
+	Source     interface{}            // the source the field was resolved on
+	Path       *graphql.ResponsePath
+}
+
+// per request ancestors keyed by response path
+type ancestryStore struct {
+	mx        sync.RWMutex
+	ancestors map[string]*Ancestor
+}
+
+// records the ancestors of each resolver for the request
+type ancestryExtension struct{}
+
+// Ancestors gets the fields resolved above the current resolver ordered from
+// the parent field to the root field. List indexes are skipped so the parent
+// of a list item field is the list field. Requires the schema to be made with
+// Ancestry enabled, otherwise nil is returned
+func Ancestors(p graphql.ResolveParams) []*Ancestor {
+	if p.Context == nil {
+		return nil
+	}
+
+	store, ok := p.Context.Value(ancestryKey{}).(*ancestryStore)
+	if !ok || p.Info.Path == nil {
+		return nil
+	}
+
+	store.mx.RLock()
+	defer store.mx.RUnlock()
+
+	ancestors := []*Ancestor{}
+	for path := p.Info.Path.Prev; path != nil; path = path.Prev {
+		if _, ok := path.Key.(int); ok {
+			continue
+		}
+		if ancestor, ok := store.ancestors[responsePathString(path)]; ok {
+			ancestors = append(ancestors, ancestor)
+		}
+	}
+
+	return ancestors
+}
+
+// FindAncestor gets the nearest ancestor with the field name
+func FindAncestor(p graphql.ResolveParams, fieldName string) *Ancestor {
+	for _, ancestor := range Ancestors(p) {
+		if ancestor.FieldName == fieldName {
+			return ancestor
+		}
+	}
+	return nil
+}
+
+// wraps the resolvers of each object field to record the field as an
+// ancestor before it is resolved
+func recordAncestry(schema graphql.Schema) {
+	for _, t := range schema.TypeMap() {
+		object, ok := t.(*graphql.Object)
+		if !ok || strings.HasPrefix(object.Name(), "__") {
+			continue
+		}
+
+		for _, field := range object.Fields() {
+			resolve := field.Resolve
+			if resolve == nil {
+				resolve = graphql.DefaultResolveFn
+			}
+
+			field.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+				if p.Context != nil && p.Info.Path != nil {
+					if store, ok := p.Context.Value(ancestryKey{}).(*ancestryStore); ok {
+						alias, _ := p.Info.Path.Key.(string)
+						ancestor := &Ancestor{
+							FieldName:  p.Info.FieldName,
+							Alias:      alias,
+							ParentType: p.Info.ParentType.Name(),
+							Args:       p.Args,
+							Source:     p.Source,
+							Path:       p.Info.Path,
+						}
+
+						store.mx.Lock()
+						store.ancestors[responsePathString(p.Info.Path)] = ancestor
+						store.mx.Unlock()
+					}
+				}
+
+				return resolve(p)
+			}
+		}
+	}
+}
+
+// Init creates the per request ancestor store
+func (c *ancestryExtension) Init(ctx context.Context, p *graphql.Params) context.Context {
+	return context.WithValue(contextOrBackground(ctx), ancestryKey{}, &ancestryStore{
+		ancestors: map[string]*Ancestor{},
+	})
+}
+
+// Name returns the extension name
+func (c *ancestryExtension) Name() string {
+	return "ancestry"
+}
+
+// ParseDidStart is not used
+func (c *ancestryExtension) ParseDidStart(ctx context.Context) (context.Context, graphql.ParseFinishFunc) {
+	return ctx, func(err error) {}
+}
+
+// ValidationDidStart is not used
+func (c *ancestryExtension) ValidationDidStart(ctx context.Context) (context.Context, graphql.ValidationFinishFunc) {
+	return ctx, func(errs []gqlerrors.FormattedError) {}
+}
+
+// ExecutionDidStart is not used
+func (c *ancestryExtension) ExecutionDidStart(ctx context.Context) (context.Context, graphql.ExecutionFinishFunc) {
+	return ctx, func(r *graphql.Result) {}
+}
+
+// ResolveFieldDidStart is not used
+func (c *ancestryExtension) ResolveFieldDidStart(ctx context.Context, info *graphql.ResolveInfo) (context.Context, graphql.ResolveFieldFinishFunc) {
+	return ctx, func(v interface{}, err error) {}
+}
+
+// HasResult is always false
+func (c *ancestryExtension) HasResult() bool {
+	return false
+}
+
+// GetResult is not used
+func (c *ancestryExtension) GetResult(ctx context.Context) interface{} {
+	return nil
+}
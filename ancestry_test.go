@@ -0,0 +1,89 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/graphql-go/graphql"
+)
+
+func TestAncestors(t *testing.T) {
+	var ancestors []*Ancestor
+
+	schema, err := MakeExecutableSchema(ExecutableSchema{
+		TypeDefs: `
+type Comment {
+	text: String
+}
+
+type Post {
+	title: String
+	comments: [Comment]
+}
+
+type User {
+	name: String
+	posts(first: Int): [Post]
+}
+
+type Query {
+	user(id: ID!): User
+}`,
+		Ancestry: true,
+		Resolvers: ResolverMap{
+			"Query": &ObjectResolver{
+				Fields: FieldResolveMap{
+					"user": &FieldResolve{
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							return map[string]interface{}{"name": "bob"}, nil
+						},
+					},
+				},
+			},
+			"User": &ObjectResolver{
+				Fields: FieldResolveMap{
+					"posts": &FieldResolve{
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							return []interface{}{map[string]interface{}{"title": "hello"}}, nil
+						},
+					},
+				},
+			},
+			"Post": &ObjectResolver{
+				Fields: FieldResolveMap{
+					"comments": &FieldResolve{
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							ancestors = Ancestors(p)
+							return []interface{}{}, nil
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: `{ u: user(id: "1") { posts(first: 5) { comments { text } } } }`,
+	})
+	if r.HasErrors() {
+		t.Fatal(r.Errors)
+	}
+
+	if len(ancestors) != 2 {
+		t.Fatalf("expected 2 ancestors, got %d", len(ancestors))
+	}
+
+	posts, user := ancestors[0], ancestors[1]
+	if posts.FieldName != "posts" || posts.ParentType != "User" || posts.Args["first"] != 5 {
+		t.Errorf("unexpected posts ancestor %+v", posts)
+	}
+	if source, ok := posts.Source.(map[string]interface{}); !ok || source["name"] != "bob" {
+		t.Errorf("expected the posts source to be the user, got %v", posts.Source)
+	}
+	if user.FieldName != "user" || user.Alias != "u" || user.Args["id"] != "1" {
+		t.Errorf("unexpected user ancestor %+v", user)
+	}
+}
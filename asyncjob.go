@@ -0,0 +1,269 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/kinds"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+const (
+	directiveAsyncJob = "asyncJob"
+	jobTypeName       = "Job"
+)
+
+// AsyncJobDirective runs a mutation in the background and returns a Job
+var AsyncJobDirective = graphql.NewDirective(graphql.DirectiveConfig{
+	Name:        directiveAsyncJob,
+	Description: "Runs the mutation resolver in the background and immediately returns a Job. The result is available from the job query and jobUpdated subscription",
+	Locations:   []string{graphql.DirectiveLocationFieldDefinition},
+	Args:        graphql.FieldConfigArgument{},
+})
+
+// job types added to documents using @asyncJob
+const asyncJobTypeDefs = `
+enum JobStatus {
+	PENDING
+	RUNNING
+	SUCCEEDED
+	FAILED
+	CANCELLED
+}
+
+type Job {
+	id: ID!
+	status: JobStatus!
+	progress: Float!
+	result: JSON
+	error: String
+	createdAt: DateTime!
+	updatedAt: DateTime!
+}
+`
+
+// expandAsyncJobs changes the return type of @asyncJob mutation fields to Job!
+// and adds the job types with job, cancelJob and jobUpdated root fields
+func expandAsyncJobs(document *ast.Document) (*ast.Document, error) {
+	if !usesAsyncJobs(document) {
+		return document, nil
+	}
+
+	mutationName := getRootTypeName(document, ast.OperationTypeMutation, DefaultRootMutationName)
+	for _, def := range document.Definitions {
+		for _, object := range objectDefinitions(def) {
+			for _, field := range object.Fields {
+				if getASTDirective(field.Directives, directiveAsyncJob) == nil {
+					continue
+				}
+				if object.Name.Value != mutationName {
+					return nil, fmt.Errorf("@%s can only be applied to fields of %q, found on %s.%s", directiveAsyncJob, mutationName, object.Name.Value, field.Name.Value)
+				}
+				field.Type = ast.NewNonNull(&ast.NonNull{
+					Type: ast.NewNamed(&ast.Named{Name: ast.NewName(&ast.Name{Value: jobTypeName})}),
+				})
+			}
+		}
+	}
+
+	queryName := getRootTypeName(document, ast.OperationTypeQuery, DefaultRootQueryName)
+	subscriptionName := getRootTypeName(document, ast.OperationTypeSubscription, DefaultRootSubscriptionName)
+
+	typeDefs := asyncJobTypeDefs
+	if FindDefinition(document, "JSON") == nil {
+		typeDefs += "\nscalar JSON\n"
+	}
+	typeDefs += fmt.Sprintf(`
+extend type %s {
+	job(id: ID!): Job
+}
+
+extend type %s {
+	cancelJob(id: ID!): Job
+}
+`, queryName, mutationName)
+
+	subscriptionFields := "{\n\tjobUpdated(id: ID!): Job\n}"
+	if FindDefinition(document, subscriptionName) != nil {
+		typeDefs += fmt.Sprintf("\nextend type %s %s\n", subscriptionName, subscriptionFields)
+	} else {
+		typeDefs += fmt.Sprintf("\ntype %s %s\n", subscriptionName, subscriptionFields)
+		addSchemaOperation(document, ast.OperationTypeSubscription, subscriptionName)
+	}
+
+	generated, err := parser.Parse(parser.ParseParams{
+		Source: &source.Source{
+			Body: []byte(typeDefs),
+			Name: "AsyncJob",
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, def := range generated.Definitions {
+		if err := AddDefinition(document, def); err != nil {
+			return nil, fmt.Errorf("@%s: %v", directiveAsyncJob, err)
+		}
+	}
+
+	return document, nil
+}
+
+// determines if any field uses @asyncJob
+func usesAsyncJobs(document *ast.Document) bool {
+	for _, def := range document.Definitions {
+		for _, object := range objectDefinitions(def) {
+			for _, field := range object.Fields {
+				if getASTDirective(field.Directives, directiveAsyncJob) != nil {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// gets the object definition of an object or object extension
+func objectDefinitions(def ast.Node) []*ast.ObjectDefinition {
+	switch d := def.(type) {
+	case *ast.ObjectDefinition:
+		return []*ast.ObjectDefinition{d}
+	case *ast.TypeExtensionDefinition:
+		return []*ast.ObjectDefinition{d.Definition}
+	}
+	return nil
+}
+
+// adds an operation to the schema definition if there is one
+func addSchemaOperation(document *ast.Document, operation, typeName string) {
+	for _, def := range document.Definitions {
+		if def.GetKind() != kinds.SchemaDefinition {
+			continue
+		}
+		schema := def.(*ast.SchemaDefinition)
+		schema.OperationTypes = append(schema.OperationTypes, ast.NewOperationTypeDefinition(&ast.OperationTypeDefinition{
+			Operation: operation,
+			Type:      ast.NewNamed(&ast.Named{Name: ast.NewName(&ast.Name{Value: typeName})}),
+		}))
+	}
+}
+
+// runs the mutation resolver as a job
+func (c *registry) asyncJobVisitor() *SchemaDirectiveVisitor {
+	return &SchemaDirectiveVisitor{
+		VisitFieldDefinition: func(p VisitFieldDefinitionParams) error {
+			if c.jobs == nil {
+				return fmt.Errorf("@%s on %s.%s requires a JobManager", directiveAsyncJob, p.ParentName, p.Config.Name)
+			}
+
+			resolve := p.Config.Resolve
+			if resolve == nil {
+				resolve = graphql.DefaultResolveFn
+			}
+
+			jobs := c.jobs
+			field := p.ParentName + "." + p.Config.Name
+			p.Config.Resolve = func(rp graphql.ResolveParams) (interface{}, error) {
+				return jobs.Start(rp.Context, field, func(ctx context.Context) (interface{}, error) {
+					rp.Context = ctx
+					return resolve(rp)
+				})
+			}
+
+			return nil
+		},
+	}
+}
+
+// adds the resolvers of the generated job fields
+func (c *registry) importJobResolvers() error {
+	if c.jobs == nil || !usesAsyncJobs(c.document) {
+		return nil
+	}
+
+	jobs := c.jobs
+	values := map[string]interface{}{}
+	for _, status := range []JobStatus{JobPending, JobRunning, JobSucceeded, JobFailed, JobCancelled} {
+		values[string(status)] = status
+	}
+	if err := c.importResolver("JobStatus", &EnumResolver{Values: values}); err != nil {
+		return err
+	}
+
+	c.addFieldResolvers(getRootTypeName(c.document, ast.OperationTypeQuery, DefaultRootQueryName), FieldResolveMap{
+		"job": &FieldResolve{
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				job, err := jobs.Get(contextOrBackground(p.Context), fmt.Sprintf("%v", p.Args["id"]))
+				if err == ErrJobNotFound {
+					return nil, nil
+				}
+				return job, err
+			},
+		},
+	})
+
+	c.addFieldResolvers(getRootTypeName(c.document, ast.OperationTypeMutation, DefaultRootMutationName), FieldResolveMap{
+		"cancelJob": &FieldResolve{
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				job, err := jobs.Cancel(contextOrBackground(p.Context), fmt.Sprintf("%v", p.Args["id"]))
+				if err == ErrJobNotFound {
+					return nil, nil
+				}
+				return job, err
+			},
+		},
+	})
+
+	c.addFieldResolvers(getRootTypeName(c.document, ast.OperationTypeSubscription, DefaultRootSubscriptionName), FieldResolveMap{
+		"jobUpdated": &FieldResolve{
+			Subscribe: func(p graphql.ResolveParams) (interface{}, error) {
+				ctx := contextOrBackground(p.Context)
+				updates, err := jobs.Subscribe(ctx, fmt.Sprintf("%v", p.Args["id"]))
+				if err != nil {
+					return nil, err
+				}
+
+				ch := make(chan interface{})
+				go func() {
+					defer close(ch)
+					for job := range updates {
+						select {
+						case ch <- job:
+						case <-ctx.Done():
+							return
+						}
+					}
+				}()
+				return ch, nil
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source, nil
+			},
+		},
+	})
+
+	return nil
+}
+
+// adds field resolvers to a copy of an object resolver, user supplied
+// resolvers for the same fields are kept
+func (c *registry) addFieldResolvers(typeName string, fields FieldResolveMap) {
+	merged := &ObjectResolver{Fields: FieldResolveMap{}}
+	if resolver, ok := c.resolverMap[typeName].(*ObjectResolver); ok {
+		merged.IsTypeOf = resolver.IsTypeOf
+		for name, field := range resolver.Fields {
+			merged.Fields[name] = field
+		}
+	}
+
+	for name, field := range fields {
+		if _, ok := merged.Fields[name]; !ok {
+			merged.Fields[name] = field
+		}
+	}
+	c.resolverMap[typeName] = merged
+}
@@ -0,0 +1,322 @@
+package tools
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/graphql-go/graphql"
+)
+
+func TestAsyncJob(t *testing.T) {
+	release := make(chan struct{})
+	jobs := NewJobManager()
+
+	schema, err := MakeExecutableSchema(ExecutableSchema{
+		TypeDefs: `
+type Query {
+	version: String
+}
+
+type Mutation {
+	generateReport(name: String!): String @asyncJob
+}`,
+		Jobs: jobs,
+		Resolvers: ResolverMap{
+			"Mutation": &ObjectResolver{
+				Fields: FieldResolveMap{
+					"generateReport": &FieldResolve{
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							ReportJobProgress(p.Context, 0.5)
+							<-release
+							return "report " + p.Args["name"].(string), nil
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: `mutation { generateReport(name: "sales") { id status } }`,
+	})
+	if r.HasErrors() {
+		t.Fatal(r.Errors)
+	}
+
+	job := r.Data.(map[string]interface{})["generateReport"].(map[string]interface{})
+	id := job["id"].(string)
+	if job["status"] != "PENDING" {
+		t.Errorf("expected PENDING, got %v", job["status"])
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	results := graphql.Subscribe(graphql.Params{
+		Schema:         schema,
+		Context:        ctx,
+		RequestString:  `subscription ($id: ID!) { jobUpdated(id: $id) { status progress result } }`,
+		VariableValues: map[string]interface{}{"id": id},
+	})
+
+	close(release)
+
+	var last map[string]interface{}
+	timeout := time.After(time.Second)
+	for done := false; !done; {
+		select {
+		case r, ok := <-results:
+			if !ok {
+				done = true
+				break
+			}
+			if r.HasErrors() {
+				t.Fatal(r.Errors)
+			}
+			last = r.Data.(map[string]interface{})["jobUpdated"].(map[string]interface{})
+		case <-timeout:
+			t.Fatal("timed out waiting for job updates")
+		}
+	}
+
+	if last["status"] != "SUCCEEDED" || last["result"] != "report sales" || last["progress"] != 1.0 {
+		t.Errorf("unexpected final job state %v", last)
+	}
+
+	r = graphql.Do(graphql.Params{
+		Schema:         schema,
+		RequestString:  `query ($id: ID!) { job(id: $id) { status result error } }`,
+		VariableValues: map[string]interface{}{"id": id},
+	})
+	if r.HasErrors() {
+		t.Fatal(r.Errors)
+	}
+	job = r.Data.(map[string]interface{})["job"].(map[string]interface{})
+	if job["status"] != "SUCCEEDED" {
+		t.Errorf("expected SUCCEEDED, got %v", job["status"])
+	}
+	if job["error"] != nil {
+		t.Errorf("expected a null error, got %#v", job["error"])
+	}
+}
+
+func TestAsyncJobCancel(t *testing.T) {
+	schema, err := MakeExecutableSchema(ExecutableSchema{
+		TypeDefs: `
+type Query {
+	version: String
+}
+
+type Mutation {
+	wait: Boolean @asyncJob
+}`,
+		Jobs: NewJobManager(),
+		Resolvers: ResolverMap{
+			"Mutation": &ObjectResolver{
+				Fields: FieldResolveMap{
+					"wait": &FieldResolve{
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							<-p.Context.Done()
+							return nil, p.Context.Err()
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := graphql.Do(graphql.Params{Schema: schema, RequestString: `mutation { wait { id } }`})
+	if r.HasErrors() {
+		t.Fatal(r.Errors)
+	}
+	id := r.Data.(map[string]interface{})["wait"].(map[string]interface{})["id"]
+
+	r = graphql.Do(graphql.Params{
+		Schema:         schema,
+		RequestString:  `mutation ($id: ID!) { cancelJob(id: $id) { status } }`,
+		VariableValues: map[string]interface{}{"id": id},
+	})
+	if r.HasErrors() {
+		t.Fatal(r.Errors)
+	}
+	if status := r.Data.(map[string]interface{})["cancelJob"].(map[string]interface{})["status"]; status != "CANCELLED" {
+		t.Errorf("expected CANCELLED, got %v", status)
+	}
+}
+
+func TestAsyncJobErrors(t *testing.T) {
+	if _, err := MakeExecutableSchema(ExecutableSchema{
+		TypeDefs: `
+type Query {
+	report: String @asyncJob
+}`,
+		Jobs: NewJobManager(),
+	}); err == nil {
+		t.Error("expected an error using @asyncJob outside of the mutation type")
+	}
+
+	if _, err := MakeExecutableSchema(ExecutableSchema{
+		TypeDefs: `
+type Query {
+	version: String
+}
+
+type Mutation {
+	report: String @asyncJob
+}`,
+	}); err == nil {
+		t.Error("expected an error using @asyncJob without a JobManager")
+	}
+}
+
+func TestAsyncJobUpdatedUnsubscribe(t *testing.T) {
+	jobs := NewJobManager()
+	schema, err := MakeExecutableSchema(ExecutableSchema{
+		TypeDefs: `
+type Query {
+	version: String
+}
+
+type Mutation {
+	wait: Boolean @asyncJob
+}`,
+		Jobs: jobs,
+		Resolvers: ResolverMap{
+			"Mutation": &ObjectResolver{
+				Fields: FieldResolveMap{
+					"wait": &FieldResolve{
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							<-p.Context.Done()
+							return nil, p.Context.Err()
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	job, err := jobs.Start(context.Background(), "wait", func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := runtime.NumGoroutine()
+
+	// subscribe without reading the updates, then leave
+	ctx, cancel := context.WithCancel(context.Background())
+	field := schema.SubscriptionType().Fields()["jobUpdated"]
+	if _, err := field.Subscribe(graphql.ResolveParams{
+		Context: ctx,
+		Args:    map[string]interface{}{"id": job.ID},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+
+	for deadline := time.Now().Add(time.Second); runtime.NumGoroutine() > before; {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the job update goroutines to exit, %d are running", runtime.NumGoroutine()-before)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	jobs.Cancel(context.Background(), job.ID)
+}
+
+// a PubSub whose subscriptions end when closed without delivering updates
+type closingPubSub struct {
+	closed chan struct{}
+}
+
+func (c *closingPubSub) Publish(ctx context.Context, topic string, payload interface{}) error {
+	return nil
+}
+
+func (c *closingPubSub) Subscribe(ctx context.Context, topic string) (<-chan interface{}, error) {
+	ch := make(chan interface{})
+	go func() {
+		<-c.closed
+		close(ch)
+	}()
+	return ch, nil
+}
+
+func TestJobManagerSubscribeClosedUpdates(t *testing.T) {
+	pubsub := &closingPubSub{closed: make(chan struct{})}
+	jobs := NewJobManager()
+	jobs.PubSub = pubsub
+
+	release := make(chan struct{})
+	job, err := jobs.Start(context.Background(), "wait", func(ctx context.Context) (interface{}, error) {
+		<-release
+		return "done", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	updates, err := jobs.Subscribe(context.Background(), job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if initial := <-updates; initial.Status.Done() {
+		t.Fatalf("expected the job to be running, got %s", initial.Status)
+	}
+
+	close(release)
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(5 * time.Millisecond) {
+		if job, _ := jobs.Get(context.Background(), job.ID); job.Status.Done() {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the job to finish")
+		}
+	}
+
+	// the updates end without the final state, it is read from the store
+	close(pubsub.closed)
+	select {
+	case final, ok := <-updates:
+		if !ok {
+			t.Fatal("expected the final job state before the updates end")
+		}
+		if final.Status != JobSucceeded || final.Result != "done" {
+			t.Errorf("unexpected final job state %+v", final)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the final job state")
+	}
+}
+
+func TestMemoryJobStoreRetention(t *testing.T) {
+	store := NewMemoryJobStore()
+	store.Retention = 10 * time.Millisecond
+	ctx := context.Background()
+
+	old := time.Now().UTC().Add(-time.Second)
+	store.Save(ctx, &Job{ID: "finished", Status: JobSucceeded, UpdatedAt: old})
+	store.Save(ctx, &Job{ID: "running", Status: JobRunning, UpdatedAt: old})
+
+	if _, err := store.Get(ctx, "finished"); err != ErrJobNotFound {
+		t.Errorf("expected the finished job to expire, got %v", err)
+	}
+	if _, err := store.Get(ctx, "running"); err != nil {
+		t.Errorf("expected the running job to be kept, got %v", err)
+	}
+	if len(store.jobs) != 1 {
+		t.Errorf("expected the expired job to be evicted, %d jobs are stored", len(store.jobs))
+	}
+}
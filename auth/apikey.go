@@ -0,0 +1,351 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rohit20001221/graphql-go-tools/server"
+	"github.com/rohit20001221/graphql-go-tools/server/graphqlws"
+)
+
+// api key errors
+var (
+	// ErrAPIKeyNotFound is returned by stores for unknown key hashes
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	// ErrInvalidAPIKey is returned for unknown keys
+	ErrInvalidAPIKey = errors.New("invalid api key")
+	// ErrAPIKeyExpired is returned for keys past their expiry
+	ErrAPIKeyExpired = errors.New("api key expired")
+	// ErrRateLimited is returned when a key exceeds its rate limit
+	ErrRateLimited = errors.New("rate limit exceeded")
+)
+
+// default api key locations
+const (
+	DefaultAPIKeyHeader     = "X-API-Key"
+	DefaultAPIKeyPayloadKey = "apiKey"
+	apiKeyScheme            = "ApiKey"
+	apiKeyMethod            = "apiKey"
+)
+
+// RateLimit allows a number of requests per interval with bursts up to the
+// number of requests
+type RateLimit struct {
+	Requests int
+	Interval time.Duration
+}
+
+// APIKey a stored api key, only the hash of the key is stored
+type APIKey struct {
+	ID        string                 `json:"id"`
+	Hash      string                 `json:"hash"`    // HashAPIKey of the key
+	Subject   string                 `json:"subject"` // the principal id, defaults to the key id
+	Scopes    []string               `json:"scopes"`
+	ExpiresAt time.Time              `json:"expiresAt"` // never expires when zero
+	RateLimit *RateLimit             `json:"rateLimit,omitempty"`
+	LastUsed  time.Time              `json:"lastUsed"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// HashAPIKey hashes a key for storage and lookup
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// APIKeyStore stores api keys by hash
+type APIKeyStore interface {
+	// GetAPIKey gets a key by hash or ErrAPIKeyNotFound
+	GetAPIKey(ctx context.Context, hash string) (*APIKey, error)
+	// TouchAPIKey records the last time a key was used
+	TouchAPIKey(ctx context.Context, id string, usedAt time.Time) error
+}
+
+// MemoryAPIKeyStore stores api keys in memory
+type MemoryAPIKeyStore struct {
+	mx   sync.RWMutex
+	keys map[string]*APIKey
+}
+
+// NewMemoryAPIKeyStore creates a memory store with the keys
+func NewMemoryAPIKeyStore(keys ...*APIKey) *MemoryAPIKeyStore {
+	store := &MemoryAPIKeyStore{keys: map[string]*APIKey{}}
+	for _, key := range keys {
+		store.Add(key)
+	}
+	return store
+}
+
+// Add adds or replaces a key
+func (s *MemoryAPIKeyStore) Add(key *APIKey) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	s.keys[key.Hash] = key
+}
+
+// Remove removes a key by id
+func (s *MemoryAPIKeyStore) Remove(id string) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	for hash, key := range s.keys {
+		if key.ID == id {
+			delete(s.keys, hash)
+		}
+	}
+}
+
+// GetAPIKey gets a copy of a key by hash
+func (s *MemoryAPIKeyStore) GetAPIKey(ctx context.Context, hash string) (*APIKey, error) {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+	key, ok := s.keys[hash]
+	if !ok {
+		return nil, ErrAPIKeyNotFound
+	}
+	k := *key
+	return &k, nil
+}
+
+// TouchAPIKey records the last time a key was used
+func (s *MemoryAPIKeyStore) TouchAPIKey(ctx context.Context, id string, usedAt time.Time) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	for _, key := range s.keys {
+		if key.ID == id {
+			key.LastUsed = usedAt
+			return nil
+		}
+	}
+	return ErrAPIKeyNotFound
+}
+
+// a token bucket of a key
+type tokenBucket struct {
+	limit   RateLimit
+	tokens  float64
+	updated time.Time
+}
+
+// interval between removing idle token buckets
+const bucketPruneInterval = time.Minute
+
+// APIKeyProvider authenticates api keys from a store. Keys are read from the
+// X-API-Key header or an Authorization header with the ApiKey scheme over
+// http, and from the apiKey, authToken or Authorization connection_init
+// payload fields over websockets. Rate limits are charged per http request
+// and per websocket operation
+type APIKeyProvider struct {
+	Store      APIKeyStore
+	Header     string           // defaults to X-API-Key
+	PayloadKey string           // defaults to apiKey
+	Now        func() time.Time // defaults to time.Now
+	OnError    func(err error)  // called when recording the last used time fails
+
+	mx      sync.Mutex
+	buckets map[string]*tokenBucket
+	pruned  time.Time
+}
+
+// NewAPIKeyProvider creates a new api key provider
+func NewAPIKeyProvider(store APIKeyStore) *APIKeyProvider {
+	return &APIKeyProvider{Store: store}
+}
+
+func (p *APIKeyProvider) now() time.Time {
+	if p.Now != nil {
+		return p.Now()
+	}
+	return time.Now()
+}
+
+// Authenticate validates a key and returns its principal
+func (p *APIKeyProvider) Authenticate(ctx context.Context, key string) (*Principal, error) {
+	stored, err := p.Store.GetAPIKey(ctx, HashAPIKey(key))
+	if errors.Is(err, ErrAPIKeyNotFound) {
+		return nil, ErrInvalidAPIKey
+	} else if err != nil {
+		return nil, err
+	}
+
+	now := p.now()
+	if !stored.ExpiresAt.IsZero() && !now.Before(stored.ExpiresAt) {
+		return nil, ErrAPIKeyExpired
+	}
+	if !p.allow(stored, now) {
+		return nil, fmt.Errorf("%w for api key %s", ErrRateLimited, stored.ID)
+	}
+
+	if err := p.Store.TouchAPIKey(ctx, stored.ID, now); err != nil && p.OnError != nil {
+		p.OnError(err)
+	}
+
+	subject := stored.Subject
+	if subject == "" {
+		subject = stored.ID
+	}
+
+	claims := map[string]interface{}{"keyId": stored.ID}
+	for k, v := range stored.Metadata {
+		claims[k] = v
+	}
+
+	principal := &Principal{
+		ID:     subject,
+		Scopes: append([]string{}, stored.Scopes...),
+		Method: apiKeyMethod,
+		Claims: claims,
+	}
+	if stored.RateLimit != nil {
+		limit := *stored.RateLimit
+		principal.rateLimit = &limit
+	}
+	return principal, nil
+}
+
+// takes a token from the bucket of a rate limited key
+func (p *APIKeyProvider) allow(key *APIKey, now time.Time) bool {
+	return p.take(key.ID, key.RateLimit, now)
+}
+
+// takes a token from the bucket of a key, buckets are created full when
+// missing and keys without a limit are not rate limited
+func (p *APIKeyProvider) take(id string, limit *RateLimit, now time.Time) bool {
+	if limit == nil || limit.Requests <= 0 || limit.Interval <= 0 {
+		return true
+	}
+
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	if p.buckets == nil {
+		p.buckets = map[string]*tokenBucket{}
+	}
+	p.prune(now)
+
+	bucket, ok := p.buckets[id]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(limit.Requests), updated: now}
+		p.buckets[id] = bucket
+	}
+	bucket.limit = *limit
+	return bucket.take(now)
+}
+
+// removes the buckets that have refilled, they are recreated full when the
+// key is used again so removed keys do not keep their buckets
+func (p *APIKeyProvider) prune(now time.Time) {
+	if now.Sub(p.pruned) < bucketPruneInterval {
+		return
+	}
+	p.pruned = now
+	for id, bucket := range p.buckets {
+		if bucket.refill(now) >= float64(bucket.limit.Requests) {
+			delete(p.buckets, id)
+		}
+	}
+}
+
+// refills the bucket for the elapsed time and returns the tokens
+func (b *tokenBucket) refill(now time.Time) float64 {
+	capacity := float64(b.limit.Requests)
+	if elapsed := now.Sub(b.updated); elapsed > 0 {
+		b.tokens += capacity * float64(elapsed) / float64(b.limit.Interval)
+		if b.tokens > capacity {
+			b.tokens = capacity
+		}
+		b.updated = now
+	}
+	return b.tokens
+}
+
+// takes a token if one is available
+func (b *tokenBucket) take(now time.Time) bool {
+	if b.refill(now) < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// gets the key of a request
+func (p *APIKeyProvider) requestKey(r *http.Request) string {
+	header := p.Header
+	if header == "" {
+		header = DefaultAPIKeyHeader
+	}
+	if key := r.Header.Get(header); key != "" {
+		return key
+	}
+	value := r.Header.Get("Authorization")
+	if len(value) > len(apiKeyScheme) && strings.EqualFold(value[:len(apiKeyScheme)+1], apiKeyScheme+" ") {
+		return strings.TrimSpace(value[len(apiKeyScheme)+1:])
+	}
+	return ""
+}
+
+// ContextFunc authenticates the key of a request. Requests without a key are
+// anonymous, the error of an invalid key is reported by @hasScope. Context
+// funcs cannot reject requests, requests over their rate limit are served
+// anonymously, use Middleware to reject them
+func (p *APIKeyProvider) ContextFunc() server.ContextFunc {
+	return contextFunc(p.requestKey, p.Authenticate)
+}
+
+// Middleware authenticates the key of each http request like ContextFunc and
+// responds with 429 Too Many Requests to requests over their rate limit. Use
+// it around the server handler instead of ContextFunc, websocket upgrades are
+// passed through and authenticated by AuthenticateFunc
+func (p *APIKeyProvider) Middleware(next http.Handler) http.Handler {
+	contextFunc := p.ContextFunc()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if server.IsWSUpgrade(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := contextFunc(r)
+		if err := ErrorFromContext(ctx); errors.Is(err, ErrRateLimited) {
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// AuthenticateFunc authenticates the key of a connection_init payload,
+// connections with an invalid key are rejected and connections without a key
+// are anonymous
+func (p *APIKeyProvider) AuthenticateFunc() graphqlws.AuthenticateFunc {
+	payloadKey := p.PayloadKey
+	if payloadKey == "" {
+		payloadKey = DefaultAPIKeyPayloadKey
+	}
+	return authenticateFunc(func(data map[string]interface{}) string {
+		return payloadCredential(data, payloadKey, apiKeyScheme)
+	}, p.Authenticate)
+}
+
+// StartOperationFunc charges the rate limit of the key that authenticated a
+// websocket connection for each operation, use it as the
+// server.WSOptions.StartOperationFunc with AuthenticateFunc
+func (p *APIKeyProvider) StartOperationFunc() func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		principal, ok := FromContext(ctx)
+		if !ok || principal.Method != apiKeyMethod {
+			return nil
+		}
+
+		id, _ := principal.Claims["keyId"].(string)
+		if !p.take(id, principal.rateLimit, p.now()) {
+			return fmt.Errorf("%w for api key %s", ErrRateLimited, id)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,315 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/graphql-go/graphql"
+	tools "github.com/rohit20001221/graphql-go-tools"
+	"github.com/rohit20001221/graphql-go-tools/server"
+	"github.com/rohit20001221/graphql-go-tools/server/graphqlws"
+)
+
+func makeScopedSchema(t *testing.T) graphql.Schema {
+	schema, err := tools.MakeExecutableSchema(tools.ExecutableSchema{
+		TypeDefs: `
+type Query {
+	public: String
+	orders: String @hasScope(scopes: ["orders:read", "admin"])
+	whoami: String
+}
+
+type Subscription {
+	orderPlaced: String @hasScope(scopes: ["orders:read"])
+}`,
+		Resolvers: tools.ResolverMap{
+			"@hasScope": HasScopeDirective,
+			"Query": &tools.ObjectResolver{
+				Fields: tools.FieldResolveMap{
+					"public": &tools.FieldResolve{
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							return "public", nil
+						},
+					},
+					"orders": &tools.FieldResolve{
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							return "orders", nil
+						},
+					},
+					"whoami": &tools.FieldResolve{
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							if principal, ok := FromContext(p.Context); ok {
+								return principal.ID, nil
+							}
+							return nil, nil
+						},
+					},
+				},
+			},
+			"Subscription": &tools.ObjectResolver{
+				Fields: tools.FieldResolveMap{
+					"orderPlaced": &tools.FieldResolve{
+						Subscribe: func(p graphql.ResolveParams) (interface{}, error) {
+							principal, _ := FromContext(p.Context)
+							ch := make(chan interface{}, 1)
+							ch <- "order for " + principal.ID
+							close(ch)
+							return ch, nil
+						},
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							return p.Source, nil
+						},
+					},
+				},
+			},
+		},
+		SchemaDirectives: tools.SchemaDirectiveVisitorMap{
+			"hasScope": HasScopeVisitor,
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to make schema: %v", err)
+	}
+	return schema
+}
+
+type graphqlResponse struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func TestAPIKeyContextFunc(t *testing.T) {
+	store := NewMemoryAPIKeyStore(
+		&APIKey{ID: "reader", Hash: HashAPIKey("secret-reader"), Subject: "partner", Scopes: []string{"orders:read"}},
+		&APIKey{ID: "limited", Hash: HashAPIKey("secret-limited")},
+		&APIKey{ID: "expired", Hash: HashAPIKey("secret-expired"), Scopes: []string{"orders:read"}, ExpiresAt: time.Now().Add(-time.Hour)},
+	)
+	provider := NewAPIKeyProvider(store)
+
+	srv := httptest.NewServer(server.New(makeScopedSchema(t), &server.Options{
+		ContextFunc: provider.ContextFunc(),
+	}))
+	defer srv.Close()
+
+	query := func(header, value string) graphqlResponse {
+		req, _ := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader(`{"query":"{ public orders whoami }"}`))
+		req.Header.Set("Content-Type", "application/json")
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+
+		var result graphqlResponse
+		if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	result := query("X-API-Key", "secret-reader")
+	if len(result.Errors) != 0 || result.Data["orders"] != "orders" || result.Data["whoami"] != "partner" {
+		t.Errorf("expected the key to be authorized, got %+v", result)
+	}
+
+	result = query("Authorization", "ApiKey secret-reader")
+	if len(result.Errors) != 0 || result.Data["orders"] != "orders" {
+		t.Errorf("expected the ApiKey authorization scheme to be accepted, got %+v", result)
+	}
+
+	for _, tt := range []struct {
+		name, header, value, message string
+	}{
+		{"anonymous", "", "", "unauthenticated"},
+		{"invalid", "X-API-Key", "wrong", "invalid api key"},
+		{"expired", "X-API-Key", "secret-expired", "api key expired"},
+		{"missing scope", "X-API-Key", "secret-limited", "forbidden: requires scope orders:read or admin"},
+	} {
+		result := query(tt.header, tt.value)
+		if result.Data["public"] != "public" || result.Data["orders"] != nil {
+			t.Errorf("%s: expected only public data, got %+v", tt.name, result.Data)
+		}
+		if len(result.Errors) != 1 || result.Errors[0].Message != tt.message {
+			t.Errorf("%s: expected error %q, got %+v", tt.name, tt.message, result.Errors)
+		}
+	}
+
+	key, _ := store.GetAPIKey(context.Background(), HashAPIKey("secret-reader"))
+	if key.LastUsed.IsZero() {
+		t.Error("expected the last used time to be recorded")
+	}
+}
+
+func TestAPIKeyRateLimit(t *testing.T) {
+	now := time.Now()
+	provider := NewAPIKeyProvider(NewMemoryAPIKeyStore(&APIKey{
+		ID:        "limited",
+		Hash:      HashAPIKey("secret"),
+		RateLimit: &RateLimit{Requests: 2, Interval: time.Second},
+	}))
+	provider.Now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if _, err := provider.Authenticate(context.Background(), "secret"); err != nil {
+			t.Fatalf("expected request %d to be allowed, got %v", i, err)
+		}
+	}
+	if _, err := provider.Authenticate(context.Background(), "secret"); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected the third request to be rate limited, got %v", err)
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if _, err := provider.Authenticate(context.Background(), "secret"); err != nil {
+		t.Errorf("expected a token to be refilled, got %v", err)
+	}
+	if _, err := provider.Authenticate(context.Background(), "secret"); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("expected the bucket to be empty, got %v", err)
+	}
+
+	// websocket operations are charged to the key of the connection
+	now = now.Add(time.Second)
+	principal, err := provider.Authenticate(context.Background(), "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := provider.StartOperationFunc()
+	ctx := NewContext(context.Background(), principal)
+	if err := start(ctx); err != nil {
+		t.Errorf("expected the first operation to be allowed, got %v", err)
+	}
+	if err := start(ctx); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("expected the second operation to be rate limited, got %v", err)
+	}
+	if err := start(context.Background()); err != nil {
+		t.Errorf("expected anonymous operations not to be charged, got %v", err)
+	}
+
+	// idle buckets are removed once they have refilled
+	provider.Store.(*MemoryAPIKeyStore).Add(&APIKey{
+		ID:        "other",
+		Hash:      HashAPIKey("other"),
+		RateLimit: &RateLimit{Requests: 1, Interval: time.Second},
+	})
+	now = now.Add(2 * bucketPruneInterval)
+	if _, err := provider.Authenticate(context.Background(), "other"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := provider.buckets["limited"]; ok || len(provider.buckets) != 1 {
+		t.Errorf("expected the idle bucket to be removed, got %d buckets", len(provider.buckets))
+	}
+
+	// the bucket of an idle connection is recreated from the principal
+	for i := 0; i < 2; i++ {
+		if err := start(ctx); err != nil {
+			t.Errorf("expected operation %d after pruning to be allowed, got %v", i, err)
+		}
+	}
+	if err := start(ctx); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("expected the recreated bucket to be rate limited, got %v", err)
+	}
+}
+
+func TestAPIKeyMiddleware(t *testing.T) {
+	provider := NewAPIKeyProvider(NewMemoryAPIKeyStore(&APIKey{
+		ID:        "limited",
+		Hash:      HashAPIKey("secret"),
+		Scopes:    []string{"orders:read"},
+		RateLimit: &RateLimit{Requests: 1, Interval: time.Hour},
+	}))
+
+	srv := httptest.NewServer(provider.Middleware(server.New(makeScopedSchema(t), &server.Options{})))
+	defer srv.Close()
+
+	query := func() *http.Response {
+		req, _ := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader(`{"query":"{ public orders }"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-Key", "secret")
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	res := query()
+	var result graphqlResponse
+	json.NewDecoder(res.Body).Decode(&result)
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK || result.Data["orders"] != "orders" {
+		t.Errorf("expected the key to be authorized, got %d %+v", res.StatusCode, result)
+	}
+
+	res = query()
+	res.Body.Close()
+	if res.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("expected the rate limited request to be rejected with 429, got %d", res.StatusCode)
+	}
+}
+
+func TestAPIKeyAuthenticateFunc(t *testing.T) {
+	provider := NewAPIKeyProvider(NewMemoryAPIKeyStore(&APIKey{
+		ID:     "reader",
+		Hash:   HashAPIKey("secret"),
+		Scopes: []string{"orders:read"},
+	}))
+
+	srv := httptest.NewServer(server.New(makeScopedSchema(t), &server.Options{
+		WS: &server.WSOptions{AuthenticateFunc: provider.AuthenticateFunc()},
+	}))
+	defer srv.Close()
+
+	subscribe := func(payload map[string]interface{}) []graphqlws.OperationMessage {
+		ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), http.Header{
+			"Sec-WebSocket-Protocol": []string{"graphql-ws"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ws.Close()
+		ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+		ws.WriteJSON(graphqlws.OperationMessage{Type: "connection_init", Payload: payload})
+		ws.WriteJSON(graphqlws.OperationMessage{ID: "1", Type: "start", Payload: map[string]interface{}{
+			"query": "subscription { orderPlaced }",
+		}})
+
+		messages := []graphqlws.OperationMessage{}
+		for {
+			var msg graphqlws.OperationMessage
+			if err := ws.ReadJSON(&msg); err != nil {
+				t.Fatal(err)
+			}
+			if msg.Type == "ka" {
+				continue
+			}
+			messages = append(messages, msg)
+			if msg.Type == "data" || msg.Type == "connection_error" {
+				return messages
+			}
+		}
+	}
+
+	messages := subscribe(map[string]interface{}{"apiKey": "secret"})
+	last := messages[len(messages)-1]
+	data, _ := json.Marshal(last.Payload)
+	if last.Type != "data" || !strings.Contains(string(data), `"orderPlaced":"order for reader"`) {
+		t.Errorf("expected the operation to be authorized, got %s %s", last.Type, data)
+	}
+
+	messages = subscribe(map[string]interface{}{"authToken": "wrong"})
+	if last := messages[len(messages)-1]; last.Type != "connection_error" {
+		t.Errorf("expected the connection to be rejected, got %+v", messages)
+	}
+}
@@ -0,0 +1,232 @@
+// Package auth provides authentication for http requests and websocket
+// connections served by the server package. Authenticated principals are
+// placed into the context where the @hasScope directive checks their scopes
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	tools "github.com/rohit20001221/graphql-go-tools"
+	"github.com/rohit20001221/graphql-go-tools/server"
+	"github.com/rohit20001221/graphql-go-tools/server/graphqlws"
+)
+
+// authentication errors
+var (
+	// ErrUnauthenticated is returned when a principal is required but none was authenticated
+	ErrUnauthenticated = errors.New("unauthenticated")
+	// ErrForbidden is wrapped by errors for principals missing a required scope
+	ErrForbidden = errors.New("forbidden")
+)
+
+// Principal an authenticated client
+type Principal struct {
+	ID     string                 `json:"id"`
+	Scopes []string               `json:"scopes"`
+	Method string                 `json:"method"` // the authentication method such as apiKey
+	Claims map[string]interface{} `json:"claims,omitempty"`
+
+	rateLimit *RateLimit // the rate limit of an api key, charged per websocket operation
+}
+
+// HasScope determines if the principal was granted the scope
+func (p *Principal) HasScope(scope string) bool {
+	if p == nil {
+		return false
+	}
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+type errorKey struct{}
+
+// NewContext returns a context with the principal
+func NewContext(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// FromContext gets the principal of the context
+func FromContext(ctx context.Context) (*Principal, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
+
+// returns a context with the error of a failed authentication, context
+// funcs cannot reject requests so the error is reported by @hasScope
+func contextWithError(ctx context.Context, err error) context.Context {
+	return context.WithValue(ctx, errorKey{}, err)
+}
+
+// ErrorFromContext gets the error of a failed authentication
+func ErrorFromContext(ctx context.Context) error {
+	if ctx == nil {
+		return nil
+	}
+	err, _ := ctx.Value(errorKey{}).(error)
+	return err
+}
+
+// HasScopeDirective requires the principal to have one of the scopes
+var HasScopeDirective = graphql.NewDirective(graphql.DirectiveConfig{
+	Name:        "hasScope",
+	Description: "Requires an authenticated principal with one of the scopes",
+	Locations:   []string{graphql.DirectiveLocationFieldDefinition},
+	Args: graphql.FieldConfigArgument{
+		"scopes": &graphql.ArgumentConfig{
+			Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String))),
+		},
+	},
+})
+
+// HasScopeVisitor resolves fields with @hasScope only for principals with one
+// of the scopes, register it with HasScopeDirective
+//
+//	Resolvers:        tools.ResolverMap{"@hasScope": auth.HasScopeDirective},
+//	SchemaDirectives: tools.SchemaDirectiveVisitorMap{"hasScope": auth.HasScopeVisitor},
+var HasScopeVisitor = &tools.SchemaDirectiveVisitor{
+	VisitFieldDefinition: func(p tools.VisitFieldDefinitionParams) error {
+		scopes := []string{}
+		if values, ok := p.Args["scopes"].([]interface{}); ok {
+			for _, value := range values {
+				if scope, ok := value.(string); ok {
+					scopes = append(scopes, scope)
+				}
+			}
+		}
+		if len(scopes) == 0 {
+			return fmt.Errorf("@hasScope on %s.%s requires at least one scope", p.ParentName, p.Config.Name)
+		}
+
+		resolve := p.Config.Resolve
+		if resolve == nil {
+			resolve = graphql.DefaultResolveFn
+		}
+
+		p.Config.Resolve = func(rp graphql.ResolveParams) (interface{}, error) {
+			if err := checkScopes(rp.Context, scopes); err != nil {
+				return nil, err
+			}
+			return resolve(rp)
+		}
+
+		// subscriptions are checked before subscribing
+		if subscribe := p.Config.Subscribe; subscribe != nil {
+			p.Config.Subscribe = func(rp graphql.ResolveParams) (interface{}, error) {
+				if err := checkScopes(rp.Context, scopes); err != nil {
+					return nil, err
+				}
+				return subscribe(rp)
+			}
+		}
+		return nil
+	},
+}
+
+// checks the principal of the context has one of the scopes
+func checkScopes(ctx context.Context, scopes []string) error {
+	principal, ok := FromContext(ctx)
+	if !ok {
+		if err := ErrorFromContext(ctx); err != nil {
+			return err
+		}
+		return ErrUnauthenticated
+	}
+
+	for _, scope := range scopes {
+		if principal.HasScope(scope) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: requires scope %s", ErrForbidden, strings.Join(scopes, " or "))
+}
+
+// authenticates the credential of a request, requests without a credential
+// are anonymous and failures are recorded in the context
+func contextFunc(credential func(r *http.Request) string, authenticate func(ctx context.Context, credential string) (*Principal, error)) server.ContextFunc {
+	return func(r *http.Request) context.Context {
+		ctx := r.Context()
+		value := credential(r)
+		if value == "" {
+			return ctx
+		}
+
+		principal, err := authenticate(ctx, value)
+		if err != nil {
+			return contextWithError(ctx, err)
+		}
+		return NewContext(ctx, principal)
+	}
+}
+
+// authenticates the credential of a connection_init payload, connections
+// without a credential are anonymous and failures reject the connection
+func authenticateFunc(credential func(data map[string]interface{}) string, authenticate func(ctx context.Context, credential string) (*Principal, error)) graphqlws.AuthenticateFunc {
+	return func(data map[string]interface{}, conn graphqlws.Connection) (context.Context, error) {
+		ctx := conn.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		value := credential(data)
+		if value == "" {
+			return ctx, nil
+		}
+
+		principal, err := authenticate(ctx, value)
+		if err != nil {
+			return nil, err
+		}
+		return NewContext(ctx, principal), nil
+	}
+}
+
+// gets the credential of an authorization header with the scheme
+func authorizationCredential(r *http.Request, scheme string) string {
+	return credentialFromAuthorization(r.Header.Get("Authorization"), scheme)
+}
+
+// gets the credential of an authorization value with the scheme, values
+// without a scheme are returned as is
+func credentialFromAuthorization(value, scheme string) string {
+	value = strings.TrimSpace(value)
+	if i := strings.IndexByte(value, ' '); i != -1 {
+		if !strings.EqualFold(value[:i], scheme) {
+			return ""
+		}
+		return strings.TrimSpace(value[i+1:])
+	}
+	return value
+}
+
+// gets a credential from a connection_init payload by key, authToken or the
+// Authorization value with the scheme
+func payloadCredential(data map[string]interface{}, key, scheme string) string {
+	if key != "" {
+		if value, ok := data[key].(string); ok && value != "" {
+			return value
+		}
+	}
+	if value, ok := data["authToken"].(string); ok && value != "" {
+		return value
+	}
+	for _, name := range []string{"Authorization", "authorization"} {
+		if value, ok := data[name].(string); ok && value != "" {
+			return credentialFromAuthorization(value, scheme)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,438 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rohit20001221/graphql-go-tools/server"
+	"github.com/rohit20001221/graphql-go-tools/server/graphqlws"
+)
+
+// jwt errors
+var (
+	// ErrInvalidToken is wrapped by errors for malformed tokens, unknown keys and invalid signatures
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrTokenExpired is returned for tokens past their exp claim
+	ErrTokenExpired = errors.New("token expired")
+	// ErrTokenNotValidYet is returned for tokens before their nbf claim
+	ErrTokenNotValidYet = errors.New("token not valid yet")
+)
+
+// supported signing algorithms
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	ES256 = "ES256"
+)
+
+const (
+	bearerScheme = "Bearer"
+	jwtMethod    = "jwt"
+)
+
+// a verification key and the algorithm it verifies
+type verificationKey struct {
+	alg string
+	key interface{}
+}
+
+// JWK a JSON web key, only the fields of HMAC, RSA and P-256 EC keys are supported
+type JWK struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg,omitempty"`
+	Use string `json:"use,omitempty"`
+	K   string `json:"k,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// KeySet verification keys by key id. The algorithm of each key is fixed by
+// its type so tokens cannot select a weaker algorithm
+type KeySet struct {
+	mx   sync.RWMutex
+	keys map[string]verificationKey
+}
+
+// NewKeySet creates an empty key set
+func NewKeySet() *KeySet {
+	return &KeySet{keys: map[string]verificationKey{}}
+}
+
+func (s *KeySet) add(kid string, key verificationKey) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	s.keys[kid] = key
+}
+
+// AddHMAC adds an HS256 secret
+func (s *KeySet) AddHMAC(kid string, secret []byte) {
+	s.add(kid, verificationKey{alg: HS256, key: secret})
+}
+
+// AddRSA adds an RS256 public key
+func (s *KeySet) AddRSA(kid string, key *rsa.PublicKey) {
+	s.add(kid, verificationKey{alg: RS256, key: key})
+}
+
+// AddECDSA adds an ES256 public key
+func (s *KeySet) AddECDSA(kid string, key *ecdsa.PublicKey) {
+	s.add(kid, verificationKey{alg: ES256, key: key})
+}
+
+// AddJWK adds a JSON web key
+func (s *KeySet) AddJWK(jwk JWK) error {
+	if jwk.Use != "" && jwk.Use != "sig" {
+		return fmt.Errorf("key %q is not a signing key", jwk.Kid)
+	}
+
+	// the algorithm is fixed by the key type, keys published for another
+	// algorithm would otherwise be verified with the wrong one
+	algorithms := map[string]string{"oct": HS256, "RSA": RS256, "EC": ES256}
+	if alg, ok := algorithms[jwk.Kty]; ok && jwk.Alg != "" && jwk.Alg != alg {
+		return fmt.Errorf("unsupported algorithm %q of key %q", jwk.Alg, jwk.Kid)
+	}
+
+	switch jwk.Kty {
+	case "oct":
+		secret, err := decodeSegment(jwk.K)
+		if err != nil {
+			return fmt.Errorf("invalid key %q: %v", jwk.Kid, err)
+		}
+		s.AddHMAC(jwk.Kid, secret)
+
+	case "RSA":
+		n, err := decodeSegment(jwk.N)
+		if err != nil {
+			return fmt.Errorf("invalid key %q: %v", jwk.Kid, err)
+		}
+		e, err := decodeSegment(jwk.E)
+		if err != nil {
+			return fmt.Errorf("invalid key %q: %v", jwk.Kid, err)
+		}
+		s.AddRSA(jwk.Kid, &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		})
+
+	case "EC":
+		if jwk.Crv != "P-256" {
+			return fmt.Errorf("unsupported curve %q of key %q", jwk.Crv, jwk.Kid)
+		}
+		x, err := decodeSegment(jwk.X)
+		if err != nil {
+			return fmt.Errorf("invalid key %q: %v", jwk.Kid, err)
+		}
+		y, err := decodeSegment(jwk.Y)
+		if err != nil {
+			return fmt.Errorf("invalid key %q: %v", jwk.Kid, err)
+		}
+		key := &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}
+		if !key.Curve.IsOnCurve(key.X, key.Y) {
+			return fmt.Errorf("invalid key %q: point is not on the curve", jwk.Kid)
+		}
+		s.AddECDSA(jwk.Kid, key)
+
+	default:
+		return fmt.Errorf("unsupported key type %q of key %q", jwk.Kty, jwk.Kid)
+	}
+	return nil
+}
+
+// ParseJWKS parses a JSON web key set
+func ParseJWKS(data []byte) (*KeySet, error) {
+	var jwks struct {
+		Keys []JWK `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &jwks); err != nil {
+		return nil, fmt.Errorf("invalid key set: %v", err)
+	}
+
+	set := NewKeySet()
+	for _, jwk := range jwks.Keys {
+		if err := set.AddJWK(jwk); err != nil {
+			return nil, err
+		}
+	}
+	return set, nil
+}
+
+// LoadJWKSFile reads a JSON web key set from a file
+func LoadJWKSFile(path string) (*KeySet, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseJWKS(data)
+}
+
+// gets the key of a token, tokens without a key id use the only key of the set
+func (s *KeySet) lookup(kid string) (verificationKey, bool) {
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+// ClaimsMapper maps verified claims to a principal
+type ClaimsMapper func(claims map[string]interface{}) (*Principal, error)
+
+// JWTVerifier verifies bearer tokens. Tokens are read from the Authorization
+// header over http and from the authToken or Authorization connection_init
+// payload fields over websockets
+type JWTVerifier struct {
+	Keys     *KeySet
+	Issuer   string           // required iss claim, not checked when empty
+	Audience string           // required aud claim, not checked when empty
+	Leeway   time.Duration    // allowed clock skew of exp and nbf
+	Now      func() time.Time // defaults to time.Now
+	Mapper   ClaimsMapper     // defaults to DefaultClaimsMapper
+
+	// AllowMissingExpiry accepts tokens without an exp claim, they never
+	// expire. Tokens must have an exp claim by default
+	AllowMissingExpiry bool
+}
+
+// NewJWTVerifier creates a new verifier
+func NewJWTVerifier(keys *KeySet) *JWTVerifier {
+	return &JWTVerifier{Keys: keys}
+}
+
+// DefaultClaimsMapper maps the sub claim to the principal id and the space
+// separated scope claim or the scp and scopes list claims to its scopes
+func DefaultClaimsMapper(claims map[string]interface{}) (*Principal, error) {
+	sub, _ := claims["sub"].(string)
+	if sub == "" {
+		return nil, fmt.Errorf("%w: missing sub claim", ErrInvalidToken)
+	}
+
+	scopes := []string{}
+	for _, name := range []string{"scope", "scp", "scopes"} {
+		switch value := claims[name].(type) {
+		case string:
+			scopes = append(scopes, strings.Fields(value)...)
+		case []interface{}:
+			for _, item := range value {
+				if scope, ok := item.(string); ok {
+					scopes = append(scopes, scope)
+				}
+			}
+		}
+	}
+
+	return &Principal{
+		ID:     sub,
+		Scopes: scopes,
+		Method: jwtMethod,
+		Claims: claims,
+	}, nil
+}
+
+// Verify verifies the signature and claims of a token and returns its claims
+func (v *JWTVerifier) Verify(token string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeJSONSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: invalid header: %v", ErrInvalidToken, err)
+	}
+
+	if v.Keys == nil {
+		return nil, fmt.Errorf("%w: no keys", ErrInvalidToken)
+	}
+	key, ok := v.Keys.lookup(header.Kid)
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidToken, header.Kid)
+	}
+	if header.Alg != key.alg {
+		return nil, fmt.Errorf("%w: algorithm %q does not match the key", ErrInvalidToken, header.Alg)
+	}
+
+	signature, err := decodeSegment(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid signature encoding", ErrInvalidToken)
+	}
+	if !verifySignature(key, parts[0]+"."+parts[1], signature) {
+		return nil, fmt.Errorf("%w: invalid signature", ErrInvalidToken)
+	}
+
+	claims := map[string]interface{}{}
+	if err := decodeJSONSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: invalid claims: %v", ErrInvalidToken, err)
+	}
+	if err := v.validateClaims(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// validates the time, issuer and audience claims
+func (v *JWTVerifier) validateClaims(claims map[string]interface{}) error {
+	now := time.Now()
+	if v.Now != nil {
+		now = v.Now()
+	}
+
+	exp, hasExp, err := numericClaim(claims, "exp")
+	if err != nil {
+		return err
+	}
+	if !hasExp && !v.AllowMissingExpiry {
+		return fmt.Errorf("%w: missing exp claim", ErrInvalidToken)
+	}
+	if hasExp && !now.Before(unixTime(exp).Add(v.Leeway)) {
+		return ErrTokenExpired
+	}
+
+	nbf, hasNbf, err := numericClaim(claims, "nbf")
+	if err != nil {
+		return err
+	}
+	if hasNbf && now.Add(v.Leeway).Before(unixTime(nbf)) {
+		return ErrTokenNotValidYet
+	}
+
+	if v.Issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != v.Issuer {
+			return fmt.Errorf("%w: unexpected issuer %q", ErrInvalidToken, iss)
+		}
+	}
+
+	if v.Audience != "" {
+		found := false
+		switch aud := claims["aud"].(type) {
+		case string:
+			found = aud == v.Audience
+		case []interface{}:
+			for _, item := range aud {
+				if item == v.Audience {
+					found = true
+					break
+				}
+			}
+		}
+		if !found {
+			return fmt.Errorf("%w: token is not intended for audience %q", ErrInvalidToken, v.Audience)
+		}
+	}
+
+	return nil
+}
+
+// Authenticate verifies a token and maps its claims to a principal
+func (v *JWTVerifier) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	claims, err := v.Verify(token)
+	if err != nil {
+		return nil, err
+	}
+
+	mapper := v.Mapper
+	if mapper == nil {
+		mapper = DefaultClaimsMapper
+	}
+	return mapper(claims)
+}
+
+// ContextFunc authenticates the bearer token of a request. Requests without
+// a token are anonymous, the error of an invalid token is reported by @hasScope
+func (v *JWTVerifier) ContextFunc() server.ContextFunc {
+	return contextFunc(func(r *http.Request) string {
+		return authorizationCredential(r, bearerScheme)
+	}, v.Authenticate)
+}
+
+// AuthenticateFunc authenticates the token of a connection_init payload,
+// connections with an invalid token are rejected and connections without a
+// token are anonymous
+func (v *JWTVerifier) AuthenticateFunc() graphqlws.AuthenticateFunc {
+	return authenticateFunc(func(data map[string]interface{}) string {
+		return payloadCredential(data, "", bearerScheme)
+	}, v.Authenticate)
+}
+
+// verifies the signature of the signing input with the key
+func verifySignature(key verificationKey, input string, signature []byte) bool {
+	digest := sha256.Sum256([]byte(input))
+
+	switch key.alg {
+	case HS256:
+		mac := hmac.New(sha256.New, key.key.([]byte))
+		mac.Write([]byte(input))
+		return hmac.Equal(signature, mac.Sum(nil))
+
+	case RS256:
+		return rsa.VerifyPKCS1v15(key.key.(*rsa.PublicKey), crypto.SHA256, digest[:], signature) == nil
+
+	case ES256:
+		if len(signature) != 64 {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		return ecdsa.Verify(key.key.(*ecdsa.PublicKey), digest[:], r, s)
+	}
+	return false
+}
+
+func decodeSegment(segment string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(segment, "="))
+}
+
+func decodeJSONSegment(segment string, v interface{}) error {
+	data, err := decodeSegment(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// gets a NumericDate claim, present claims that are not numbers are invalid
+func numericClaim(claims map[string]interface{}, name string) (float64, bool, error) {
+	value, ok := claims[name]
+	if !ok {
+		return 0, false, nil
+	}
+	seconds, ok := value.(float64)
+	if !ok {
+		return 0, false, fmt.Errorf("%w: %s claim is not a number", ErrInvalidToken, name)
+	}
+	return seconds, true, nil
+}
+
+func unixTime(seconds float64) time.Time {
+	return time.Unix(0, int64(seconds*float64(time.Second)))
+}
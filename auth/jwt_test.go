@@ -0,0 +1,248 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/rohit20001221/graphql-go-tools/server"
+	"github.com/rohit20001221/graphql-go-tools/server/graphqlws"
+)
+
+func encodeSegment(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// signs a token with an HMAC secret, RSA or ECDSA private key
+func signToken(t *testing.T, alg, kid string, key interface{}, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	input := encodeSegment(header) + "." + encodeSegment(payload)
+	digest := sha256.Sum256([]byte(input))
+
+	var signature []byte
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(input))
+		signature = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		sig, err := rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		signature = sig
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	}
+
+	return input + "." + encodeSegment(signature)
+}
+
+func TestJWTVerify(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := []byte("secret")
+
+	// load the public keys from a jwks file
+	jwks, _ := json.Marshal(map[string]interface{}{
+		"keys": []JWK{
+			{Kid: "hmac", Kty: "oct", K: encodeSegment(secret)},
+			{Kid: "rsa", Kty: "RSA", Use: "sig", N: encodeSegment(rsaKey.N.Bytes()), E: encodeSegment(big.NewInt(int64(rsaKey.E)).Bytes())},
+			{Kid: "ec", Kty: "EC", Crv: "P-256", X: encodeSegment(ecKey.X.Bytes()), Y: encodeSegment(ecKey.Y.Bytes())},
+		},
+	})
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, jwks, 0644); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := LoadJWKSFile(path)
+	if err != nil {
+		t.Fatalf("failed to load jwks: %v", err)
+	}
+
+	now := time.Unix(1700000000, 0)
+	verifier := NewJWTVerifier(keys)
+	verifier.Issuer = "https://auth.example.com"
+	verifier.Audience = "orders-api"
+	verifier.Leeway = time.Minute
+	verifier.Now = func() time.Time { return now }
+
+	claims := func(overrides map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{
+			"sub":   "user-1",
+			"iss":   "https://auth.example.com",
+			"aud":   []string{"orders-api", "other"},
+			"exp":   now.Add(time.Hour).Unix(),
+			"nbf":   now.Add(-time.Hour).Unix(),
+			"scope": "orders:read orders:write",
+		}
+		for k, v := range overrides {
+			if v == nil {
+				delete(c, k)
+				continue
+			}
+			c[k] = v
+		}
+		return c
+	}
+
+	for _, tt := range []struct {
+		name  string
+		token string
+		err   error
+	}{
+		{"HS256", signToken(t, HS256, "hmac", secret, claims(nil)), nil},
+		{"RS256", signToken(t, RS256, "rsa", rsaKey, claims(nil)), nil},
+		{"ES256", signToken(t, ES256, "ec", ecKey, claims(nil)), nil},
+		{"within leeway", signToken(t, RS256, "rsa", rsaKey, claims(map[string]interface{}{"exp": now.Add(-30 * time.Second).Unix()})), nil},
+		{"expired", signToken(t, RS256, "rsa", rsaKey, claims(map[string]interface{}{"exp": now.Add(-time.Hour).Unix()})), ErrTokenExpired},
+		{"not valid yet", signToken(t, RS256, "rsa", rsaKey, claims(map[string]interface{}{"nbf": now.Add(time.Hour).Unix()})), ErrTokenNotValidYet},
+		{"issuer", signToken(t, RS256, "rsa", rsaKey, claims(map[string]interface{}{"iss": "https://evil.example.com"})), ErrInvalidToken},
+		{"audience", signToken(t, RS256, "rsa", rsaKey, claims(map[string]interface{}{"aud": "billing-api"})), ErrInvalidToken},
+		{"unknown key", signToken(t, RS256, "missing", rsaKey, claims(nil)), ErrInvalidToken},
+		{"algorithm confusion", signToken(t, HS256, "rsa", secret, claims(nil)), ErrInvalidToken},
+		{"wrong key", signToken(t, HS256, "hmac", []byte("other"), claims(nil)), ErrInvalidToken},
+		{"malformed", "not-a-token", ErrInvalidToken},
+		{"missing expiry", signToken(t, RS256, "rsa", rsaKey, claims(map[string]interface{}{"exp": nil})), ErrInvalidToken},
+		{"non-numeric expiry", signToken(t, RS256, "rsa", rsaKey, claims(map[string]interface{}{"exp": "never"})), ErrInvalidToken},
+		{"non-numeric not before", signToken(t, RS256, "rsa", rsaKey, claims(map[string]interface{}{"nbf": "now"})), ErrInvalidToken},
+	} {
+		principal, err := verifier.Authenticate(context.Background(), tt.token)
+		if tt.err != nil {
+			if !errors.Is(err, tt.err) {
+				t.Errorf("%s: expected %v, got %v", tt.name, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+			continue
+		}
+		if principal.ID != "user-1" || !principal.HasScope("orders:write") || principal.Method != "jwt" {
+			t.Errorf("%s: unexpected principal %+v", tt.name, principal)
+		}
+	}
+}
+
+func TestJWTVerifyOptions(t *testing.T) {
+	secret := []byte("secret")
+	keys := NewKeySet()
+	keys.AddHMAC("hmac", secret)
+	verifier := NewJWTVerifier(keys)
+	verifier.AllowMissingExpiry = true
+
+	token := signToken(t, HS256, "hmac", secret, map[string]interface{}{"sub": "user-1"})
+	if _, err := verifier.Authenticate(context.Background(), token); err != nil {
+		t.Errorf("expected a token without expiry to be allowed, got %v", err)
+	}
+
+	for _, alg := range []string{"PS256", "RS384"} {
+		err := NewKeySet().AddJWK(JWK{Kid: "rsa", Kty: "RSA", Alg: alg, N: encodeSegment([]byte{1}), E: encodeSegment([]byte{1})})
+		if err == nil || !strings.Contains(err.Error(), "unsupported algorithm") {
+			t.Errorf("expected a %s key to be rejected, got %v", alg, err)
+		}
+	}
+	if err := NewKeySet().AddJWK(JWK{Kid: "hmac", Kty: "oct", Alg: HS256, K: encodeSegment(secret)}); err != nil {
+		t.Errorf("expected an HS256 key to be added, got %v", err)
+	}
+}
+
+type testConnection struct {
+	ctx context.Context
+}
+
+func (c *testConnection) ID() string                                     { return "test" }
+func (c *testConnection) Context() context.Context                       { return c.ctx }
+func (c *testConnection) WS() *websocket.Conn                            { return nil }
+func (c *testConnection) SendData(string, *graphqlws.DataMessagePayload) {}
+func (c *testConnection) SendError(error)                                {}
+
+func TestJWTTransports(t *testing.T) {
+	secret := []byte("secret")
+	keys := NewKeySet()
+	keys.AddHMAC("", secret)
+	verifier := NewJWTVerifier(keys)
+	verifier.Mapper = func(claims map[string]interface{}) (*Principal, error) {
+		principal, err := DefaultClaimsMapper(claims)
+		if err == nil && claims["admin"] == true {
+			principal.Scopes = append(principal.Scopes, "admin")
+		}
+		return principal, err
+	}
+
+	token := signToken(t, HS256, "", secret, map[string]interface{}{"sub": "user-1", "admin": true, "exp": time.Now().Add(time.Hour).Unix()})
+
+	srv := httptest.NewServer(server.New(makeScopedSchema(t), &server.Options{
+		ContextFunc: verifier.ContextFunc(),
+	}))
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader(`{"query":"{ orders whoami }"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	var result graphqlResponse
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Errors) != 0 || result.Data["orders"] != "orders" || result.Data["whoami"] != "user-1" {
+		t.Errorf("expected the mapped admin scope to be authorized, got %+v", result)
+	}
+
+	authenticate := verifier.AuthenticateFunc()
+	conn := &testConnection{ctx: context.Background()}
+	for _, payload := range []map[string]interface{}{
+		{"authToken": token},
+		{"Authorization": "Bearer " + token},
+	} {
+		ctx, err := authenticate(payload, conn)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if principal, ok := FromContext(ctx); !ok || principal.ID != "user-1" {
+			t.Errorf("expected a principal for payload %v", payload)
+		}
+	}
+
+	if _, err := authenticate(map[string]interface{}{"authToken": "invalid"}, conn); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("expected the connection to be rejected, got %v", err)
+	}
+	if ctx, err := authenticate(map[string]interface{}{}, conn); err != nil || ctx != conn.ctx {
+		t.Errorf("expected connections without a token to be anonymous, got %v", err)
+	}
+}
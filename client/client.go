@@ -0,0 +1,122 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// Request a graphql request sent by a Client
+type Request struct {
+	Query         string      `json:"query"`
+	Variables     interface{} `json:"variables,omitempty"`
+	OperationName string      `json:"operationName,omitempty"`
+}
+
+// Client executes graphql requests and decodes the response data
+// into the supplied value. Generated operation functions accept any Client
+type Client interface {
+	Do(ctx context.Context, req *Request, data interface{}) error
+}
+
+// Error a graphql error returned in a response
+type Error struct {
+	Message    string                 `json:"message"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+func (e Error) Error() string {
+	return e.Message
+}
+
+// Errors the list of errors returned in a response
+type Errors []Error
+
+func (e Errors) Error() string {
+	messages := []string{}
+	for _, err := range e {
+		messages = append(messages, err.Message)
+	}
+	return fmt.Sprintf("graphql: %s", strings.Join(messages, "; "))
+}
+
+type response struct {
+	Data   json.RawMessage `json:"data"`
+	Errors Errors          `json:"errors"`
+}
+
+// HTTPClient a Client that posts requests as JSON to an endpoint
+type HTTPClient struct {
+	Endpoint   string
+	Header     http.Header
+	HTTPClient *http.Client
+}
+
+// NewHTTPClient creates a new http client for the endpoint
+func NewHTTPClient(endpoint string) *HTTPClient {
+	return &HTTPClient{
+		Endpoint:   endpoint,
+		Header:     http.Header{},
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+// Do executes the request, any graphql errors are returned as Errors
+// after the data has been decoded
+func (c *HTTPClient) Do(ctx context.Context, req *Request, data interface{}) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, c.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	for key, values := range c.Header {
+		for _, value := range values {
+			r.Header.Add(key, value)
+		}
+	}
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Accept", "application/json")
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	res, err := httpClient.Do(r)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	resBody, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	var result response
+	if err := json.Unmarshal(resBody, &result); err != nil {
+		return fmt.Errorf("graphql: unexpected response (status %d): %v", res.StatusCode, err)
+	}
+
+	if len(result.Data) > 0 && string(result.Data) != "null" && data != nil {
+		if err := json.Unmarshal(result.Data, data); err != nil {
+			return err
+		}
+	}
+
+	if len(result.Errors) > 0 {
+		return result.Errors
+	}
+
+	return nil
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	tools "github.com/rohit20001221/graphql-go-tools"
+	"github.com/rohit20001221/graphql-go-tools/codegen"
+	"github.com/rohit20001221/graphql-go-tools/schemadiff"
+)
+
+func runChangelog(args []string) error {
+	flags := flag.NewFlagSet("changelog", flag.ExitOnError)
+	gitPath := flags.String("git", "", "schema file or directory in a git repository, arguments are read as revisions of it")
+	format := flags.String("format", "markdown", "output format, markdown or json")
+	out := flags.String("out", "", "output file, defaults to stdout")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: gqltools changelog [flags] <snapshot> <snapshot>...")
+		fmt.Fprintln(os.Stderr, "")
+		fmt.Fprintln(os.Stderr, "snapshots are schema files or directories ordered from the oldest to the newest,")
+		fmt.Fprintln(os.Stderr, "or git revisions when -git is set")
+		fmt.Fprintln(os.Stderr, "")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() < 2 {
+		flags.Usage()
+		return fmt.Errorf("at least two snapshots are required")
+	}
+	if *format != "markdown" && *format != "json" {
+		return fmt.Errorf("unsupported format %q, supported formats: markdown, json", *format)
+	}
+
+	versions := []schemadiff.Version{}
+	for _, snapshot := range flags.Args() {
+		var typeDefs string
+		var err error
+		if *gitPath != "" {
+			typeDefs, err = readGitGraphQL(*gitPath, snapshot)
+		} else {
+			typeDefs, err = readGraphQL(snapshot)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %v", snapshot, err)
+		}
+
+		schema, err := codegen.LoadSchema(typeDefs)
+		if err != nil {
+			return fmt.Errorf("failed to load %s: %v", snapshot, err)
+		}
+		versions = append(versions, schemadiff.Version{Name: snapshot, Schema: schema})
+	}
+
+	changelog := schemadiff.NewChangelog(versions)
+
+	var buf bytes.Buffer
+	if *format == "json" {
+		enc := json.NewEncoder(&buf)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(changelog); err != nil {
+			return err
+		}
+	} else if err := changelog.WriteMarkdown(&buf); err != nil {
+		return err
+	}
+
+	if *out == "" {
+		_, err := os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	return ioutil.WriteFile(*out, buf.Bytes(), 0644)
+}
+
+// reads a schema file or the graphql files of a directory at a git revision.
+// directories are written to a temporary directory and read with ReadSourceFiles
+func readGitGraphQL(schemaPath, revision string) (string, error) {
+	dir := filepath.Dir(schemaPath)
+	if info, err := os.Stat(schemaPath); err == nil && info.IsDir() {
+		dir = schemaPath
+	}
+
+	// revisions are resolved to a commit so they cannot be parsed as options
+	if strings.HasPrefix(revision, "-") {
+		return "", fmt.Errorf("invalid revision %q", revision)
+	}
+	commit, err := git(dir, "rev-parse", "--verify", "--end-of-options", revision+"^{commit}")
+	if err != nil {
+		return "", err
+	}
+	revision = strings.TrimSpace(commit)
+
+	// paths in the tree are relative to the repository root
+	prefix, err := git(dir, "rev-parse", "--show-prefix")
+	if err != nil {
+		return "", err
+	}
+	prefix = strings.TrimSpace(prefix)
+
+	treePath := path.Clean(prefix)
+	if dir != schemaPath {
+		treePath = path.Join(prefix, filepath.Base(schemaPath))
+	}
+
+	objectType, err := git(dir, "cat-file", "-t", revision+":"+treePath)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(objectType) != "tree" {
+		return git(dir, "show", revision+":"+treePath)
+	}
+
+	files, err := git(dir, "ls-tree", "-r", "--name-only", "--full-tree", revision, "--", treePath)
+	if err != nil {
+		return "", err
+	}
+
+	tmp, err := ioutil.TempDir("", "gqltools-changelog")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmp)
+
+	for _, file := range strings.Split(strings.TrimSpace(files), "\n") {
+		if !strings.HasSuffix(file, ".graphql") {
+			continue
+		}
+
+		content, err := git(dir, "show", revision+":"+file)
+		if err != nil {
+			return "", err
+		}
+
+		rel := strings.TrimPrefix(strings.TrimPrefix(file, treePath), "/")
+		target := filepath.Join(tmp, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return "", err
+		}
+		if err := ioutil.WriteFile(target, []byte(content), 0644); err != nil {
+			return "", err
+		}
+	}
+
+	return tools.ReadSourceFiles(tmp, true)
+}
+
+// runs a git command in a directory and returns its output
+func git(dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %s", strings.Join(args, " "), strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
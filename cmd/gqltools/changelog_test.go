@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// creates a git repository with a commit for each version of the schema files
+func makeGitRepo(t *testing.T, versions ...map[string]string) string {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repo := t.TempDir()
+	if _, err := git(repo, "init", "-q"); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, files := range versions {
+		for name, content := range files {
+			path := filepath.Join(repo, filepath.FromSlash(name))
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := git(repo, "add", "-A"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := git(repo, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", fmt.Sprintf("version %d", i+1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return repo
+}
+
+func TestReadGitGraphQL(t *testing.T) {
+	repo := makeGitRepo(t,
+		map[string]string{
+			"schema.graphql":           "type Query { user: User }",
+			"schema/user.graphql":      "type User { id: ID! }",
+			"schema/nested/ok.graphql": "type Post { id: ID! }",
+		},
+		map[string]string{
+			"schema.graphql":      "type Query { user: User, users: [User] }",
+			"schema/user.graphql": "type User { id: ID!, name: String }",
+		},
+	)
+
+	typeDefs, err := readGitGraphQL(filepath.Join(repo, "schema.graphql"), "HEAD~1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if typeDefs != "type Query { user: User }" {
+		t.Errorf("unexpected schema file at HEAD~1 %q", typeDefs)
+	}
+
+	typeDefs, err = readGitGraphQL(filepath.Join(repo, "schema"), "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(typeDefs, "name: String") || !strings.Contains(typeDefs, "type Post") {
+		t.Errorf("unexpected schema directory at HEAD %q", typeDefs)
+	}
+
+	for _, revision := range []string{"--output=" + filepath.Join(repo, "out"), "-h", "unknown"} {
+		if _, err := readGitGraphQL(filepath.Join(repo, "schema.graphql"), revision); err == nil {
+			t.Errorf("expected revision %q to be rejected", revision)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(repo, "out")); err == nil {
+		t.Error("expected the revision not to be parsed as an option")
+	}
+}
+
+func TestRunChangelogGit(t *testing.T) {
+	repo := makeGitRepo(t,
+		map[string]string{"schema.graphql": "type Query { user: String, users: [String] }"},
+		map[string]string{"schema.graphql": "type Query { user: String }"},
+	)
+
+	out := filepath.Join(t.TempDir(), "CHANGELOG.md")
+	if err := runChangelog([]string{"-git", filepath.Join(repo, "schema.graphql"), "-out", out, "HEAD~1", "HEAD"}); err != nil {
+		t.Fatal(err)
+	}
+
+	changelog, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(changelog), "users") {
+		t.Errorf("expected the removed field in the changelog\n%s", changelog)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	tools "github.com/rohit20001221/graphql-go-tools"
+	"github.com/rohit20001221/graphql-go-tools/codegen"
+)
+
+// repeatable flag of name=value pairs
+type mappingFlag map[string]string
+
+func (m mappingFlag) String() string {
+	pairs := []string{}
+	for k, v := range m {
+		pairs = append(pairs, k+"="+v)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (m mappingFlag) Set(value string) error {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return fmt.Errorf("expected Name=value, got %q", value)
+	}
+	m[parts[0]] = parts[1]
+	return nil
+}
+
+func runCodegen(args []string) error {
+	if len(args) == 0 || args[0] != "go-client" {
+		return fmt.Errorf("expected a generator, supported generators: go-client")
+	}
+
+	scalars := mappingFlag{}
+	flags := flag.NewFlagSet("codegen go-client", flag.ExitOnError)
+	schemaPath := flags.String("schema", "", "schema file or directory of .graphql files")
+	operationsPath := flags.String("operations", "", "operations file or directory of .graphql files")
+	pkg := flags.String("package", "client", "package name of the generated file")
+	out := flags.String("out", "", "output file, defaults to stdout")
+	flags.Var(scalars, "scalar", "custom scalar mapping as Name=go/import/path.Type, may be repeated")
+	flags.Parse(args[1:])
+
+	if *schemaPath == "" || *operationsPath == "" {
+		flags.Usage()
+		return fmt.Errorf("-schema and -operations are required")
+	}
+
+	typeDefs, err := readGraphQL(*schemaPath)
+	if err != nil {
+		return err
+	}
+
+	operations, err := readGraphQL(*operationsPath)
+	if err != nil {
+		return err
+	}
+
+	schema, err := codegen.LoadSchema(typeDefs)
+	if err != nil {
+		return err
+	}
+
+	document, err := codegen.ParseOperations(operations)
+	if err != nil {
+		return err
+	}
+
+	source, err := codegen.GenerateGoClient(schema, document, codegen.GoClientConfig{
+		Package: *pkg,
+		Scalars: scalars,
+	})
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		_, err = os.Stdout.Write(source)
+		return err
+	}
+	return ioutil.WriteFile(*out, source, 0644)
+}
+
+// reads a single file or all graphql files in a directory
+func readGraphQL(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+
+	if info.IsDir() {
+		return tools.ReadSourceFiles(path, true)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,63 @@
+// Command gqltools provides tooling for graphql-go-tools schemas
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+// a sub command of gqltools
+type command struct {
+	usage string
+	run   func(args []string) error
+}
+
+var commands = map[string]command{
+	"changelog": {
+		usage: "changelog [flags] <snapshot>...    render a changelog from schema snapshots or git revisions",
+		run:   runChangelog,
+	},
+	"codegen": {
+		usage: "codegen go-client [flags]    generate a typed Go client from operations",
+		run:   runCodegen,
+	},
+	"registry": {
+		usage: "registry serve|publish|check [flags]    run or publish to a schema registry",
+		run:   runRegistry,
+	},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "gqltools: unknown command %q\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+
+	if err := cmd.run(os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "gqltools %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	names := []string{}
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(os.Stderr, "usage: gqltools <command> [arguments]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "commands:")
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %s\n", commands[name].usage)
+	}
+}
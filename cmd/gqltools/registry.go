@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/rohit20001221/graphql-go-tools/schemadiff"
+	"github.com/rohit20001221/graphql-go-tools/schemaregistry"
+)
+
+// logs registry messages to stderr
+type stderrLogger struct{}
+
+func (l *stderrLogger) Infof(format string, data ...interface{})  { log.Printf(format, data...) }
+func (l *stderrLogger) Debugf(format string, data ...interface{}) {}
+func (l *stderrLogger) Errorf(format string, data ...interface{}) {
+	log.Printf("error: "+format, data...)
+}
+func (l *stderrLogger) Warnf(format string, data ...interface{}) {
+	log.Printf("warning: "+format, data...)
+}
+
+func runRegistry(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("expected a sub command, supported sub commands: serve, publish, check")
+	}
+
+	switch args[0] {
+	case "serve":
+		return runRegistryServe(args[1:])
+	case "publish":
+		return runRegistryPublish(args[1:], false)
+	case "check":
+		return runRegistryPublish(args[1:], true)
+	}
+	return fmt.Errorf("unknown sub command %q, supported sub commands: serve, publish, check", args[0])
+}
+
+func runRegistryServe(args []string) error {
+	flags := flag.NewFlagSet("registry serve", flag.ExitOnError)
+	addr := flags.String("addr", ":8080", "listen address")
+	dir := flags.String("dir", "./schemas", "directory schemas are stored in")
+	flags.Parse(args)
+
+	store, err := schemaregistry.NewFileStore(*dir)
+	if err != nil {
+		return err
+	}
+
+	registry := schemaregistry.New(store)
+	registry.Logger = &stderrLogger{}
+
+	log.Printf("schema registry listening on %s storing schemas in %s", *addr, *dir)
+	return http.ListenAndServe(*addr, registry.Handler())
+}
+
+// publishes or checks a schema against a running registry
+func runRegistryPublish(args []string, dryRun bool) error {
+	name := "registry publish"
+	if dryRun {
+		name = "registry check"
+	}
+
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	registryURL := flags.String("url", "http://localhost:8080", "registry url")
+	service := flags.String("service", "", "service name")
+	variant := flags.String("variant", "current", "schema variant such as prod or staging")
+	schemaPath := flags.String("schema", "", "schema file or directory of .graphql files")
+	force := flags.Bool("force", false, "publish even if the schema has breaking changes")
+	flags.Parse(args)
+
+	if *service == "" || *schemaPath == "" {
+		flags.Usage()
+		return fmt.Errorf("-service and -schema are required")
+	}
+
+	sdl, err := readGraphQL(*schemaPath)
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	if *force {
+		query.Set("force", "true")
+	}
+	if dryRun {
+		query.Set("dryRun", "true")
+	}
+
+	endpoint := fmt.Sprintf("%s/schemas/%s/%s?%s", strings.TrimRight(*registryURL, "/"), url.PathEscape(*service), url.PathEscape(*variant), query.Encode())
+	res, err := http.Post(endpoint, "application/graphql", bytes.NewBufferString(sdl))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	var result schemaregistry.PublishResult
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("registry responded %s: %s", res.Status, strings.TrimSpace(string(body)))
+	}
+
+	printChanges(os.Stdout, result.Changes)
+
+	switch {
+	case res.StatusCode == http.StatusConflict:
+		return fmt.Errorf("schema has breaking changes, use -force to publish anyway")
+	case dryRun && result.Changes.HasBreaking():
+		return fmt.Errorf("schema has breaking changes")
+	case result.Published:
+		fmt.Printf("published %s/%s version %d\n", *service, *variant, result.Version.Version)
+	case !dryRun && result.Version != nil:
+		fmt.Printf("schema unchanged, latest version is %d\n", result.Version.Version)
+	}
+	return nil
+}
+
+func printChanges(w io.Writer, changes schemadiff.Changes) {
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "no changes")
+		return
+	}
+	for _, change := range changes {
+		fmt.Fprintf(w, "%-9s %s\n", change.Criticality, change.Message)
+	}
+}
@@ -0,0 +1,12 @@
+// Command resolvercheck checks ExecutableSchema resolver maps against their
+// TypeDefs, run it directly or with go vet -vettool=$(which resolvercheck)
+package main
+
+import (
+	"github.com/rohit20001221/graphql-go-tools/resolvercheck"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(resolvercheck.Analyzer)
+}
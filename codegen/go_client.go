@@ -0,0 +1,801 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/printer"
+)
+
+// ClientImportPath the import path of the runtime client used by generated code
+const ClientImportPath = "github.com/rohit20001221/graphql-go-tools/client"
+
+// GoClientConfig configuration for generating a typed Go client
+type GoClientConfig struct {
+	Package string            // package name of the generated file
+	Scalars map[string]string // maps custom scalars to Go types, e.g. "DateTime": "time.Time"
+}
+
+// GenerateGoClient generates a Go source file containing a typed function,
+// variables struct and result struct for each named operation in the document.
+// Fragments and abstract types are supported, selections on abstract types
+// are decoded into On<Type> fields using __typename which is added to the
+// operation when not already selected. Nullable variables are sent as null
+// when nil, except variables with a default value which are omitted so the
+// default applies. Names that map to the same Go identifier, such as the
+// response keys a_b and aB, are an error
+func GenerateGoClient(schema graphql.Schema, document *ast.Document, config GoClientConfig) ([]byte, error) {
+	if config.Package == "" {
+		config.Package = "client"
+	}
+
+	if result := graphql.ValidateDocument(&schema, document, nil); !result.IsValid {
+		messages := []string{}
+		for _, err := range result.Errors {
+			messages = append(messages, err.Message)
+		}
+		return nil, fmt.Errorf("invalid operations: %s", strings.Join(messages, "; "))
+	}
+
+	g := &goClientGenerator{
+		schema:    schema,
+		config:    config,
+		imports:   map[string]string{},
+		declared:  map[string]string{},
+		fragments: map[string]*ast.FragmentDefinition{},
+	}
+
+	for _, def := range document.Definitions {
+		if fragment, ok := def.(*ast.FragmentDefinition); ok {
+			g.fragments[fragment.Name.Value] = fragment
+		}
+	}
+
+	for _, def := range document.Definitions {
+		if op, ok := def.(*ast.OperationDefinition); ok {
+			if err := g.generateOperation(op); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return g.source()
+}
+
+type goClientGenerator struct {
+	schema    graphql.Schema
+	config    GoClientConfig
+	imports   map[string]string
+	declared  map[string]string // the owner of each declared name
+	decls     []string
+	fragments map[string]*ast.FragmentDefinition
+}
+
+// a field in a generated struct
+type goField struct {
+	name string
+	typ  string
+	tag  string
+}
+
+// selections collected for a single response key
+type collectedField struct {
+	key    string
+	fields []*ast.Field
+}
+
+// selections collected for a type condition that does not always apply
+type conditionalGroup struct {
+	typ  graphql.Composite
+	sets []*ast.SelectionSet
+}
+
+func (g *goClientGenerator) source() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by gqltools codegen go-client. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", g.config.Package)
+
+	// standard library imports are grouped before other imports
+	std, other := []string{}, []string{}
+	for path := range g.imports {
+		if strings.Contains(strings.Split(path, "/")[0], ".") {
+			other = append(other, path)
+		} else {
+			std = append(std, path)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(other)
+
+	buf.WriteString("import (\n")
+	for _, path := range std {
+		fmt.Fprintf(&buf, "\t%q\n", path)
+	}
+	if len(std) > 0 && len(other) > 0 {
+		buf.WriteString("\n")
+	}
+	for _, path := range other {
+		fmt.Fprintf(&buf, "\t%q\n", path)
+	}
+	buf.WriteString(")\n\n")
+
+	for _, decl := range g.decls {
+		buf.WriteString(decl)
+		buf.WriteString("\n")
+	}
+
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %v", err)
+	}
+	return formatted, nil
+}
+
+func (g *goClientGenerator) addImport(path string) string {
+	name := path[strings.LastIndex(path, "/")+1:]
+	g.imports[path] = name
+	return name
+}
+
+// declares a name owned by an operation, selection, input or enum. Names
+// are declared once per owner, different owners of a name are an error
+func (g *goClientGenerator) declare(name, owner, decl string) error {
+	if existing, ok := g.declared[name]; ok {
+		if existing == owner {
+			return nil
+		}
+		return fmt.Errorf("%s and %s both generate the Go name %s", existing, owner, name)
+	}
+	g.declared[name] = owner
+	g.decls = append(g.decls, decl)
+	return nil
+}
+
+// checks that the fields of a struct have distinct Go names
+func uniqueFields(owner string, fields []goField) error {
+	keys := map[string]string{}
+	for _, f := range fields {
+		key := strings.Split(f.tag, ",")[0]
+		if key == "-" {
+			key = f.name
+		}
+		if existing, ok := keys[f.name]; ok {
+			return fmt.Errorf("%s: %q and %q both generate the Go field %s", owner, existing, key, f.name)
+		}
+		keys[f.name] = key
+	}
+	return nil
+}
+
+func (g *goClientGenerator) generateOperation(op *ast.OperationDefinition) error {
+	if op.Name == nil || op.Name.Value == "" {
+		return fmt.Errorf("operations must be named to generate a client")
+	}
+
+	opName := exportedName(op.Name.Value)
+	owner := fmt.Sprintf("operation %q", op.Name.Value)
+	var root *graphql.Object
+	switch op.Operation {
+	case ast.OperationTypeQuery:
+		root = g.schema.QueryType()
+	case ast.OperationTypeMutation:
+		root = g.schema.MutationType()
+	default:
+		return fmt.Errorf("operation %q: %s operations are not supported", op.Name.Value, op.Operation)
+	}
+
+	if root == nil {
+		return fmt.Errorf("operation %q: schema does not define a %s type", op.Name.Value, op.Operation)
+	}
+
+	// variables
+	hasVariables := len(op.VariableDefinitions) > 0
+	if hasVariables {
+		fields := []goField{}
+		for _, def := range op.VariableDefinitions {
+			t, err := g.inputTypeFromAST(def.Type)
+			if err != nil {
+				return fmt.Errorf("operation %q: %v", op.Name.Value, err)
+			}
+			// nil sends an explicit null unless a default value applies
+			field, err := g.inputField(def.Variable.Name.Value, t, def.DefaultValue != nil)
+			if err != nil {
+				return fmt.Errorf("operation %q: %v", op.Name.Value, err)
+			}
+			fields = append(fields, field)
+		}
+		if err := uniqueFields(owner+" variables", fields); err != nil {
+			return err
+		}
+		if err := g.declare(opName+"Variables", owner, structDecl(
+			fmt.Sprintf("// %sVariables variables for the %s operation", opName, op.Name.Value),
+			opName+"Variables",
+			fields,
+		)); err != nil {
+			return err
+		}
+	}
+
+	// result
+	resultName := opName + "Result"
+	if _, err := g.selectionStruct(resultName, op.Name.Value, root, []*ast.SelectionSet{op.SelectionSet}); err != nil {
+		return fmt.Errorf("operation %q: %v", op.Name.Value, err)
+	}
+
+	// document
+	documentName := lowerFirst(opName) + "Document"
+	printed, err := g.printOperation(op)
+	if err != nil {
+		return err
+	}
+	if err := g.declare(documentName, owner, fmt.Sprintf(
+		"// %s the %s operation document\nconst %s = %s\n",
+		documentName, op.Name.Value, documentName, quote(printed),
+	)); err != nil {
+		return err
+	}
+
+	// function
+	g.addImport("context")
+	client := g.addImport(ClientImportPath)
+
+	var fn strings.Builder
+	fmt.Fprintf(&fn, "// %s executes the %s %s\n", opName, op.Name.Value, op.Operation)
+	if hasVariables {
+		fmt.Fprintf(&fn, "func %s(ctx context.Context, c %s.Client, vars %sVariables) (*%s, error) {\n", opName, client, opName, resultName)
+	} else {
+		fmt.Fprintf(&fn, "func %s(ctx context.Context, c %s.Client) (*%s, error) {\n", opName, client, resultName)
+	}
+	fmt.Fprintf(&fn, "\treq := &%s.Request{\n\t\tQuery: %s,\n\t\tOperationName: %q,\n", client, documentName, op.Name.Value)
+	if hasVariables {
+		fn.WriteString("\t\tVariables: vars,\n")
+	}
+	fn.WriteString("\t}\n\n")
+	fmt.Fprintf(&fn, "\tvar result %s\n", resultName)
+	fn.WriteString("\tif err := c.Do(ctx, req, &result); err != nil {\n\t\treturn nil, err\n\t}\n")
+	fn.WriteString("\treturn &result, nil\n}\n")
+	return g.declare(opName, owner, fn.String())
+}
+
+// prints the operation along with every fragment it uses
+func (g *goClientGenerator) printOperation(op *ast.OperationDefinition) (string, error) {
+	used := map[string]bool{}
+	g.usedFragments(op.SelectionSet, used)
+
+	names := []string{}
+	for name := range used {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	document := ast.NewDocument(&ast.Document{
+		Definitions: []ast.Node{op},
+	})
+	for _, name := range names {
+		fragment, ok := g.fragments[name]
+		if !ok {
+			return "", fmt.Errorf("unknown fragment %q", name)
+		}
+		document.Definitions = append(document.Definitions, fragment)
+	}
+
+	printed, ok := printer.Print(document).(string)
+	if !ok {
+		return "", fmt.Errorf("failed to print operation %q", op.Name.Value)
+	}
+	return strings.TrimSpace(printed), nil
+}
+
+func (g *goClientGenerator) usedFragments(set *ast.SelectionSet, used map[string]bool) {
+	if set == nil {
+		return
+	}
+	for _, sel := range set.Selections {
+		switch s := sel.(type) {
+		case *ast.Field:
+			g.usedFragments(s.SelectionSet, used)
+		case *ast.InlineFragment:
+			g.usedFragments(s.SelectionSet, used)
+		case *ast.FragmentSpread:
+			name := s.Name.Value
+			if used[name] {
+				continue
+			}
+			used[name] = true
+			if fragment, ok := g.fragments[name]; ok {
+				g.usedFragments(fragment.SelectionSet, used)
+			}
+		}
+	}
+}
+
+// determines if a fragment with the type condition always applies to the parent type
+func (g *goClientGenerator) alwaysApplies(parent graphql.Composite, condition *ast.Named) bool {
+	if condition == nil || condition.Name.Value == parent.Name() {
+		return true
+	}
+
+	object, ok := parent.(*graphql.Object)
+	if !ok {
+		return false
+	}
+
+	switch t := g.schema.Type(condition.Name.Value).(type) {
+	case *graphql.Interface:
+		for _, iface := range object.Interfaces() {
+			if iface.Name() == t.Name() {
+				return true
+			}
+		}
+	case *graphql.Union:
+		for _, member := range t.Types() {
+			if member.Name() == object.Name() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// collects the fields of selection sets, grouping fragments that only
+// apply to some of the possible types of the parent
+func (g *goClientGenerator) collect(
+	parent graphql.Composite,
+	sets []*ast.SelectionSet,
+	fields *[]*collectedField,
+	groups *[]*conditionalGroup,
+) error {
+	for _, set := range sets {
+		if err := g.collectSet(parent, set, fields, groups); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *goClientGenerator) collectSet(
+	parent graphql.Composite,
+	set *ast.SelectionSet,
+	fields *[]*collectedField,
+	groups *[]*conditionalGroup,
+) error {
+	for _, sel := range set.Selections {
+		switch s := sel.(type) {
+		case *ast.Field:
+			key := s.Name.Value
+			if s.Alias != nil {
+				key = s.Alias.Value
+			}
+
+			found := false
+			for _, f := range *fields {
+				if f.key == key {
+					f.fields = append(f.fields, s)
+					found = true
+					break
+				}
+			}
+			if !found {
+				*fields = append(*fields, &collectedField{key: key, fields: []*ast.Field{s}})
+			}
+		case *ast.InlineFragment:
+			if err := g.collectFragment(parent, s.TypeCondition, s.SelectionSet, fields, groups); err != nil {
+				return err
+			}
+		case *ast.FragmentSpread:
+			fragment, ok := g.fragments[s.Name.Value]
+			if !ok {
+				return fmt.Errorf("unknown fragment %q", s.Name.Value)
+			}
+			if err := g.collectFragment(parent, fragment.TypeCondition, fragment.SelectionSet, fields, groups); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (g *goClientGenerator) collectFragment(
+	parent graphql.Composite,
+	condition *ast.Named,
+	set *ast.SelectionSet,
+	fields *[]*collectedField,
+	groups *[]*conditionalGroup,
+) error {
+	if g.alwaysApplies(parent, condition) {
+		return g.collectSet(parent, set, fields, groups)
+	}
+
+	typ, ok := g.schema.Type(condition.Name.Value).(graphql.Composite)
+	if !ok {
+		return fmt.Errorf("fragment type condition %q is not a composite type", condition.Name.Value)
+	}
+
+	for _, group := range *groups {
+		if group.typ.Name() == typ.Name() {
+			group.sets = append(group.sets, set)
+			return nil
+		}
+	}
+	*groups = append(*groups, &conditionalGroup{typ: typ, sets: []*ast.SelectionSet{set}})
+	return nil
+}
+
+// generates a struct for the selections on a composite type and returns its
+// name, the path of response keys identifies the selection in errors
+func (g *goClientGenerator) selectionStruct(name, path string, parent graphql.Composite, sets []*ast.SelectionSet) (string, error) {
+	fields := []*collectedField{}
+	groups := []*conditionalGroup{}
+	if err := g.collect(parent, sets, &fields, &groups); err != nil {
+		return "", err
+	}
+
+	needsTypename := isAbstract(parent) || len(groups) > 0
+
+	goFields := []goField{}
+	hasTypename := false
+	for _, f := range fields {
+		fieldName := f.fields[0].Name.Value
+		if fieldName == "__typename" {
+			if f.key == "__typename" {
+				hasTypename = true
+			}
+			goFields = append(goFields, goField{name: typenameField(f.key), typ: "string", tag: f.key})
+			continue
+		}
+
+		def := fieldDefinition(parent, fieldName)
+		if def == nil {
+			return "", fmt.Errorf("no field %q on type %q", fieldName, parent.Name())
+		}
+
+		subSets := []*ast.SelectionSet{}
+		for _, field := range f.fields {
+			if field.SelectionSet != nil {
+				subSets = append(subSets, field.SelectionSet)
+			}
+		}
+
+		typ, err := g.outputType(name+exportedName(f.key), path+"."+f.key, def.Type, f.fields[0], subSets, false)
+		if err != nil {
+			return "", err
+		}
+		goFields = append(goFields, goField{name: exportedName(f.key), typ: typ, tag: f.key})
+	}
+
+	if needsTypename && !hasTypename {
+		goFields = append([]goField{{name: "Typename", typ: "string", tag: "__typename"}}, goFields...)
+	}
+
+	// conditional fragments are decoded separately
+	cases := []string{}
+	for _, group := range groups {
+		groupName, err := g.selectionStruct(name+"On"+group.typ.Name(), path+".on "+group.typ.Name(), group.typ, group.sets)
+		if err != nil {
+			return "", err
+		}
+		fieldName := "On" + group.typ.Name()
+		goFields = append(goFields, goField{name: fieldName, typ: "*" + groupName, tag: "-"})
+
+		typenames := []string{}
+		for _, object := range g.possibleTypes(group.typ) {
+			typenames = append(typenames, strconv.Quote(object.Name()))
+		}
+		if len(typenames) > 0 {
+			cases = append(cases, fmt.Sprintf(
+				"\tswitch v.Typename {\n\tcase %s:\n\t\tv.%s = new(%s)\n\t\tif err := json.Unmarshal(data, v.%s); err != nil {\n\t\t\treturn err\n\t\t}\n\t}\n",
+				strings.Join(typenames, ", "), fieldName, groupName, fieldName,
+			))
+		}
+	}
+
+	owner := fmt.Sprintf("selection %s", path)
+	if err := uniqueFields(owner, goFields); err != nil {
+		return "", err
+	}
+
+	decl := structDecl(fmt.Sprintf("// %s selection on %s", name, parent.Name()), name, goFields)
+	if len(groups) > 0 {
+		g.addImport("encoding/json")
+		var fn strings.Builder
+		fmt.Fprintf(&fn, "\n// UnmarshalJSON decodes the %s selection and any fragments matching __typename\n", name)
+		fmt.Fprintf(&fn, "func (v *%s) UnmarshalJSON(data []byte) error {\n", name)
+		fmt.Fprintf(&fn, "\ttype plain %s\n", name)
+		fn.WriteString("\tif err := json.Unmarshal(data, (*plain)(v)); err != nil {\n\t\treturn err\n\t}\n\n")
+		for _, c := range cases {
+			fn.WriteString(c)
+		}
+		fn.WriteString("\treturn nil\n}\n")
+		decl += fn.String()
+	}
+
+	if err := g.declare(name, owner, decl); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// adds __typename to a selection set so abstract results can be decoded
+func ensureTypename(field *ast.Field) {
+	if field.SelectionSet == nil {
+		return
+	}
+	for _, sel := range field.SelectionSet.Selections {
+		if f, ok := sel.(*ast.Field); ok && f.Name.Value == "__typename" && f.Alias == nil {
+			return
+		}
+	}
+	typename := ast.NewField(&ast.Field{
+		Name: ast.NewName(&ast.Name{Value: "__typename"}),
+	})
+	field.SelectionSet.Selections = append([]ast.Selection{typename}, field.SelectionSet.Selections...)
+}
+
+func isAbstract(t graphql.Type) bool {
+	switch t.(type) {
+	case *graphql.Interface, *graphql.Union:
+		return true
+	}
+	return false
+}
+
+func (g *goClientGenerator) possibleTypes(t graphql.Composite) []*graphql.Object {
+	switch typ := t.(type) {
+	case *graphql.Object:
+		return []*graphql.Object{typ}
+	case *graphql.Interface:
+		return g.schema.PossibleTypes(typ)
+	case *graphql.Union:
+		return g.schema.PossibleTypes(typ)
+	}
+	return nil
+}
+
+// gets the go type for an output type
+func (g *goClientGenerator) outputType(name, path string, t graphql.Type, field *ast.Field, sets []*ast.SelectionSet, nonNull bool) (string, error) {
+	switch typ := t.(type) {
+	case *graphql.NonNull:
+		return g.outputType(name, path, typ.OfType, field, sets, true)
+	case *graphql.List:
+		elem, err := g.outputType(name, path, typ.OfType, field, sets, false)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + elem, nil
+	case *graphql.Scalar:
+		return nullable(g.scalarType(typ.Name()), nonNull), nil
+	case *graphql.Enum:
+		enum, err := g.enumType(typ)
+		if err != nil {
+			return "", err
+		}
+		return nullable(enum, nonNull), nil
+	case graphql.Composite:
+		if isAbstract(typ) || g.hasConditionalFragments(typ, sets) {
+			ensureTypename(field)
+		}
+		structName, err := g.selectionStruct(name, path, typ, sets)
+		if err != nil {
+			return "", err
+		}
+		return nullable(structName, nonNull), nil
+	}
+	return "", fmt.Errorf("unsupported output type %v", t)
+}
+
+func (g *goClientGenerator) hasConditionalFragments(parent graphql.Composite, sets []*ast.SelectionSet) bool {
+	fields := []*collectedField{}
+	groups := []*conditionalGroup{}
+	if err := g.collect(parent, sets, &fields, &groups); err != nil {
+		return false
+	}
+	return len(groups) > 0
+}
+
+// gets the go type for an input type from the ast
+func (g *goClientGenerator) inputTypeFromAST(t ast.Type) (graphql.Type, error) {
+	switch typ := t.(type) {
+	case *ast.NonNull:
+		inner, err := g.inputTypeFromAST(typ.Type)
+		if err != nil {
+			return nil, err
+		}
+		return graphql.NewNonNull(inner), nil
+	case *ast.List:
+		inner, err := g.inputTypeFromAST(typ.Type)
+		if err != nil {
+			return nil, err
+		}
+		return graphql.NewList(inner), nil
+	case *ast.Named:
+		if named := g.schema.Type(typ.Name.Value); named != nil {
+			return named, nil
+		}
+		return nil, fmt.Errorf("unknown type %q", typ.Name.Value)
+	}
+	return nil, fmt.Errorf("invalid type %v", t)
+}
+
+// generates a field for a variable or input field, nil values of nullable
+// fields are omitted when omitEmpty is set and sent as null otherwise
+func (g *goClientGenerator) inputField(name string, t graphql.Type, omitEmpty bool) (goField, error) {
+	tag := name
+	if _, ok := t.(*graphql.NonNull); !ok && omitEmpty {
+		tag += ",omitempty"
+	}
+	typ, err := g.inputType(t, false)
+	if err != nil {
+		return goField{}, err
+	}
+	return goField{name: exportedName(name), typ: typ, tag: tag}, nil
+}
+
+func (g *goClientGenerator) inputType(t graphql.Type, nonNull bool) (string, error) {
+	switch typ := t.(type) {
+	case *graphql.NonNull:
+		return g.inputType(typ.OfType, true)
+	case *graphql.List:
+		elem, err := g.inputType(typ.OfType, false)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + elem, nil
+	case *graphql.Scalar:
+		return nullable(g.scalarType(typ.Name()), nonNull), nil
+	case *graphql.Enum:
+		enum, err := g.enumType(typ)
+		if err != nil {
+			return "", err
+		}
+		return nullable(enum, nonNull), nil
+	case *graphql.InputObject:
+		name := exportedName(typ.Name())
+		owner := fmt.Sprintf("input %s", typ.Name())
+		if existing, ok := g.declared[name]; ok {
+			if existing != owner {
+				return "", fmt.Errorf("%s and %s both generate the Go name %s", existing, owner, name)
+			}
+			return nullable(name, nonNull), nil
+		}
+
+		// mark as declared before visiting the fields to allow recursive types
+		g.declared[name] = owner
+
+		fieldNames := []string{}
+		for fieldName := range typ.Fields() {
+			fieldNames = append(fieldNames, fieldName)
+		}
+		sort.Strings(fieldNames)
+
+		fields := []goField{}
+		for _, fieldName := range fieldNames {
+			field, err := g.inputField(fieldName, typ.Fields()[fieldName].Type, true)
+			if err != nil {
+				return "", err
+			}
+			fields = append(fields, field)
+		}
+		if err := uniqueFields(owner, fields); err != nil {
+			return "", err
+		}
+		g.decls = append(g.decls, structDecl(fmt.Sprintf("// %s input type", name), name, fields))
+		return nullable(name, nonNull), nil
+	}
+	return "interface{}", nil
+}
+
+func (g *goClientGenerator) scalarType(name string) string {
+	switch name {
+	case "String", "ID":
+		return "string"
+	case "Int":
+		return "int"
+	case "Float":
+		return "float64"
+	case "Boolean":
+		return "bool"
+	}
+
+	goType, ok := g.config.Scalars[name]
+	if !ok {
+		g.addImport("encoding/json")
+		return "json.RawMessage"
+	}
+
+	// qualified types are in the form path/to/pkg.Type
+	if i := strings.LastIndex(goType, "."); i != -1 {
+		pointer := strings.HasPrefix(goType, "*")
+		pkgName := g.addImport(strings.TrimPrefix(goType[:i], "*"))
+		goType = pkgName + goType[i:]
+		if pointer {
+			goType = "*" + goType
+		}
+	}
+	return goType
+}
+
+func (g *goClientGenerator) enumType(enum *graphql.Enum) (string, error) {
+	name := exportedName(enum.Name())
+	owner := fmt.Sprintf("enum %s", enum.Name())
+	if g.declared[name] == owner {
+		return name, nil
+	}
+
+	var decl strings.Builder
+	fmt.Fprintf(&decl, "// %s enum values\ntype %s string\n\nconst (\n", name, name)
+	for _, value := range enum.Values() {
+		fmt.Fprintf(&decl, "\t%s%s %s = %q\n", name, exportedName(strings.ToLower(value.Name)), name, value.Name)
+	}
+	decl.WriteString(")\n")
+	return name, g.declare(name, owner, decl.String())
+}
+
+func fieldDefinition(parent graphql.Composite, name string) *graphql.FieldDefinition {
+	switch t := parent.(type) {
+	case *graphql.Object:
+		return t.Fields()[name]
+	case *graphql.Interface:
+		return t.Fields()[name]
+	}
+	return nil
+}
+
+func structDecl(comment, name string, fields []goField) string {
+	var decl strings.Builder
+	fmt.Fprintf(&decl, "%s\ntype %s struct {\n", comment, name)
+	for _, f := range fields {
+		fmt.Fprintf(&decl, "\t%s %s `json:%q`\n", f.name, f.typ, f.tag)
+	}
+	decl.WriteString("}\n")
+	return decl.String()
+}
+
+// pointers are used for nullable values that have no natural zero value
+func nullable(typ string, nonNull bool) string {
+	if nonNull || strings.HasPrefix(typ, "*") || strings.HasPrefix(typ, "[]") ||
+		typ == "json.RawMessage" || typ == "interface{}" {
+		return typ
+	}
+	return "*" + typ
+}
+
+func typenameField(key string) string {
+	if key == "__typename" {
+		return "Typename"
+	}
+	return exportedName(key)
+}
+
+// converts a graphql name to an exported go identifier
+func exportedName(name string) string {
+	parts := strings.FieldsFunc(name, func(r rune) bool { return r == '_' })
+	for i, part := range parts {
+		switch upper := strings.ToUpper(part); upper {
+		case "ID", "URL", "JSON", "HTTP", "API":
+			parts[i] = upper
+		default:
+			parts[i] = strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+func lowerFirst(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+func quote(s string) string {
+	if strings.Contains(s, "`") {
+		return strconv.Quote(s)
+	}
+	return "`" + s + "`"
+}
@@ -0,0 +1,196 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateGoClient(t *testing.T) {
+	schema, err := LoadSchema(`
+scalar DateTime
+
+enum Status {
+	ACTIVE
+	IN_PROGRESS
+}
+
+interface Node {
+	id: ID!
+}
+
+type User implements Node {
+	id: ID!
+	name: String
+	created: DateTime
+	status: Status!
+}
+
+type Post implements Node {
+	id: ID!
+	title: String!
+}
+
+union SearchResult = User | Post
+
+type Query {
+	user(id: ID!): User
+	search(q: String!): [SearchResult!]!
+}`)
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+
+	document, err := ParseOperations(`
+query GetUser($id: ID!) {
+	user(id: $id) {
+		...UserFields
+	}
+}
+
+fragment UserFields on User {
+	id
+	name
+	created
+	status
+}
+
+query Search($q: String!) {
+	search(q: $q) {
+		... on User {
+			name
+		}
+		... on Post {
+			title
+		}
+	}
+}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	source, err := GenerateGoClient(schema, document, GoClientConfig{
+		Package: "users",
+		Scalars: map[string]string{"DateTime": "time.Time"},
+	})
+	if err != nil {
+		t.Fatalf("failed to generate client: %v", err)
+	}
+
+	code := string(source)
+	for _, expected := range []string{
+		"package users",
+		"func GetUser(ctx context.Context, c client.Client, vars GetUserVariables) (*GetUserResult, error)",
+		"Created *time.Time",
+		"StatusInProgress Status = \"IN_PROGRESS\"",
+		"OnUser   *SearchResultSearchOnUser `json:\"-\"`",
+		"fragment UserFields on User",
+		"func (v *SearchResultSearch) UnmarshalJSON(data []byte) error",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Errorf("expected generated code to contain %q\n%s", expected, code)
+		}
+	}
+
+	// __typename is required to decode the union
+	if !strings.Contains(code, "search(q: $q) {\n    __typename") {
+		t.Errorf("expected __typename to be added to the search selection\n%s", code)
+	}
+}
+
+func TestGenerateGoClientAnonymousOperation(t *testing.T) {
+	schema, err := LoadSchema(`type Query { foo: String }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	document, err := ParseOperations(`{ foo }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := GenerateGoClient(schema, document, GoClientConfig{}); err == nil {
+		t.Error("expected an error for an anonymous operation")
+	}
+}
+
+func TestGenerateGoClientVariables(t *testing.T) {
+	schema, err := LoadSchema(`
+input ProfileInput {
+	bio: String
+}
+
+type Mutation {
+	updateUser(id: ID!, name: String, limit: Int, profile: ProfileInput): Boolean
+}
+
+type Query {
+	foo: String
+}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	document, err := ParseOperations(`
+mutation UpdateUser($id: ID!, $name: String, $limit: Int = 10, $profile: ProfileInput) {
+	updateUser(id: $id, name: $name, limit: $limit, profile: $profile)
+}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	source, err := GenerateGoClient(schema, document, GoClientConfig{})
+	if err != nil {
+		t.Fatalf("failed to generate client: %v", err)
+	}
+
+	// nil nullable variables are sent as null unless a default value applies
+	code := string(source)
+	for _, expected := range []string{
+		"ID      string        `json:\"id\"`",
+		"Name    *string       `json:\"name\"`",
+		"Limit   *int          `json:\"limit,omitempty\"`",
+		"Profile *ProfileInput `json:\"profile\"`",
+		"Bio *string `json:\"bio,omitempty\"`",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Errorf("expected generated code to contain %q\n%s", expected, code)
+		}
+	}
+}
+
+func TestGenerateGoClientNameClash(t *testing.T) {
+	schema, err := LoadSchema(`
+type User {
+	name: String
+	address: Address
+}
+
+type Address {
+	city: String
+}
+
+type Query {
+	user: User
+}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name       string
+		operations string
+		err        string
+	}{
+		{"fields", `query GetUser { user { a_b: name aB: name } }`, `"a_b" and "aB" both generate the Go field AB`},
+		{"selections", `query GetUser { user { home_address: address { city } homeAddress: address { city } } }`, "selection GetUser.user.home_address and selection GetUser.user.homeAddress both generate the Go name GetUserResultUserHomeAddress"},
+		{"operations", "query get_user { user { name } }\nquery getUser { user { name } }", "selection get_user.user and selection getUser.user both generate the Go name GetUserResultUser"},
+	} {
+		document, err := ParseOperations(tt.operations)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := GenerateGoClient(schema, document, GoClientConfig{}); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: expected an error containing %q, got %v", tt.name, tt.err, err)
+		}
+	}
+}
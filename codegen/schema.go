@@ -0,0 +1,80 @@
+package codegen
+
+import (
+	"fmt"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/kinds"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	tools "github.com/rohit20001221/graphql-go-tools"
+)
+
+// LoadSchema builds a schema from type definitions for code generation.
+// No resolvers are required, custom scalars and abstract types are bound to
+// placeholder functions and directives get placeholder runtime dependencies
+// since only the shape of the schema is used
+func LoadSchema(typeDefs string) (graphql.Schema, error) {
+	config := tools.ExecutableSchema{
+		TypeDefs:      typeDefs,
+		Resolvers:     tools.ResolverMap{},
+		PubSub:        tools.NewPubSub(),
+		Jobs:          tools.NewJobManager(),
+		ErrorMatchers: map[string]tools.ErrorMatcher{},
+	}
+
+	document, err := config.ConcatenateTypeDefs()
+	if err != nil {
+		return graphql.Schema{}, err
+	}
+
+	for _, def := range document.Definitions {
+		switch def.GetKind() {
+		case kinds.ObjectDefinition:
+			config.ErrorMatchers[def.(*ast.ObjectDefinition).Name.Value] = unmatchedError
+		case kinds.ScalarDefinition:
+			config.Resolvers[def.(*ast.ScalarDefinition).Name.Value] = &tools.ScalarResolver{
+				Serialize:    passthrough,
+				ParseValue:   passthrough,
+				ParseLiteral: func(value ast.Value) interface{} { return value.GetValue() },
+			}
+		case kinds.InterfaceDefinition:
+			config.Resolvers[def.(*ast.InterfaceDefinition).Name.Value] = &tools.InterfaceResolver{
+				ResolveType: unresolvedType,
+			}
+		case kinds.UnionDefinition:
+			config.Resolvers[def.(*ast.UnionDefinition).Name.Value] = &tools.UnionResolver{
+				ResolveType: unresolvedType,
+			}
+		}
+	}
+
+	return tools.MakeExecutableSchema(config)
+}
+
+// ParseOperations parses a document containing operations and fragments
+func ParseOperations(operations string) (*ast.Document, error) {
+	document, err := parser.Parse(parser.ParseParams{
+		Source: &source.Source{
+			Body: []byte(operations),
+			Name: "GraphQL",
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse operations: %v", err)
+	}
+	return document, nil
+}
+
+func passthrough(value interface{}) interface{} {
+	return value
+}
+
+func unmatchedError(err error) (interface{}, bool) {
+	return nil, false
+}
+
+func unresolvedType(p graphql.ResolveTypeParams) *graphql.Object {
+	return nil
+}
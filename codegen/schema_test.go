@@ -0,0 +1,47 @@
+package codegen
+
+import "testing"
+
+func TestLoadSchemaAsyncJob(t *testing.T) {
+	schema, err := LoadSchema(`
+type Query {
+	version: String
+}
+
+type Mutation {
+	generateReport(name: String!): String @asyncJob
+}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if schema.Type("Job") == nil {
+		t.Error("expected the Job type to be generated")
+	}
+}
+
+func TestLoadSchemaErrorsAsData(t *testing.T) {
+	schema, err := LoadSchema(`
+interface Error {
+	message: String!
+}
+
+type NotFound implements Error {
+	message: String!
+}
+
+type User {
+	id: ID!
+}
+
+type Query {
+	user(id: ID!): User @errorsAsData(types: ["NotFound"])
+}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if schema.Type("UserResult") == nil {
+		t.Error("expected the UserResult union to be generated")
+	}
+}
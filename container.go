@@ -0,0 +1,280 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Lifetime determines how long a provided service lives
+type Lifetime int
+
+// service lifetimes
+const (
+	// Singleton services are created once per container
+	Singleton Lifetime = iota
+	// Scoped services are created once per request or websocket operation
+	Scoped
+)
+
+type scopeKey struct{}
+
+type resolvingKey struct{}
+
+// ServiceProvider creates a service, the context contains the current
+// scope so providers can resolve their own dependencies with Get. Singleton
+// providers receive a container context without the values of the request
+type ServiceProvider func(ctx context.Context) (interface{}, error)
+
+// ServiceCleanup releases a service when its lifetime ends
+type ServiceCleanup func(service interface{}) error
+
+type serviceRegistration struct {
+	lifetime Lifetime
+	provider ServiceProvider
+	cleanup  ServiceCleanup
+}
+
+// a lazily created service instance, the lock is held while the provider
+// runs. Errors are not stored so a failed provider is retried
+type serviceInstance struct {
+	mx      sync.Mutex
+	created bool
+	value   interface{}
+}
+
+// Container holds service providers and singleton instances.
+// Services are registered with Provide and resolved with Get
+// from the context of a scope created by NewScope
+type Container struct {
+	mx         sync.Mutex
+	services   map[reflect.Type]*serviceRegistration
+	singletons *serviceSet
+	root       *Scope
+}
+
+// NewContainer creates a new service container
+func NewContainer() *Container {
+	c := &Container{
+		services:   map[reflect.Type]*serviceRegistration{},
+		singletons: newServiceSet(),
+	}
+	c.root = &Scope{container: c, services: c.singletons, root: true}
+	return c
+}
+
+// Register registers a provider for a service type with an optional cleanup function
+func (c *Container) Register(t reflect.Type, lifetime Lifetime, provider ServiceProvider, cleanup ServiceCleanup) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	c.services[t] = &serviceRegistration{
+		lifetime: lifetime,
+		provider: provider,
+		cleanup:  cleanup,
+	}
+}
+
+// Provide registers a typed provider for T with an optional cleanup function
+func Provide[T any](c *Container, lifetime Lifetime, provider func(ctx context.Context) (T, error), cleanup ...func(T) error) {
+	var cleanupFn ServiceCleanup
+	if len(cleanup) > 0 && cleanup[0] != nil {
+		cleanupFn = func(service interface{}) error {
+			return cleanup[0](service.(T))
+		}
+	}
+
+	c.Register(typeOf[T](), lifetime, func(ctx context.Context) (interface{}, error) {
+		return provider(ctx)
+	}, cleanupFn)
+}
+
+// NewScope creates a new scope for scoped services and returns a context
+// containing it. The scope must be closed when the request or operation ends
+func (c *Container) NewScope(ctx context.Context) (context.Context, *Scope) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	scope := &Scope{
+		container: c,
+		services:  newServiceSet(),
+	}
+	return context.WithValue(ctx, scopeKey{}, scope), scope
+}
+
+// Close runs the cleanup functions of all singleton services
+func (c *Container) Close() error {
+	return c.singletons.close()
+}
+
+func (c *Container) registration(t reflect.Type) (*serviceRegistration, bool) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	reg, ok := c.services[t]
+	return reg, ok
+}
+
+// Scope holds the scoped service instances of a request or websocket operation
+type Scope struct {
+	container *Container
+	services  *serviceSet
+	root      bool // the scope of singleton providers
+}
+
+// Close runs the cleanup functions of all scoped services in reverse creation order
+func (s *Scope) Close() error {
+	return s.services.close()
+}
+
+// Get resolves a service from the scope, creating it if required
+func (s *Scope) Get(ctx context.Context, t reflect.Type) (interface{}, error) {
+	reg, ok := s.container.registration(t)
+	if !ok {
+		return nil, fmt.Errorf("no provider registered for service %s", t)
+	}
+
+	switch reg.lifetime {
+	case Singleton:
+		// singletons outlive the request so they are created with the
+		// container scope instead of the request context
+		singletonCtx := context.WithValue(context.Background(), scopeKey{}, s.container.root)
+		if resolving, ok := ctx.Value(resolvingKey{}).([]reflect.Type); ok {
+			singletonCtx = context.WithValue(singletonCtx, resolvingKey{}, resolving)
+		}
+		return s.container.singletons.get(singletonCtx, t, reg)
+	case Scoped:
+		if s.root {
+			return nil, fmt.Errorf("scoped service %s cannot be resolved by a singleton", t)
+		}
+		return s.services.get(ctx, t, reg)
+	}
+	return nil, fmt.Errorf("invalid lifetime %d for service %s", reg.lifetime, t)
+}
+
+// GetScope gets the service scope from the context
+func GetScope(ctx context.Context) (*Scope, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	scope, ok := ctx.Value(scopeKey{}).(*Scope)
+	return scope, ok
+}
+
+// Get resolves a service of type T from the scope in the context
+func Get[T any](ctx context.Context) (T, error) {
+	var zero T
+	scope, ok := GetScope(ctx)
+	if !ok {
+		return zero, errors.New("no service scope found in context")
+	}
+
+	service, err := scope.Get(ctx, typeOf[T]())
+	if err != nil {
+		return zero, err
+	}
+	return service.(T), nil
+}
+
+// MustGet resolves a service of type T and panics if it cannot be resolved
+func MustGet[T any](ctx context.Context) T {
+	service, err := Get[T](ctx)
+	if err != nil {
+		panic(err)
+	}
+	return service
+}
+
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// a set of created service instances and their cleanup functions
+type serviceSet struct {
+	mx        sync.Mutex
+	instances map[reflect.Type]*serviceInstance
+	cleanups  []func() error
+	closed    bool
+}
+
+func newServiceSet() *serviceSet {
+	return &serviceSet{
+		instances: map[reflect.Type]*serviceInstance{},
+	}
+}
+
+// gets or creates an instance, the set lock is not held while the provider
+// runs so providers can resolve other services from the same set. The types
+// being resolved are kept in the context to report cycles
+func (s *serviceSet) get(ctx context.Context, t reflect.Type, reg *serviceRegistration) (interface{}, error) {
+	resolving, _ := ctx.Value(resolvingKey{}).([]reflect.Type)
+	for i, r := range resolving {
+		if r == t {
+			return nil, fmt.Errorf("service cycle detected: %s", serviceCycle(append(resolving[i:], t)))
+		}
+	}
+
+	s.mx.Lock()
+	if s.closed {
+		s.mx.Unlock()
+		return nil, fmt.Errorf("service %s requested from a closed scope", t)
+	}
+	instance, ok := s.instances[t]
+	if !ok {
+		instance = &serviceInstance{}
+		s.instances[t] = instance
+	}
+	s.mx.Unlock()
+
+	instance.mx.Lock()
+	defer instance.mx.Unlock()
+	if instance.created {
+		return instance.value, nil
+	}
+
+	chain := append(append([]reflect.Type{}, resolving...), t)
+	value, err := reg.provider(context.WithValue(ctx, resolvingKey{}, chain))
+	if err != nil {
+		return nil, err
+	}
+	instance.value, instance.created = value, true
+
+	if reg.cleanup != nil {
+		s.mx.Lock()
+		s.cleanups = append(s.cleanups, func() error {
+			return reg.cleanup(value)
+		})
+		s.mx.Unlock()
+	}
+
+	return value, nil
+}
+
+// formats the types of a service cycle
+func serviceCycle(types []reflect.Type) string {
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = t.String()
+	}
+	return strings.Join(names, " -> ")
+}
+
+func (s *serviceSet) close() error {
+	s.mx.Lock()
+	cleanups := s.cleanups
+	s.cleanups = nil
+	s.closed = true
+	s.mx.Unlock()
+
+	errs := []error{}
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		if err := cleanups[i](); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
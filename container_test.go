@@ -0,0 +1,191 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/graphql-go/graphql"
+)
+
+type testDB struct {
+	name string
+}
+
+type testLoader struct {
+	db     *testDB
+	closed bool
+}
+
+func TestContainer(t *testing.T) {
+	container := NewContainer()
+	dbCount := 0
+	loaders := []*testLoader{}
+
+	Provide(container, Singleton, func(ctx context.Context) (*testDB, error) {
+		dbCount++
+		return &testDB{name: "db"}, nil
+	})
+	Provide(container, Scoped, func(ctx context.Context) (*testLoader, error) {
+		db, err := Get[*testDB](ctx)
+		if err != nil {
+			return nil, err
+		}
+		loader := &testLoader{db: db}
+		loaders = append(loaders, loader)
+		return loader, nil
+	}, func(loader *testLoader) error {
+		loader.closed = true
+		return nil
+	})
+
+	schema, err := MakeExecutableSchema(ExecutableSchema{
+		TypeDefs: `type Query { db: String }`,
+		Resolvers: ResolverMap{
+			"Query": &ObjectResolver{
+				Fields: FieldResolveMap{
+					"db": &FieldResolve{
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							loader := MustGet[*testLoader](p.Context)
+							if again := MustGet[*testLoader](p.Context); again != loader {
+								t.Error("expected the same scoped loader within a request")
+							}
+							return loader.db.name, nil
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		ctx, scope := container.NewScope(context.Background())
+		r := graphql.Do(graphql.Params{
+			Schema:        schema,
+			RequestString: `{ db }`,
+			Context:       ctx,
+		})
+		if r.HasErrors() {
+			t.Fatal(r.Errors)
+		}
+		if err := scope.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if dbCount != 1 {
+		t.Errorf("expected singleton to be created once, created %d times", dbCount)
+	}
+
+	if len(loaders) != 2 {
+		t.Fatalf("expected a loader per scope, got %d", len(loaders))
+	}
+
+	for _, loader := range loaders {
+		if !loader.closed {
+			t.Error("expected loader to be cleaned up when the scope closed")
+		}
+	}
+}
+
+func TestContainerMissingScope(t *testing.T) {
+	if _, err := Get[*testDB](context.Background()); err == nil {
+		t.Error("expected an error without a scope")
+	}
+
+	ctx, _ := NewContainer().NewScope(context.Background())
+	if _, err := Get[*testDB](ctx); err == nil {
+		t.Error("expected an error for an unregistered service")
+	}
+}
+
+type requestKey struct{}
+
+type testCache struct{}
+
+func TestContainerSingletonErrors(t *testing.T) {
+	container := NewContainer()
+	attempts := 0
+	Provide(container, Singleton, func(ctx context.Context) (*testDB, error) {
+		attempts++
+		if ctx.Value(requestKey{}) != nil {
+			t.Error("expected singletons to be created without the request context")
+		}
+		if attempts == 1 {
+			return nil, errors.New("dial failed")
+		}
+		return &testDB{name: "db"}, nil
+	})
+
+	ctx, scope := container.NewScope(context.WithValue(context.Background(), requestKey{}, "request"))
+	defer scope.Close()
+
+	if _, err := Get[*testDB](ctx); err == nil {
+		t.Fatal("expected the first attempt to fail")
+	}
+	if db, err := Get[*testDB](ctx); err != nil || db.name != "db" {
+		t.Errorf("expected a failed singleton to be retried, got %v", err)
+	}
+
+	// singletons cannot capture scoped services
+	Provide(container, Scoped, func(ctx context.Context) (*testLoader, error) {
+		return &testLoader{}, nil
+	})
+	Provide(container, Singleton, func(ctx context.Context) (*testCache, error) {
+		if _, err := Get[*testLoader](ctx); err != nil {
+			return nil, err
+		}
+		return &testCache{}, nil
+	})
+	if _, err := Get[*testCache](ctx); err == nil {
+		t.Error("expected a singleton resolving a scoped service to fail")
+	}
+}
+
+func TestContainerCycle(t *testing.T) {
+	container := NewContainer()
+	Provide(container, Scoped, func(ctx context.Context) (*testDB, error) {
+		if _, err := Get[*testLoader](ctx); err != nil {
+			return nil, err
+		}
+		return &testDB{}, nil
+	})
+	Provide(container, Scoped, func(ctx context.Context) (*testLoader, error) {
+		if _, err := Get[*testDB](ctx); err != nil {
+			return nil, err
+		}
+		return &testLoader{}, nil
+	})
+	Provide(container, Singleton, func(ctx context.Context) (*testCache, error) {
+		return Get[*testCache](ctx)
+	})
+
+	ctx, scope := container.NewScope(context.Background())
+	defer scope.Close()
+
+	done := make(chan error, 2)
+	go func() {
+		_, err := Get[*testDB](ctx)
+		done <- err
+	}()
+	go func() {
+		_, err := Get[*testCache](ctx)
+		done <- err
+	}()
+
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-done:
+			if err == nil || !strings.Contains(err.Error(), "service cycle detected") {
+				t.Errorf("expected a cycle error, got %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timed out resolving a service cycle")
+		}
+	}
+}
@@ -37,20 +37,32 @@ type SchemaDirectiveVisitor struct {
 	VisitInputFieldDefinition func(p VisitInputFieldDefinitionParams) error
 }
 
+// TypeRegistry provides directive visitors access to the types being built.
+// Types that are defined but not yet built return an unresolved dependencies
+// error, visitors can return it to retry once the type has been built
+type TypeRegistry interface {
+	GetType(name string) (graphql.Type, error)
+	GetDirective(name string) (*graphql.Directive, error)
+	AddType(t graphql.Type) error
+	AddDirective(directive *graphql.Directive) error
+}
+
 // VisitSchemaParams params
 type VisitSchemaParams struct {
-	Context context.Context
-	Config  *graphql.SchemaConfig
-	Node    *ast.SchemaDefinition
-	Args    map[string]interface{}
+	Context  context.Context
+	Config   *graphql.SchemaConfig
+	Node     *ast.SchemaDefinition
+	Args     map[string]interface{}
+	Registry TypeRegistry
 }
 
 // VisitScalarParams params
 type VisitScalarParams struct {
-	Context context.Context
-	Config  *graphql.ScalarConfig
-	Node    *ast.ScalarDefinition
-	Args    map[string]interface{}
+	Context  context.Context
+	Config   *graphql.ScalarConfig
+	Node     *ast.ScalarDefinition
+	Args     map[string]interface{}
+	Registry TypeRegistry
 }
 
 // VisitObjectParams params
@@ -60,6 +72,7 @@ type VisitObjectParams struct {
 	Node       *ast.ObjectDefinition
 	Extensions []*ast.ObjectDefinition
 	Args       map[string]interface{}
+	Registry   TypeRegistry
 }
 
 // VisitFieldDefinitionParams params
@@ -70,62 +83,77 @@ type VisitFieldDefinitionParams struct {
 	Args       map[string]interface{}
 	ParentName string
 	ParentKind string
+	Registry   TypeRegistry
 }
 
 // VisitArgumentDefinitionParams params
 type VisitArgumentDefinitionParams struct {
-	Context context.Context
-	Config  *graphql.ArgumentConfig
-	Node    *ast.InputValueDefinition
-	Args    map[string]interface{}
+	Context    context.Context
+	Config     *graphql.ArgumentConfig
+	Node       *ast.InputValueDefinition
+	Args       map[string]interface{}
+	FieldName  string // empty for directive arguments
+	ParentName string // the type or directive name
+	ParentKind string
+	Registry   TypeRegistry
 }
 
 // VisitInterfaceParams params
 type VisitInterfaceParams struct {
-	Context context.Context
-	Config  *graphql.InterfaceConfig
-	Node    *ast.InterfaceDefinition
-	Args    map[string]interface{}
+	Context  context.Context
+	Config   *graphql.InterfaceConfig
+	Node     *ast.InterfaceDefinition
+	Args     map[string]interface{}
+	Registry TypeRegistry
 }
 
 // VisitUnionParams params
 type VisitUnionParams struct {
-	Context context.Context
-	Config  *graphql.UnionConfig
-	Node    *ast.UnionDefinition
-	Args    map[string]interface{}
+	Context  context.Context
+	Config   *graphql.UnionConfig
+	Node     *ast.UnionDefinition
+	Args     map[string]interface{}
+	Registry TypeRegistry
 }
 
 // VisitEnumParams params
 type VisitEnumParams struct {
-	Context context.Context
-	Config  *graphql.EnumConfig
-	Node    *ast.EnumDefinition
-	Args    map[string]interface{}
+	Context  context.Context
+	Config   *graphql.EnumConfig
+	Node     *ast.EnumDefinition
+	Args     map[string]interface{}
+	Registry TypeRegistry
 }
 
 // VisitEnumValueParams params
 type VisitEnumValueParams struct {
-	Context context.Context
-	Config  *graphql.EnumValueConfig
-	Node    *ast.EnumValueDefinition
-	Args    map[string]interface{}
+	Context    context.Context
+	Config     *graphql.EnumValueConfig
+	Node       *ast.EnumValueDefinition
+	Args       map[string]interface{}
+	ParentName string
+	ParentKind string
+	Registry   TypeRegistry
 }
 
 // VisitInputObjectParams params
 type VisitInputObjectParams struct {
-	Context context.Context
-	Config  *graphql.InputObjectConfig
-	Node    *ast.InputObjectDefinition
-	Args    map[string]interface{}
+	Context  context.Context
+	Config   *graphql.InputObjectConfig
+	Node     *ast.InputObjectDefinition
+	Args     map[string]interface{}
+	Registry TypeRegistry
 }
 
 // VisitInputFieldDefinitionParams params
 type VisitInputFieldDefinitionParams struct {
-	Context context.Context
-	Config  *graphql.InputObjectFieldConfig
-	Node    *ast.InputValueDefinition
-	Args    map[string]interface{}
+	Context    context.Context
+	Config     *graphql.InputObjectFieldConfig
+	Node       *ast.InputValueDefinition
+	Args       map[string]interface{}
+	ParentName string
+	ParentKind string
+	Registry   TypeRegistry
 }
 
 // SchemaDirectiveVisitorMap a map of schema directive visitors
@@ -154,7 +182,7 @@ func (c *registry) buildDirectiveFromAST(definition *ast.DirectiveDefinition) er
 	}
 
 	for _, arg := range definition.Arguments {
-		if argValue, err := c.buildArgFromAST(arg); err == nil {
+		if argValue, err := c.buildArgFromAST(arg, definition.GetKind(), name, ""); err == nil {
 			directiveConfig.Args[arg.Name.Value] = argValue
 		} else {
 			return err
@@ -174,6 +202,7 @@ type applyDirectiveParams struct {
 	directives []*ast.Directive
 	node       interface{}
 	extensions []*ast.ObjectDefinition
+	fieldName  string
 	parentName string
 	parentKind string
 }
@@ -205,10 +234,11 @@ func (c *registry) applyDirectives(p applyDirectiveParams) error {
 		case *graphql.SchemaConfig:
 			if visitor.VisitSchema != nil {
 				if err := visitor.VisitSchema(VisitSchemaParams{
-					Context: c.ctx,
-					Config:  p.config.(*graphql.SchemaConfig),
-					Args:    args,
-					Node:    p.node.(*ast.SchemaDefinition),
+					Context:  c.ctx,
+					Registry: c,
+					Config:   p.config.(*graphql.SchemaConfig),
+					Args:     args,
+					Node:     p.node.(*ast.SchemaDefinition),
 				}); err != nil {
 					return err
 				}
@@ -216,10 +246,11 @@ func (c *registry) applyDirectives(p applyDirectiveParams) error {
 		case *graphql.ScalarConfig:
 			if visitor.VisitScalar != nil {
 				if err := visitor.VisitScalar(VisitScalarParams{
-					Context: c.ctx,
-					Config:  p.config.(*graphql.ScalarConfig),
-					Args:    args,
-					Node:    p.node.(*ast.ScalarDefinition),
+					Context:  c.ctx,
+					Registry: c,
+					Config:   p.config.(*graphql.ScalarConfig),
+					Args:     args,
+					Node:     p.node.(*ast.ScalarDefinition),
 				}); err != nil {
 					return err
 				}
@@ -228,6 +259,7 @@ func (c *registry) applyDirectives(p applyDirectiveParams) error {
 			if visitor.VisitObject != nil {
 				if err := visitor.VisitObject(VisitObjectParams{
 					Context:    c.ctx,
+					Registry:   c,
 					Config:     p.config.(*graphql.ObjectConfig),
 					Args:       args,
 					Node:       p.node.(*ast.ObjectDefinition),
@@ -240,6 +272,7 @@ func (c *registry) applyDirectives(p applyDirectiveParams) error {
 			if visitor.VisitFieldDefinition != nil {
 				if err := visitor.VisitFieldDefinition(VisitFieldDefinitionParams{
 					Context:    c.ctx,
+					Registry:   c,
 					Config:     p.config.(*graphql.Field),
 					Args:       args,
 					Node:       p.node.(*ast.FieldDefinition),
@@ -252,10 +285,14 @@ func (c *registry) applyDirectives(p applyDirectiveParams) error {
 		case *graphql.ArgumentConfig:
 			if visitor.VisitArgumentDefinition != nil {
 				if err := visitor.VisitArgumentDefinition(VisitArgumentDefinitionParams{
-					Context: c.ctx,
-					Config:  p.config.(*graphql.ArgumentConfig),
-					Args:    args,
-					Node:    p.node.(*ast.InputValueDefinition),
+					Context:    c.ctx,
+					Registry:   c,
+					Config:     p.config.(*graphql.ArgumentConfig),
+					Args:       args,
+					Node:       p.node.(*ast.InputValueDefinition),
+					FieldName:  p.fieldName,
+					ParentName: p.parentName,
+					ParentKind: p.parentKind,
 				}); err != nil {
 					return err
 				}
@@ -263,10 +300,11 @@ func (c *registry) applyDirectives(p applyDirectiveParams) error {
 		case *graphql.InterfaceConfig:
 			if visitor.VisitInterface != nil {
 				if err := visitor.VisitInterface(VisitInterfaceParams{
-					Context: c.ctx,
-					Config:  p.config.(*graphql.InterfaceConfig),
-					Args:    args,
-					Node:    p.node.(*ast.InterfaceDefinition),
+					Context:  c.ctx,
+					Registry: c,
+					Config:   p.config.(*graphql.InterfaceConfig),
+					Args:     args,
+					Node:     p.node.(*ast.InterfaceDefinition),
 				}); err != nil {
 					return err
 				}
@@ -274,10 +312,11 @@ func (c *registry) applyDirectives(p applyDirectiveParams) error {
 		case *graphql.UnionConfig:
 			if visitor.VisitUnion != nil {
 				if err := visitor.VisitUnion(VisitUnionParams{
-					Context: c.ctx,
-					Config:  p.config.(*graphql.UnionConfig),
-					Args:    args,
-					Node:    p.node.(*ast.UnionDefinition),
+					Context:  c.ctx,
+					Registry: c,
+					Config:   p.config.(*graphql.UnionConfig),
+					Args:     args,
+					Node:     p.node.(*ast.UnionDefinition),
 				}); err != nil {
 					return err
 				}
@@ -285,10 +324,11 @@ func (c *registry) applyDirectives(p applyDirectiveParams) error {
 		case *graphql.EnumConfig:
 			if visitor.VisitEnum != nil {
 				if err := visitor.VisitEnum(VisitEnumParams{
-					Context: c.ctx,
-					Config:  p.config.(*graphql.EnumConfig),
-					Args:    args,
-					Node:    p.node.(*ast.EnumDefinition),
+					Context:  c.ctx,
+					Registry: c,
+					Config:   p.config.(*graphql.EnumConfig),
+					Args:     args,
+					Node:     p.node.(*ast.EnumDefinition),
 				}); err != nil {
 					return err
 				}
@@ -296,10 +336,13 @@ func (c *registry) applyDirectives(p applyDirectiveParams) error {
 		case *graphql.EnumValueConfig:
 			if visitor.VisitEnumValue != nil {
 				if err := visitor.VisitEnumValue(VisitEnumValueParams{
-					Context: c.ctx,
-					Config:  p.config.(*graphql.EnumValueConfig),
-					Args:    args,
-					Node:    p.node.(*ast.EnumValueDefinition),
+					Context:    c.ctx,
+					Registry:   c,
+					Config:     p.config.(*graphql.EnumValueConfig),
+					Args:       args,
+					Node:       p.node.(*ast.EnumValueDefinition),
+					ParentName: p.parentName,
+					ParentKind: p.parentKind,
 				}); err != nil {
 					return err
 				}
@@ -307,10 +350,11 @@ func (c *registry) applyDirectives(p applyDirectiveParams) error {
 		case *graphql.InputObjectConfig:
 			if visitor.VisitInputObject != nil {
 				if err := visitor.VisitInputObject(VisitInputObjectParams{
-					Context: c.ctx,
-					Config:  p.config.(*graphql.InputObjectConfig),
-					Args:    args,
-					Node:    p.node.(*ast.InputObjectDefinition),
+					Context:  c.ctx,
+					Registry: c,
+					Config:   p.config.(*graphql.InputObjectConfig),
+					Args:     args,
+					Node:     p.node.(*ast.InputObjectDefinition),
 				}); err != nil {
 					return err
 				}
@@ -318,10 +362,13 @@ func (c *registry) applyDirectives(p applyDirectiveParams) error {
 		case *graphql.InputObjectFieldConfig:
 			if visitor.VisitInputFieldDefinition != nil {
 				if err := visitor.VisitInputFieldDefinition(VisitInputFieldDefinitionParams{
-					Context: c.ctx,
-					Config:  p.config.(*graphql.InputObjectFieldConfig),
-					Args:    args,
-					Node:    p.node.(*ast.InputValueDefinition),
+					Context:    c.ctx,
+					Registry:   c,
+					Config:     p.config.(*graphql.InputObjectFieldConfig),
+					Args:       args,
+					Node:       p.node.(*ast.InputValueDefinition),
+					ParentName: p.parentName,
+					ParentKind: p.parentKind,
 				}); err != nil {
 					return err
 				}
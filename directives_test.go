@@ -95,3 +95,133 @@ type Query {
 		return
 	}
 }
+
+func TestDirectiveVisitorRegistry(t *testing.T) {
+	typeDefs := `
+directive @connection on FIELD_DEFINITION
+directive @trim on ARGUMENT_DEFINITION | INPUT_FIELD_DEFINITION
+
+schema {
+	query: Query
+}
+
+type Query {
+	foos(filter: String @trim): [Foo] @connection
+	filter(input: FooFilter): String
+}
+
+type Foo {
+	name: String
+}
+
+input FooFilter {
+	name: String @trim
+}
+`
+
+	parents := []string{}
+	schema, err := MakeExecutableSchema(ExecutableSchema{
+		TypeDefs: typeDefs,
+		Resolvers: ResolverMap{
+			"Query": &ObjectResolver{
+				Fields: FieldResolveMap{
+					"foos": &FieldResolve{
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							return []interface{}{
+								map[string]interface{}{"name": "foo"},
+								map[string]interface{}{"name": "bar"},
+							}, nil
+						},
+					},
+				},
+			},
+		},
+		SchemaDirectives: SchemaDirectiveVisitorMap{
+			"connection": &SchemaDirectiveVisitor{
+				VisitFieldDefinition: func(p VisitFieldDefinitionParams) error {
+					node, err := p.Registry.GetType("Foo")
+					if err != nil {
+						return err
+					}
+
+					edge := graphql.NewObject(graphql.ObjectConfig{
+						Name: node.Name() + "Edge",
+						Fields: graphql.Fields{
+							"node": &graphql.Field{Type: node},
+						},
+					})
+					connection := graphql.NewObject(graphql.ObjectConfig{
+						Name: node.Name() + "Connection",
+						Fields: graphql.Fields{
+							"edges": &graphql.Field{Type: graphql.NewList(edge)},
+						},
+					})
+
+					if err := p.Registry.AddType(edge); err != nil {
+						return err
+					}
+					if err := p.Registry.AddType(connection); err != nil {
+						return err
+					}
+
+					resolve := p.Config.Resolve
+					p.Config.Type = connection
+					p.Config.Resolve = func(rp graphql.ResolveParams) (interface{}, error) {
+						result, err := resolve(rp)
+						if err != nil {
+							return nil, err
+						}
+						edges := []interface{}{}
+						for _, item := range result.([]interface{}) {
+							edges = append(edges, map[string]interface{}{"node": item})
+						}
+						return map[string]interface{}{"edges": edges}, nil
+					}
+					return nil
+				},
+			},
+			"trim": &SchemaDirectiveVisitor{
+				VisitArgumentDefinition: func(p VisitArgumentDefinitionParams) error {
+					parents = append(parents, p.ParentName+"."+p.FieldName+"."+p.Node.Name.Value)
+					return nil
+				},
+				VisitInputFieldDefinition: func(p VisitInputFieldDefinitionParams) error {
+					parents = append(parents, p.ParentName+"."+p.Node.Name.Value)
+					return nil
+				},
+			},
+		},
+	})
+
+	if err != nil {
+		t.Fatalf("failed to make schema: %v", err)
+	}
+
+	for _, expected := range []string{"Query.foos.filter", "FooFilter.name"} {
+		found := false
+		for _, parent := range parents {
+			found = found || parent == expected
+		}
+		if !found {
+			t.Errorf("expected visitor parent %q, got %v", expected, parents)
+		}
+	}
+
+	if _, ok := schema.TypeMap()["FooConnection"]; !ok {
+		t.Fatal("expected FooConnection to be added to the schema")
+	}
+
+	r := graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: `{ foos { edges { node { name } } } }`,
+	})
+
+	if r.HasErrors() {
+		t.Fatalf("unexpected errors: %v", r.Errors)
+	}
+
+	edges := r.Data.(map[string]interface{})["foos"].(map[string]interface{})["edges"].([]interface{})
+	if len(edges) != 2 {
+		t.Errorf("expected 2 edges, got %d", len(edges))
+	}
+}
@@ -0,0 +1,394 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+const (
+	directiveErrorsAsData = "errorsAsData"
+	errorInterfaceName    = "Error"
+)
+
+// ErrorsAsDataDirective returns errors of the listed types as data in a
+// generated union of the field type and the error types
+var ErrorsAsDataDirective = graphql.NewDirective(graphql.DirectiveConfig{
+	Name:        directiveErrorsAsData,
+	Description: "Changes the field type to a union of the field type and the error types. Errors returned by the resolver are matched to the error types by the registered ErrorMatchers",
+	Locations:   []string{graphql.DirectiveLocationFieldDefinition},
+	Args: graphql.FieldConfigArgument{
+		"types": &graphql.ArgumentConfig{
+			Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String))),
+			Description: "Names of the error types, each must implement the Error interface",
+		},
+		"name": &graphql.ArgumentConfig{
+			Type:        graphql.String,
+			Description: "Name of the generated union, defaults to <Type>Result",
+		},
+	},
+})
+
+// ErrorMatcher matches an error returned by a resolver to an error type and
+// returns the value the error type is resolved from. A nil value resolves the
+// error type from the error message
+type ErrorMatcher func(err error) (value interface{}, ok bool)
+
+// ErrorIs matches errors that are the target error with errors.Is
+func ErrorIs(target error) ErrorMatcher {
+	return func(err error) (interface{}, bool) {
+		return nil, errors.Is(err, target)
+	}
+}
+
+// ErrorAs matches errors of the same type as the example with errors.As and
+// resolves the error type from the matched error value
+func ErrorAs(example error) ErrorMatcher {
+	t := reflect.TypeOf(example)
+	return func(err error) (interface{}, bool) {
+		target := reflect.New(t)
+		if !errors.As(err, target.Interface()) {
+			return nil, false
+		}
+		return target.Elem().Interface(), true
+	}
+}
+
+// an error resolved as an error type
+type errorData struct {
+	typeName string
+	value    interface{}
+	err      error
+}
+
+// the error interface added to documents using @errorsAsData without one
+const errorInterfaceTypeDefs = `
+interface Error {
+	message: String!
+}
+`
+
+// expandErrorsAsData changes the type of @errorsAsData fields to a generated
+// union of the field type and the error types. The union name is written
+// back to the directive for the visitor
+func expandErrorsAsData(document *ast.Document) (*ast.Document, error) {
+	unions := map[string][]string{}
+	order := []string{}
+
+	for _, def := range document.Definitions {
+		for _, object := range objectDefinitions(def) {
+			for _, field := range object.Fields {
+				directive := getASTDirective(field.Directives, directiveErrorsAsData)
+				if directive == nil {
+					continue
+				}
+
+				path := object.Name.Value + "." + field.Name.Value
+				errorTypes := getASTDirectiveStringListArg(directive, "types")
+				if len(errorTypes) == 0 {
+					return nil, fmt.Errorf("@%s on %s requires at least one error type", directiveErrorsAsData, path)
+				}
+
+				fieldType := field.Type
+				nonNull, isNonNull := fieldType.(*ast.NonNull)
+				if isNonNull {
+					fieldType = nonNull.Type
+				}
+				named, ok := fieldType.(*ast.Named)
+				if !ok {
+					return nil, fmt.Errorf("@%s on %s requires a named object type", directiveErrorsAsData, path)
+				}
+				if _, ok := FindDefinition(document, named.Name.Value).(*ast.ObjectDefinition); !ok {
+					return nil, fmt.Errorf("@%s on %s requires a named object type, found %s", directiveErrorsAsData, path, named.Name.Value)
+				}
+
+				for _, name := range errorTypes {
+					if !implementsError(document, name) {
+						return nil, fmt.Errorf("@%s on %s: error type %s must be an object implementing %s", directiveErrorsAsData, path, name, errorInterfaceName)
+					}
+				}
+
+				unionName := getASTDirectiveStringArg(directive, "name")
+				if unionName == "" {
+					unionName = named.Name.Value + "Result"
+				}
+				members := append([]string{named.Name.Value}, errorTypes...)
+
+				if existing, ok := unions[unionName]; ok {
+					if !sameMembers(existing, members) {
+						return nil, fmt.Errorf("@%s on %s: union %s is generated with different types", directiveErrorsAsData, path, unionName)
+					}
+				} else {
+					if FindDefinition(document, unionName) != nil {
+						return nil, fmt.Errorf("@%s on %s: definition %q already exists", directiveErrorsAsData, path, unionName)
+					}
+					unions[unionName] = members
+					order = append(order, unionName)
+				}
+
+				var unionType ast.Type = ast.NewNamed(&ast.Named{Name: ast.NewName(&ast.Name{Value: unionName})})
+				if isNonNull {
+					unionType = ast.NewNonNull(&ast.NonNull{Type: unionType})
+				}
+				field.Type = unionType
+				setASTDirectiveStringArg(directive, "name", unionName)
+			}
+		}
+	}
+
+	if len(order) == 0 {
+		return document, nil
+	}
+
+	if FindDefinition(document, errorInterfaceName) == nil {
+		generated, err := parser.Parse(parser.ParseParams{
+			Source: &source.Source{
+				Body: []byte(errorInterfaceTypeDefs),
+				Name: "ErrorsAsData",
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, def := range generated.Definitions {
+			if err := AddDefinition(document, def); err != nil {
+				return nil, fmt.Errorf("@%s: %v", directiveErrorsAsData, err)
+			}
+		}
+	}
+
+	for _, unionName := range order {
+		types := []*ast.Named{}
+		for _, member := range unions[unionName] {
+			types = append(types, ast.NewNamed(&ast.Named{Name: ast.NewName(&ast.Name{Value: member})}))
+		}
+		union := ast.NewUnionDefinition(&ast.UnionDefinition{
+			Name:  ast.NewName(&ast.Name{Value: unionName}),
+			Types: types,
+		})
+		if err := AddDefinition(document, union); err != nil {
+			return nil, fmt.Errorf("@%s: %v", directiveErrorsAsData, err)
+		}
+	}
+
+	return document, nil
+}
+
+// gets the string list value of a directive argument
+func getASTDirectiveStringListArg(directive *ast.Directive, name string) []string {
+	values := []string{}
+	for _, arg := range directive.Arguments {
+		if arg.Name.Value != name {
+			continue
+		}
+		switch v := arg.Value.(type) {
+		case *ast.ListValue:
+			for _, item := range v.Values {
+				if s, ok := item.GetValue().(string); ok {
+					values = append(values, s)
+				}
+			}
+		case *ast.StringValue:
+			values = append(values, v.Value)
+		}
+	}
+	return values
+}
+
+// determines if a type is an object implementing the Error interface in its
+// definition or an extension
+func implementsError(document *ast.Document, name string) bool {
+	object, ok := FindDefinition(document, name).(*ast.ObjectDefinition)
+	if !ok {
+		return false
+	}
+
+	objects := []*ast.ObjectDefinition{object}
+	for _, ext := range FindExtensions(document, name) {
+		objects = append(objects, ext.Definition)
+	}
+	for _, o := range objects {
+		for _, iface := range o.Interfaces {
+			if iface.Name.Value == errorInterfaceName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// determines if two unions have the same member types
+func sameMembers(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	x := append([]string{}, a...)
+	y := append([]string{}, b...)
+	sort.Strings(x)
+	sort.Strings(y)
+	return strings.Join(x, ",") == strings.Join(y, ",")
+}
+
+// resolves errors matched to an error type as data
+func (c *registry) errorsAsDataVisitor() *SchemaDirectiveVisitor {
+	return &SchemaDirectiveVisitor{
+		VisitFieldDefinition: func(p VisitFieldDefinitionParams) error {
+			errorTypes := []string{}
+			if values, ok := p.Args["types"].([]interface{}); ok {
+				for _, value := range values {
+					if name, ok := value.(string); ok {
+						errorTypes = append(errorTypes, name)
+					}
+				}
+			}
+
+			matchers := make([]ErrorMatcher, len(errorTypes))
+			for i, name := range errorTypes {
+				matcher, ok := c.errorMatchers[name]
+				if !ok {
+					return fmt.Errorf("@%s on %s.%s: no ErrorMatcher registered for %s", directiveErrorsAsData, p.ParentName, p.Config.Name, name)
+				}
+				matchers[i] = matcher
+			}
+
+			resolve := p.Config.Resolve
+			if resolve == nil {
+				resolve = graphql.DefaultResolveFn
+			}
+
+			p.Config.Resolve = func(rp graphql.ResolveParams) (interface{}, error) {
+				result, err := resolve(rp)
+				if err == nil {
+					return result, nil
+				}
+
+				// errors are matched in the order of the directive types
+				for i, match := range matchers {
+					if value, ok := match(err); ok {
+						return &errorData{typeName: errorTypes[i], value: value, err: err}, nil
+					}
+				}
+				return nil, err
+			}
+
+			return nil
+		},
+	}
+}
+
+// resolves the type of error data from the matched error type
+func resolveErrorDataType(p graphql.ResolveTypeParams, defaultName string) *graphql.Object {
+	name := defaultName
+	if data, ok := p.Value.(*errorData); ok {
+		name = data.typeName
+	}
+	object, _ := p.Info.Schema.Type(name).(*graphql.Object)
+	return object
+}
+
+// adds a ResolveType to each generated union and the Error interface unless
+// one was supplied
+func (c *registry) importErrorsAsDataResolvers() error {
+	used := false
+	for _, def := range c.document.Definitions {
+		for _, object := range objectDefinitions(def) {
+			for _, field := range object.Fields {
+				directive := getASTDirective(field.Directives, directiveErrorsAsData)
+				if directive == nil {
+					continue
+				}
+				used = true
+
+				unionName := getASTDirectiveStringArg(directive, "name")
+				if _, ok := c.resolverMap[unionName]; ok {
+					continue
+				}
+				union, ok := FindDefinition(c.document, unionName).(*ast.UnionDefinition)
+				if !ok || len(union.Types) == 0 {
+					continue
+				}
+
+				// the first member is the field type
+				successName := union.Types[0].Name.Value
+				if err := c.importResolver(unionName, &UnionResolver{
+					ResolveType: func(p graphql.ResolveTypeParams) *graphql.Object {
+						return resolveErrorDataType(p, successName)
+					},
+				}); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if !used {
+		return nil
+	}
+	if _, ok := c.resolverMap[errorInterfaceName]; ok {
+		return nil
+	}
+	return c.importResolver(errorInterfaceName, &InterfaceResolver{
+		ResolveType: func(p graphql.ResolveTypeParams) *graphql.Object {
+			return resolveErrorDataType(p, "")
+		},
+	})
+}
+
+// resolves the fields of error types from the matched value, the message
+// field defaults to the error message
+func (c *registry) resolveErrorData(schema graphql.Schema) {
+	errorTypes := map[string]bool{}
+	for _, def := range c.document.Definitions {
+		for _, object := range objectDefinitions(def) {
+			for _, field := range object.Fields {
+				if directive := getASTDirective(field.Directives, directiveErrorsAsData); directive != nil {
+					for _, name := range getASTDirectiveStringListArg(directive, "types") {
+						errorTypes[name] = true
+					}
+				}
+			}
+		}
+	}
+
+	for name := range errorTypes {
+		object, ok := schema.Type(name).(*graphql.Object)
+		if !ok {
+			continue
+		}
+
+		for fieldName, field := range object.Fields() {
+			resolve := field.Resolve
+			if resolve == nil {
+				resolve = graphql.DefaultResolveFn
+			}
+
+			isMessage := fieldName == "message"
+			field.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+				data, ok := p.Source.(*errorData)
+				if !ok {
+					return resolve(p)
+				}
+
+				var result interface{}
+				if data.value != nil {
+					p.Source = data.value
+					var err error
+					if result, err = resolve(p); err != nil {
+						return nil, err
+					}
+				}
+				if isMessage && result == nil {
+					return data.err.Error(), nil
+				}
+				return result, nil
+			}
+		}
+	}
+}
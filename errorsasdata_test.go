@@ -0,0 +1,168 @@
+package tools
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+)
+
+var errOrderNotFound = errors.New("order not found")
+
+type outOfStockError struct {
+	SKU       string `json:"sku"`
+	Available int    `json:"available"`
+}
+
+func (e *outOfStockError) Error() string {
+	return fmt.Sprintf("%s is out of stock", e.SKU)
+}
+
+func TestErrorsAsData(t *testing.T) {
+	typeDefs := `
+type Order {
+	id: ID!
+}
+
+type NotFoundError implements Error {
+	message: String!
+}
+
+type OutOfStockError implements Error {
+	message: String!
+	sku: String!
+	available: Int!
+}
+
+type Query {
+	order: Order
+}
+
+type Mutation {
+	placeOrder(sku: String!): Order! @errorsAsData(types: ["NotFoundError", "OutOfStockError"])
+	cancelOrder(id: ID!): Order @errorsAsData(types: ["NotFoundError"], name: "CancelOrderResult")
+}`
+
+	schema, err := MakeExecutableSchema(ExecutableSchema{
+		TypeDefs: typeDefs,
+		ErrorMatchers: map[string]ErrorMatcher{
+			"NotFoundError":   ErrorIs(errOrderNotFound),
+			"OutOfStockError": ErrorAs(&outOfStockError{}),
+		},
+		Resolvers: ResolverMap{
+			"Mutation": &ObjectResolver{
+				Fields: FieldResolveMap{
+					"placeOrder": &FieldResolve{
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							switch sku := p.Args["sku"].(string); sku {
+							case "missing":
+								return nil, fmt.Errorf("place order: %w", errOrderNotFound)
+							case "sold-out":
+								return nil, &outOfStockError{SKU: sku, Available: 0}
+							case "broken":
+								return nil, errors.New("database unavailable")
+							}
+							return map[string]interface{}{"id": "1"}, nil
+						},
+					},
+					"cancelOrder": &FieldResolve{
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							return nil, errOrderNotFound
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	union, ok := schema.Type("OrderResult").(*graphql.Union)
+	if !ok {
+		t.Fatal("expected OrderResult to be generated")
+	}
+	if len(union.Types()) != 3 {
+		t.Errorf("expected OrderResult to have 3 types, got %d", len(union.Types()))
+	}
+	if _, ok := schema.Type("CancelOrderResult").(*graphql.Union); !ok {
+		t.Error("expected the named union CancelOrderResult to be generated")
+	}
+	if _, ok := schema.Type("Error").(*graphql.Interface); !ok {
+		t.Error("expected the Error interface to be generated")
+	}
+
+	query := func(request string) string {
+		r := graphql.Do(graphql.Params{Schema: schema, RequestString: request})
+		data, _ := json.Marshal(r)
+		return string(data)
+	}
+
+	selection := `{
+		__typename
+		... on Order { id }
+		... on Error { message }
+		... on OutOfStockError { sku available }
+	}`
+
+	for sku, expected := range map[string]string{
+		"ok":       `{"data":{"placeOrder":{"__typename":"Order","id":"1"}}}`,
+		"missing":  `{"data":{"placeOrder":{"__typename":"NotFoundError","message":"place order: order not found"}}}`,
+		"sold-out": `{"data":{"placeOrder":{"__typename":"OutOfStockError","available":0,"message":"sold-out is out of stock","sku":"sold-out"}}}`,
+	} {
+		result := query(fmt.Sprintf(`mutation { placeOrder(sku: %q) %s }`, sku, selection))
+		if result != expected {
+			t.Errorf("%s: expected %s, got %s", sku, expected, result)
+		}
+	}
+
+	// unmatched errors remain top-level errors
+	result := query(`mutation { placeOrder(sku: "broken") { __typename } }`)
+	if !strings.Contains(result, "database unavailable") || !strings.Contains(result, `"data":null`) {
+		t.Errorf("expected an unmatched error to be returned, got %s", result)
+	}
+
+	result = query(`mutation { cancelOrder(id: "1") { __typename ... on Error { message } } }`)
+	if result != `{"data":{"cancelOrder":{"__typename":"NotFoundError","message":"order not found"}}}` {
+		t.Errorf("unexpected cancelOrder result %s", result)
+	}
+}
+
+func TestErrorsAsDataInvalid(t *testing.T) {
+	for name, tt := range map[string]struct {
+		typeDefs string
+		matchers map[string]ErrorMatcher
+	}{
+		"not an error type": {
+			typeDefs: `
+type Order { id: ID! }
+type NotFoundError { message: String! }
+type Query { order: Order @errorsAsData(types: ["NotFoundError"]) }`,
+			matchers: map[string]ErrorMatcher{"NotFoundError": ErrorIs(errOrderNotFound)},
+		},
+		"list field": {
+			typeDefs: `
+type Order { id: ID! }
+type NotFoundError implements Error { message: String! }
+type Query { orders: [Order] @errorsAsData(types: ["NotFoundError"]) }`,
+			matchers: map[string]ErrorMatcher{"NotFoundError": ErrorIs(errOrderNotFound)},
+		},
+		"missing matcher": {
+			typeDefs: `
+type Order { id: ID! }
+type NotFoundError implements Error { message: String! }
+type Query { order: Order @errorsAsData(types: ["NotFoundError"]) }`,
+		},
+	} {
+		_, err := MakeExecutableSchema(ExecutableSchema{
+			TypeDefs:      tt.typeDefs,
+			ErrorMatchers: tt.matchers,
+		})
+		if err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
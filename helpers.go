@@ -137,7 +137,8 @@ func getDefaultValue(input *ast.InputValueDefinition) (interface{}, error) {
 	return defaultValue, err
 }
 
-// ReadSourceFiles reads all source files from a specified path
+// ReadSourceFiles reads all source files from a specified path, subdirectories
+// are only read when recursive is true
 func ReadSourceFiles(p string, recursive ...bool) (string, error) {
 	typeDefs := []string{}
 	abs, err := filepath.Abs(p)
@@ -173,7 +174,7 @@ func ReadSourceFiles(p string, recursive ...bool) (string, error) {
 			return "", err
 		}
 		for _, file := range files {
-			if err := readFunc(abs, file, nil); err != nil {
+			if err := readFunc(filepath.Join(abs, file.Name()), file, nil); err != nil {
 				return "", err
 			}
 		}
@@ -274,6 +275,43 @@ func isHiddenField(field *ast.FieldDefinition) bool {
 	return hide
 }
 
+// gets a directive by name from a list of ast directives or nil
+func getASTDirective(directives []*ast.Directive, name string) *ast.Directive {
+	for _, directive := range directives {
+		if directive.Name != nil && directive.Name.Value == name {
+			return directive
+		}
+	}
+	return nil
+}
+
+// gets the string value of a directive argument or an empty string
+func getASTDirectiveStringArg(directive *ast.Directive, name string) string {
+	for _, arg := range directive.Arguments {
+		if arg.Name.Value == name {
+			if value, ok := arg.Value.GetValue().(string); ok {
+				return value
+			}
+		}
+	}
+	return ""
+}
+
+// sets the string value of a directive argument replacing any existing value
+func setASTDirectiveStringArg(directive *ast.Directive, name, value string) {
+	stringValue := ast.NewStringValue(&ast.StringValue{Value: value})
+	for _, arg := range directive.Arguments {
+		if arg.Name.Value == name {
+			arg.Value = stringValue
+			return
+		}
+	}
+	directive.Arguments = append(directive.Arguments, ast.NewArgument(&ast.Argument{
+		Name:  ast.NewName(&ast.Name{Value: name}),
+		Value: stringValue,
+	}))
+}
+
 // Merges object definitions
 func MergeExtensions(obj *ast.ObjectDefinition, extensions ...*ast.ObjectDefinition) *ast.ObjectDefinition {
 	merged := &ast.ObjectDefinition{
@@ -0,0 +1,49 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadSourceFiles(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"query.graphql":        "type Query { user: User }",
+		"user.gql":             "type User { id: ID! }",
+		"notes.txt":            "not a schema",
+		"nested/post.graphql":  "type Post { id: ID! }",
+		"nested/ignored.jsonl": "{}",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// non-recursive reads only read the files of the directory itself
+	typeDefs, err := ReadSourceFiles(dir)
+	if err != nil {
+		t.Fatalf("failed to read the directory: %v", err)
+	}
+	if typeDefs != "type Query { user: User }\ntype User { id: ID! }" {
+		t.Errorf("unexpected non-recursive type definitions %q", typeDefs)
+	}
+
+	typeDefs, err = ReadSourceFiles(dir, true)
+	if err != nil {
+		t.Fatalf("failed to read the directory recursively: %v", err)
+	}
+	for _, def := range []string{"type Query", "type User", "type Post"} {
+		if !strings.Contains(typeDefs, def) {
+			t.Errorf("expected %q in the recursive type definitions %q", def, typeDefs)
+		}
+	}
+	if strings.Contains(typeDefs, "not a schema") {
+		t.Error("expected files without a GraphQL extension to be skipped")
+	}
+}
@@ -0,0 +1,294 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+)
+
+// decodes input object values with an InputObjectResolver
+type inputDecoder struct {
+	resolvers map[string]*InputObjectResolver
+	needs     map[string]bool
+}
+
+// wraps the resolvers of fields with arguments containing input objects with
+// an InputObjectResolver so their coerced values are decoded into Go values
+func (c *registry) decodeInputArguments(schema graphql.Schema) error {
+	decoder := &inputDecoder{
+		resolvers: map[string]*InputObjectResolver{},
+		needs:     map[string]bool{},
+	}
+
+	// the @relayMutation resolver unwraps its generated input into the field
+	// arguments, so the input itself is never decoded
+	relayInputs := relayInputNames(c.document)
+
+	for name, resolver := range c.resolverMap {
+		res, ok := resolver.(*InputObjectResolver)
+		if !ok || relayInputs[name] {
+			continue
+		}
+		if res.Type == nil && res.ParseValue == nil {
+			return fmt.Errorf("input object resolver for %s requires a Type or ParseValue", name)
+		}
+		if _, ok := schema.Type(name).(*graphql.InputObject); !ok {
+			return fmt.Errorf("input object resolver supplied for %s which is not an input object", name)
+		}
+		decoder.resolvers[name] = res
+	}
+
+	if len(decoder.resolvers) == 0 {
+		return nil
+	}
+
+	// determine which input objects need decoding before requests are resolved
+	for _, t := range schema.TypeMap() {
+		if input, ok := t.(*graphql.InputObject); ok {
+			decoder.needsDecode(input, map[string]bool{})
+		}
+	}
+
+	for name, t := range schema.TypeMap() {
+		object, ok := t.(*graphql.Object)
+		if !ok || strings.HasPrefix(name, "__") {
+			continue
+		}
+
+		for _, field := range object.Fields() {
+			decodeArgs := false
+			for _, arg := range field.Args {
+				if decoder.needsDecode(arg.Type, map[string]bool{}) {
+					decodeArgs = true
+				}
+			}
+			if !decodeArgs {
+				continue
+			}
+
+			resolve := field.Resolve
+			if resolve == nil {
+				resolve = graphql.DefaultResolveFn
+			}
+
+			args := field.Args
+			field.Resolve = func(p graphql.ResolveParams) (interface{}, error) {
+				decoded := make(map[string]interface{}, len(p.Args))
+				for key, value := range p.Args {
+					decoded[key] = value
+				}
+
+				for _, arg := range args {
+					value, ok := decoded[arg.Name()]
+					if !ok {
+						continue
+					}
+					v, err := decoder.decode(arg.Type, value, arg.Name())
+					if err != nil {
+						return nil, err
+					}
+					decoded[arg.Name()] = v
+				}
+
+				p.Args = decoded
+				return resolve(p)
+			}
+		}
+	}
+
+	return nil
+}
+
+// determines if an input type contains an input object with a resolver
+func (d *inputDecoder) needsDecode(t graphql.Input, visited map[string]bool) bool {
+	switch t := t.(type) {
+	case *graphql.NonNull:
+		return d.needsDecode(t.OfType, visited)
+	case *graphql.List:
+		return d.needsDecode(t.OfType, visited)
+	case *graphql.InputObject:
+		name := t.Name()
+		if needs, ok := d.needs[name]; ok {
+			return needs
+		}
+		if _, ok := d.resolvers[name]; ok {
+			d.needs[name] = true
+			return true
+		}
+		if visited[name] {
+			return false
+		}
+		visited[name] = true
+
+		for _, field := range t.Fields() {
+			if d.needsDecode(field.Type, visited) {
+				d.needs[name] = true
+				return true
+			}
+		}
+		d.needs[name] = false
+	}
+	return false
+}
+
+// decodes a coerced value, nested input objects are decoded first
+func (d *inputDecoder) decode(t graphql.Input, value interface{}, path string) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	switch t := t.(type) {
+	case *graphql.NonNull:
+		return d.decode(t.OfType, value, path)
+
+	case *graphql.List:
+		items, ok := value.([]interface{})
+		if !ok {
+			return d.decode(t.OfType, value, path)
+		}
+		decoded := make([]interface{}, len(items))
+		for i, item := range items {
+			v, err := d.decode(t.OfType, item, fmt.Sprintf("%s.%d", path, i))
+			if err != nil {
+				return nil, err
+			}
+			decoded[i] = v
+		}
+		return decoded, nil
+
+	case *graphql.InputObject:
+		if !d.needs[t.Name()] {
+			return value, nil
+		}
+
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return value, nil
+		}
+
+		decoded := make(map[string]interface{}, len(fields))
+		inputFields := t.Fields()
+		for key, fieldValue := range fields {
+			if field, ok := inputFields[key]; ok {
+				v, err := d.decode(field.Type, fieldValue, path+"."+key)
+				if err != nil {
+					return nil, err
+				}
+				fieldValue = v
+			}
+			decoded[key] = fieldValue
+		}
+
+		resolver, ok := d.resolvers[t.Name()]
+		if !ok {
+			return decoded, nil
+		}
+
+		if resolver.ParseValue != nil {
+			v, err := resolver.ParseValue(decoded)
+			if err != nil {
+				return nil, fmt.Errorf("invalid argument %q: %v", path, err)
+			}
+			return v, nil
+		}
+
+		rv, err := decodeInto(reflect.TypeOf(resolver.Type), decoded, path)
+		if err != nil {
+			return nil, err
+		}
+		return rv.Interface(), nil
+	}
+
+	return value, nil
+}
+
+// decodes a value into a Go type, maps are decoded into structs by json tag
+// or case insensitive field name
+func decodeInto(t reflect.Type, value interface{}, path string) (reflect.Value, error) {
+	if value == nil {
+		return reflect.Zero(t), nil
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Type().AssignableTo(t) {
+		return rv, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		elem, err := decodeInto(t.Elem(), value, path)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		ptr := reflect.New(t.Elem())
+		ptr.Elem().Set(elem)
+		return ptr, nil
+
+	case reflect.Struct:
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			break
+		}
+		s := reflect.New(t).Elem()
+		for key, fieldValue := range fields {
+			field, ok := structFieldByName(s, key)
+			if !ok {
+				return reflect.Value{}, fmt.Errorf("invalid argument %q: %s has no field for %s", path+"."+key, t, key)
+			}
+			v, err := decodeInto(field.Type(), fieldValue, path+"."+key)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			field.Set(v)
+		}
+		return s, nil
+
+	case reflect.Slice:
+		items, ok := value.([]interface{})
+		if !ok {
+			break
+		}
+		slice := reflect.MakeSlice(t, len(items), len(items))
+		for i, item := range items {
+			v, err := decodeInto(t.Elem(), item, fmt.Sprintf("%s.%d", path, i))
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			slice.Index(i).Set(v)
+		}
+		return slice, nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			return rv.Convert(t), nil
+		}
+
+	case reflect.String:
+		if rv.Kind() == reflect.String {
+			return rv.Convert(t), nil
+		}
+
+	case reflect.Bool:
+		if rv.Kind() == reflect.Bool {
+			return rv.Convert(t), nil
+		}
+	}
+
+	// fall back to json for other types such as maps and custom unmarshalers
+	b, err := json.Marshal(value)
+	if err == nil {
+		ptr := reflect.New(t)
+		if err = json.Unmarshal(b, ptr.Interface()); err == nil {
+			return ptr.Elem(), nil
+		}
+	}
+	return reflect.Value{}, fmt.Errorf("invalid argument %q: cannot decode %T into %s", path, value, t)
+}
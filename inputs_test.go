@@ -0,0 +1,196 @@
+package tools
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+)
+
+type testAddress struct {
+	Street string
+	Zip    int `json:"postcode"`
+}
+
+type testUserInput struct {
+	Name      string
+	Age       int64
+	Addresses []*testAddress
+	Role      string
+}
+
+type testRange struct {
+	Min, Max int
+}
+
+func TestInputObjectResolver(t *testing.T) {
+	var user *testUserInput
+	var ranges []interface{}
+
+	schema, err := MakeExecutableSchema(ExecutableSchema{
+		TypeDefs: `
+input AddressInput {
+	street: String!
+	postcode: Int
+}
+
+input UserInput {
+	name: String!
+	age: Int
+	addresses: [AddressInput]
+}
+
+input RangeInput {
+	min: Int!
+	max: Int!
+}
+
+input FilterInput {
+	ranges: [RangeInput]
+}
+
+type Query {
+	search(filter: FilterInput): Boolean
+}
+
+type Mutation {
+	createUser(input: UserInput!): Boolean
+}`,
+		Resolvers: ResolverMap{
+			"UserInput": &InputObjectResolver{Type: &testUserInput{}},
+			"RangeInput": &InputObjectResolver{
+				ParseValue: func(value map[string]interface{}) (interface{}, error) {
+					r := testRange{Min: value["min"].(int), Max: value["max"].(int)}
+					if r.Min > r.Max {
+						return nil, errors.New("min cannot exceed max")
+					}
+					return r, nil
+				},
+			},
+			"Query": &ObjectResolver{
+				Fields: FieldResolveMap{
+					"search": &FieldResolve{
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							ranges = p.Args["filter"].(map[string]interface{})["ranges"].([]interface{})
+							return true, nil
+						},
+					},
+				},
+			},
+			"Mutation": &ObjectResolver{
+				Fields: FieldResolveMap{
+					"createUser": &FieldResolve{
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							user = p.Args["input"].(*testUserInput)
+							return true, nil
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: `mutation { createUser(input: { name: "amy", age: 30, addresses: [{ street: "main", postcode: 1234 }] }) }`,
+	})
+	if r.HasErrors() {
+		t.Fatal(r.Errors)
+	}
+	if user.Name != "amy" || user.Age != 30 || len(user.Addresses) != 1 || user.Addresses[0].Zip != 1234 {
+		t.Errorf("unexpected decoded input %+v", user)
+	}
+
+	r = graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: `{ search(filter: { ranges: [{ min: 1, max: 2 }] }) }`,
+	})
+	if r.HasErrors() {
+		t.Fatal(r.Errors)
+	}
+	if len(ranges) != 1 || ranges[0] != (testRange{Min: 1, Max: 2}) {
+		t.Errorf("unexpected decoded ranges %v", ranges)
+	}
+
+	r = graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: `{ search(filter: { ranges: [{ min: 1, max: 2 }, { min: 3, max: 1 }] }) }`,
+	})
+	if len(r.Errors) != 1 || !strings.Contains(r.Errors[0].Message, `invalid argument "filter.ranges.1": min cannot exceed max`) {
+		t.Errorf("expected an error with the argument path, got %v", r.Errors)
+	}
+}
+
+func TestInputObjectResolverErrors(t *testing.T) {
+	if _, err := MakeExecutableSchema(ExecutableSchema{
+		TypeDefs: `
+type Query {
+	version: String
+}`,
+		Resolvers: ResolverMap{
+			"Query": &InputObjectResolver{Type: testRange{}},
+		},
+	}); err == nil {
+		t.Error("expected an error for an input object resolver on an object")
+	}
+}
+
+func TestInputObjectResolverRelayMutation(t *testing.T) {
+	var name string
+	var address *testAddress
+
+	schema, err := MakeExecutableSchema(ExecutableSchema{
+		TypeDefs: `
+input AddressInput {
+	street: String!
+	postcode: Int
+}
+
+type Query {
+	version: String
+}
+
+type Mutation {
+	createUser(name: String!, address: AddressInput): Boolean @relayMutation(payloadField: "created")
+}`,
+		Resolvers: ResolverMap{
+			"CreateUserInput": &InputObjectResolver{Type: &testUserInput{}},
+			"AddressInput":    &InputObjectResolver{Type: &testAddress{}},
+			"Mutation": &ObjectResolver{
+				Fields: FieldResolveMap{
+					"createUser": &FieldResolve{
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							name, _ = p.Args["name"].(string)
+							address, _ = p.Args["address"].(*testAddress)
+							return true, nil
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: `mutation { createUser(input: { clientMutationId: "abc", name: "amy", address: { street: "main", postcode: 1234 } }) { clientMutationId created } }`,
+	})
+	if r.HasErrors() {
+		t.Fatal(r.Errors)
+	}
+
+	// the generated input is unwrapped by @relayMutation, nested inputs are decoded
+	if name != "amy" || address == nil || address.Street != "main" || address.Zip != 1234 {
+		t.Errorf("unexpected mutation arguments %q %+v", name, address)
+	}
+	payload := r.Data.(map[string]interface{})["createUser"].(map[string]interface{})
+	if payload["clientMutationId"] != "abc" || payload["created"] != true {
+		t.Errorf("unexpected payload %v", payload)
+	}
+}
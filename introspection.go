@@ -0,0 +1,162 @@
+package tools
+
+import (
+	"fmt"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/printer"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+const appliedDirectivesFieldName = "_appliedDirectives"
+
+// the types and root field exposing applied directives. graphql-go shares
+// its introspection types between all schemas, so the directives are served
+// from a root field of the schema rather than from __Type
+const appliedDirectivesTypeDefs = `
+type _AppliedDirectiveArgument {
+	name: String!
+	value: String!
+}
+
+type _AppliedDirective {
+	name: String!
+	args: [_AppliedDirectiveArgument!]!
+}
+
+type _SchemaElementDirectives {
+	coordinate: String!
+	directives: [_AppliedDirective!]!
+}
+`
+
+// adds the applied directive types and the _appliedDirectives root field
+func addAppliedDirectivesField(document *ast.Document) error {
+	queryName := getRootTypeName(document, ast.OperationTypeQuery, DefaultRootQueryName)
+	field := "{\n\t_appliedDirectives(coordinate: String): [_SchemaElementDirectives!]!\n}"
+
+	typeDefs := appliedDirectivesTypeDefs
+	if FindDefinition(document, queryName) != nil {
+		typeDefs += fmt.Sprintf("\nextend type %s %s\n", queryName, field)
+	} else {
+		typeDefs += fmt.Sprintf("\ntype %s %s\n", queryName, field)
+		addSchemaOperation(document, ast.OperationTypeQuery, queryName)
+	}
+
+	generated, err := parser.Parse(parser.ParseParams{
+		Source: &source.Source{
+			Body: []byte(typeDefs),
+			Name: "AppliedDirectives",
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, def := range generated.Definitions {
+		if err := AddDefinition(document, def); err != nil {
+			return fmt.Errorf("IntrospectDirectives: %v", err)
+		}
+	}
+	return nil
+}
+
+// adds the resolver of the _appliedDirectives root field, the directives are
+// recorded from the document of this schema only
+func (c *registry) importAppliedDirectivesResolver(allow []string) {
+	coordinates, applied := collectAppliedDirectives(c.document, allow)
+
+	c.addFieldResolvers(getRootTypeName(c.document, ast.OperationTypeQuery, DefaultRootQueryName), FieldResolveMap{
+		appliedDirectivesFieldName: &FieldResolve{
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				results := []interface{}{}
+				coordinate, filtered := p.Args["coordinate"].(string)
+				for _, c := range coordinates {
+					if filtered && c != coordinate {
+						continue
+					}
+					results = append(results, map[string]interface{}{
+						"coordinate": c,
+						"directives": applied[c],
+					})
+				}
+				return results, nil
+			},
+		},
+	})
+}
+
+// collects the allowed directives applied in the document keyed by schema
+// coordinate, e.g. User, User.name, Query.user(id:), Role.ADMIN
+func collectAppliedDirectives(document *ast.Document, allow []string) ([]string, map[string][]interface{}) {
+	allowed := map[string]bool{}
+	for _, name := range allow {
+		allowed[name] = true
+	}
+
+	coordinates := []string{}
+	applied := map[string][]interface{}{}
+
+	store := func(coordinate string, directives []*ast.Directive) {
+		for _, directive := range directives {
+			if !allowed[directive.Name.Value] {
+				continue
+			}
+			args := []interface{}{}
+			for _, arg := range directive.Arguments {
+				args = append(args, map[string]interface{}{
+					"name":  arg.Name.Value,
+					"value": fmt.Sprintf("%v", printer.Print(arg.Value)),
+				})
+			}
+			if _, ok := applied[coordinate]; !ok {
+				coordinates = append(coordinates, coordinate)
+			}
+			applied[coordinate] = append(applied[coordinate], map[string]interface{}{
+				"name": directive.Name.Value,
+				"args": args,
+			})
+		}
+	}
+
+	storeFields := func(typeName string, fields []*ast.FieldDefinition) {
+		for _, field := range fields {
+			store(typeName+"."+field.Name.Value, field.Directives)
+			for _, arg := range field.Arguments {
+				store(fmt.Sprintf("%s.%s(%s:)", typeName, field.Name.Value, arg.Name.Value), arg.Directives)
+			}
+		}
+	}
+
+	for _, def := range document.Definitions {
+		switch d := def.(type) {
+		case *ast.TypeExtensionDefinition:
+			store(d.Definition.Name.Value, d.Definition.Directives)
+			storeFields(d.Definition.Name.Value, d.Definition.Fields)
+		case *ast.ObjectDefinition:
+			store(d.Name.Value, d.Directives)
+			storeFields(d.Name.Value, d.Fields)
+		case *ast.InterfaceDefinition:
+			store(d.Name.Value, d.Directives)
+			storeFields(d.Name.Value, d.Fields)
+		case *ast.InputObjectDefinition:
+			store(d.Name.Value, d.Directives)
+			for _, field := range d.Fields {
+				store(d.Name.Value+"."+field.Name.Value, field.Directives)
+			}
+		case *ast.EnumDefinition:
+			store(d.Name.Value, d.Directives)
+			for _, value := range d.Values {
+				store(d.Name.Value+"."+value.Name.Value, value.Directives)
+			}
+		case *ast.ScalarDefinition:
+			store(d.Name.Value, d.Directives)
+		case *ast.UnionDefinition:
+			store(d.Name.Value, d.Directives)
+		}
+	}
+
+	return coordinates, applied
+}
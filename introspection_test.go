@@ -0,0 +1,142 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+)
+
+func TestIntrospectAppliedDirectives(t *testing.T) {
+	schema, err := MakeExecutableSchema(ExecutableSchema{
+		TypeDefs: `
+directive @tag(name: String!) on OBJECT | FIELD_DEFINITION | ENUM_VALUE
+directive @constraint(minLength: Int, pattern: String) on ARGUMENT_DEFINITION | INPUT_FIELD_DEFINITION
+directive @internal on FIELD_DEFINITION
+
+enum Role {
+	ADMIN @tag(name: "restricted")
+	USER
+}
+
+input UserInput {
+	name: String! @constraint(minLength: 2, pattern: "^[a-z]+$")
+	role: Role
+}
+
+type User @tag(name: "public") {
+	id: ID!
+	name: String @tag(name: "pii") @internal
+}
+
+type Query {
+	user(id: ID! @constraint(minLength: 1)): User
+}
+
+type Mutation {
+	createUser(input: UserInput!): User
+}`,
+		SchemaDirectives: SchemaDirectiveVisitorMap{
+			"tag":        &SchemaDirectiveVisitor{},
+			"constraint": &SchemaDirectiveVisitor{},
+			"internal":   &SchemaDirectiveVisitor{},
+		},
+		IntrospectDirectives: []string{"tag", "constraint"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := graphql.Do(graphql.Params{
+		Schema: schema,
+		RequestString: `{
+	_appliedDirectives {
+		coordinate
+		directives { name args { name value } }
+	}
+}`,
+	})
+	if r.HasErrors() {
+		t.Fatal(r.Errors)
+	}
+
+	b, _ := json.Marshal(r.Data)
+	var data struct {
+		AppliedDirectives []struct {
+			Coordinate string
+			Directives []appliedDirectiveResult
+		} `json:"_appliedDirectives"`
+	}
+	if err := json.Unmarshal(b, &data); err != nil {
+		t.Fatal(err)
+	}
+
+	applied := map[string][]appliedDirectiveResult{}
+	for _, element := range data.AppliedDirectives {
+		applied[element.Coordinate] = element.Directives
+	}
+	if len(applied) != 5 {
+		t.Errorf("unexpected coordinates %+v", applied)
+	}
+
+	if d := applied["User"]; len(d) != 1 || d[0].Name != "tag" || d[0].Args[0].Value != `"public"` {
+		t.Errorf("unexpected User directives %+v", d)
+	}
+
+	// @internal is not in the allowlist
+	if d := applied["User.name"]; len(d) != 1 || d[0].Name != "tag" || d[0].Args[0].Value != `"pii"` {
+		t.Errorf("unexpected User.name directives %+v", d)
+	}
+
+	if d := applied["Query.user(id:)"]; len(d) != 1 || d[0].Args[0].Name != "minLength" || d[0].Args[0].Value != "1" {
+		t.Errorf("unexpected Query.user(id:) directives %+v", d)
+	}
+
+	if d := applied["UserInput.name"]; len(d) != 1 || len(d[0].Args) != 2 {
+		t.Errorf("unexpected UserInput.name directives %+v", d)
+	}
+
+	if d := applied["Role.ADMIN"]; len(d) != 1 {
+		t.Errorf("unexpected Role.ADMIN directives %+v", d)
+	}
+
+	r = graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: `{ _appliedDirectives(coordinate: "User") { coordinate } }`,
+	})
+	if b, _ := json.Marshal(r); string(b) != `{"data":{"_appliedDirectives":[{"coordinate":"User"}]}}` {
+		t.Errorf("unexpected filtered result %s", b)
+	}
+}
+
+func TestIntrospectAppliedDirectivesOptIn(t *testing.T) {
+	schema, err := MakeExecutableSchema(ExecutableSchema{
+		TypeDefs: `type Query { hello: String }`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if schema.Type("_AppliedDirective") != nil {
+		t.Error("expected the applied directive types to be omitted")
+	}
+	if _, ok := graphql.TypeType.Fields()["appliedDirectives"]; ok {
+		t.Error("expected the shared introspection types to be unchanged")
+	}
+
+	r := graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: `{ _appliedDirectives { coordinate } }`,
+	})
+	if !r.HasErrors() {
+		t.Error("expected _appliedDirectives to be unknown")
+	}
+}
+
+type appliedDirectiveResult struct {
+	Name string
+	Args []struct {
+		Name  string
+		Value string
+	}
+}
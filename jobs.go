@@ -0,0 +1,355 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JobStatus the status of an async job
+type JobStatus string
+
+// job statuses
+const (
+	JobPending   JobStatus = "PENDING"
+	JobRunning   JobStatus = "RUNNING"
+	JobSucceeded JobStatus = "SUCCEEDED"
+	JobFailed    JobStatus = "FAILED"
+	JobCancelled JobStatus = "CANCELLED"
+)
+
+// ErrJobNotFound is returned when a job does not exist
+var ErrJobNotFound = errors.New("job not found")
+
+// Done determines if the job has finished
+func (s JobStatus) Done() bool {
+	return s == JobSucceeded || s == JobFailed || s == JobCancelled
+}
+
+// Job a long running mutation executed in the background
+type Job struct {
+	ID        string      `json:"id"`
+	Field     string      `json:"field"`
+	Status    JobStatus   `json:"status"`
+	Progress  float64     `json:"progress"`
+	Result    interface{} `json:"result"`
+	Error     *string     `json:"error"`
+	CreatedAt time.Time   `json:"createdAt"`
+	UpdatedAt time.Time   `json:"updatedAt"`
+}
+
+// JobStore stores jobs, Get returns ErrJobNotFound for unknown jobs
+type JobStore interface {
+	Save(ctx context.Context, job *Job) error
+	Get(ctx context.Context, id string) (*Job, error)
+}
+
+// MemoryJobStore an in-memory JobStore. Finished jobs are kept for the
+// Retention period after their last update, without a Retention the store
+// grows without limit
+type MemoryJobStore struct {
+	Retention time.Duration
+
+	mx    sync.RWMutex
+	jobs  map[string]Job
+	swept time.Time
+}
+
+// NewMemoryJobStore creates a new in-memory job store
+func NewMemoryJobStore() *MemoryJobStore {
+	return &MemoryJobStore{
+		jobs: map[string]Job{},
+	}
+}
+
+// Save stores a copy of the job
+func (c *MemoryJobStore) Save(ctx context.Context, job *Job) error {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	c.jobs[job.ID] = *job
+
+	// evict expired jobs at most twice per retention period
+	if now := time.Now(); c.Retention > 0 && now.Sub(c.swept) >= c.Retention/2 {
+		c.swept = now
+		for id, job := range c.jobs {
+			if c.expired(job, now) {
+				delete(c.jobs, id)
+			}
+		}
+	}
+	return nil
+}
+
+// Get gets a copy of a job
+func (c *MemoryJobStore) Get(ctx context.Context, id string) (*Job, error) {
+	c.mx.RLock()
+	defer c.mx.RUnlock()
+
+	job, ok := c.jobs[id]
+	if !ok || c.expired(job, time.Now()) {
+		return nil, ErrJobNotFound
+	}
+	return &job, nil
+}
+
+// determines if a finished job is past the retention period
+func (c *MemoryJobStore) expired(job Job, now time.Time) bool {
+	return c.Retention > 0 && job.Status.Done() && now.Sub(job.UpdatedAt) > c.Retention
+}
+
+type jobKey struct{}
+
+// a running job
+type runningJob struct {
+	mx      sync.Mutex
+	job     Job
+	cancel  context.CancelFunc
+	manager *JobManager
+}
+
+// JobManager runs @asyncJob mutations in the background, stores their state
+// and publishes updates
+type JobManager struct {
+	Store   JobStore
+	PubSub  PubSub
+	Timeout time.Duration // maximum job duration, unlimited when zero
+
+	mx      sync.Mutex
+	running map[string]*runningJob
+}
+
+// NewJobManager creates a new job manager with an in-memory store and pubsub
+func NewJobManager() *JobManager {
+	return &JobManager{
+		Store:   NewMemoryJobStore(),
+		PubSub:  NewPubSub(),
+		running: map[string]*runningJob{},
+	}
+}
+
+// jobTopic the pubsub topic of job updates
+func jobTopic(id string) string {
+	return "job:" + id
+}
+
+// Start starts a job and returns its initial state. The job context keeps the
+// values of ctx but is not cancelled with it, request scoped services
+// should not be used after the request has finished
+func (m *JobManager) Start(ctx context.Context, field string, fn func(ctx context.Context) (interface{}, error)) (*Job, error) {
+	now := time.Now().UTC()
+	running := &runningJob{
+		manager: m,
+		job: Job{
+			ID:        uuid.New().String(),
+			Field:     field,
+			Status:    JobPending,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+	}
+
+	var (
+		jobCtx context.Context
+		cancel context.CancelFunc
+	)
+	if m.Timeout > 0 {
+		jobCtx, cancel = context.WithTimeout(context.WithoutCancel(contextOrBackground(ctx)), m.Timeout)
+	} else {
+		jobCtx, cancel = context.WithCancel(context.WithoutCancel(contextOrBackground(ctx)))
+	}
+	running.cancel = cancel
+
+	if err := m.Store.Save(ctx, &running.job); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	m.mx.Lock()
+	if m.running == nil {
+		m.running = map[string]*runningJob{}
+	}
+	m.running[running.job.ID] = running
+	m.mx.Unlock()
+
+	job := running.job
+	go m.run(context.WithValue(jobCtx, jobKey{}, running), running, fn)
+
+	return &job, nil
+}
+
+func (m *JobManager) run(ctx context.Context, running *runningJob, fn func(ctx context.Context) (interface{}, error)) {
+	defer func() {
+		running.cancel()
+		m.mx.Lock()
+		delete(m.running, running.job.ID)
+		m.mx.Unlock()
+	}()
+
+	m.update(ctx, running, func(job *Job) {
+		job.Status = JobRunning
+	})
+
+	result, err := func() (result interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("job panicked: %v", r)
+			}
+		}()
+		return fn(ctx)
+	}()
+
+	m.update(ctx, running, func(job *Job) {
+		switch {
+		case errors.Is(ctx.Err(), context.Canceled):
+			job.Status = JobCancelled
+		case err != nil:
+			job.Status = JobFailed
+			job.Error = errorMessage(err)
+		case ctx.Err() != nil:
+			job.Status = JobFailed
+			job.Error = errorMessage(ctx.Err())
+		default:
+			job.Status = JobSucceeded
+			job.Progress = 1
+			job.Result = result
+		}
+	})
+}
+
+// the message of a job error
+func errorMessage(err error) *string {
+	message := err.Error()
+	return &message
+}
+
+// updates the job state, stores and publishes it. Finished jobs are not updated
+func (m *JobManager) update(ctx context.Context, running *runningJob, fn func(job *Job)) error {
+	running.mx.Lock()
+	if running.job.Status.Done() {
+		running.mx.Unlock()
+		return nil
+	}
+	fn(&running.job)
+	running.job.UpdatedAt = time.Now().UTC()
+	job := running.job
+	running.mx.Unlock()
+
+	// use a context that is not cancelled so the final state is stored
+	ctx = context.WithoutCancel(ctx)
+	if err := m.Store.Save(ctx, &job); err != nil {
+		return err
+	}
+	if m.PubSub != nil {
+		return m.PubSub.Publish(ctx, jobTopic(job.ID), &job)
+	}
+	return nil
+}
+
+// Get gets a job
+func (m *JobManager) Get(ctx context.Context, id string) (*Job, error) {
+	return m.Store.Get(ctx, id)
+}
+
+// Cancel cancels a running job, finished jobs are returned unchanged
+func (m *JobManager) Cancel(ctx context.Context, id string) (*Job, error) {
+	m.mx.Lock()
+	running, ok := m.running[id]
+	m.mx.Unlock()
+
+	if ok {
+		running.cancel()
+		m.update(ctx, running, func(job *Job) {
+			job.Status = JobCancelled
+		})
+	}
+
+	return m.Store.Get(ctx, id)
+}
+
+// Subscribe sends the current state of a job followed by each update until the
+// job has finished or the context is done. When the updates end early the job
+// is read from the Store again so a finished job always sends its final state
+func (m *JobManager) Subscribe(ctx context.Context, id string) (<-chan *Job, error) {
+	if m.PubSub == nil {
+		return nil, fmt.Errorf("job updates require a PubSub")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	updates, err := m.PubSub.Subscribe(ctx, jobTopic(id))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	job, err := m.Get(ctx, id)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	ch := make(chan *Job, 1)
+	go func() {
+		defer cancel()
+		defer close(ch)
+
+		ch <- job
+		if job.Status.Done() {
+			return
+		}
+
+		for update := range updates {
+			job, ok := update.(*Job)
+			if !ok {
+				continue
+			}
+			select {
+			case ch <- job:
+			case <-ctx.Done():
+				return
+			}
+			if job.Status.Done() {
+				return
+			}
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+		if job, err := m.Get(ctx, id); err == nil && job.Status.Done() {
+			select {
+			case ch <- job:
+			case <-ctx.Done():
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// ReportJobProgress reports the progress between 0 and 1 of the job running
+// with ctx. Calls outside of a job are ignored
+func ReportJobProgress(ctx context.Context, progress float64) {
+	if ctx == nil {
+		return
+	}
+
+	running, ok := ctx.Value(jobKey{}).(*runningJob)
+	if !ok {
+		return
+	}
+
+	if progress < 0 {
+		progress = 0
+	} else if progress > 1 {
+		progress = 1
+	}
+
+	running.manager.update(ctx, running, func(job *Job) {
+		job.Progress = progress
+	})
+}
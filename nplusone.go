@@ -0,0 +1,239 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+)
+
+// NPlusOneExtensionName the name of the n+1 detector extension in responses
+const NPlusOneExtensionName = "nPlusOne"
+
+type nPlusOneKey struct{}
+
+type nPlusOneFrameKey struct{}
+
+// NPlusOneLogger receives the warnings of the n+1 detector
+type NPlusOneLogger interface {
+	Warnf(format string, data ...interface{})
+}
+
+// NPlusOneDetector is a development graphql.Extension that counts the resolver
+// invocations of each type.field and the data source calls reported with
+// ReportDataSourceCall during each invocation. Fields resolved once per list
+// item that issue their own fetches are reported as warnings in the response
+// extensions and to the Logger
+type NPlusOneDetector struct {
+	// Threshold is the number of per item invocations issuing fetches
+	// before a field is reported, defaults to 2
+	Threshold int
+	// Logger receives a warning for each reported field, optional
+	Logger NPlusOneLogger
+}
+
+// NPlusOneFieldStats resolver statistics for a single type.field
+type NPlusOneFieldStats struct {
+	Field                   string `json:"field"`
+	Invocations             int    `json:"invocations"`
+	ListItemInvocations     int    `json:"listItemInvocations"`
+	DataSourceCalls         int    `json:"dataSourceCalls"`
+	FetchingItemInvocations int    `json:"fetchingItemInvocations"`
+}
+
+// NPlusOneResult the result added to the response extensions
+type NPlusOneResult struct {
+	Warnings []string              `json:"warnings"`
+	Fields   []*NPlusOneFieldStats `json:"fields"`
+}
+
+// per request state
+type nPlusOneState struct {
+	mx     sync.Mutex
+	fields map[string]*NPlusOneFieldStats
+}
+
+// a single resolver invocation, carried in the resolver context so calls
+// from thunks and concurrent resolvers are credited to their own field
+type nPlusOneFrame struct {
+	state    *nPlusOneState
+	stats    *NPlusOneFieldStats
+	listItem bool
+	fetched  bool
+}
+
+// the resolver context of an invocation. graphql-go keeps the context
+// returned by ResolveFieldDidStart for the fields resolved after it, so the
+// frame of the previous invocation is replaced instead of wrapped to keep the
+// context from growing with each resolved field
+type nPlusOneContext struct {
+	context.Context
+	frame *nPlusOneFrame
+}
+
+// Value gets the frame of the invocation or a value of the parent context
+func (c *nPlusOneContext) Value(key interface{}) interface{} {
+	if key == (nPlusOneFrameKey{}) {
+		return c.frame
+	}
+	return c.Context.Value(key)
+}
+
+// NewNPlusOneDetector creates a new n+1 detector
+func NewNPlusOneDetector() *NPlusOneDetector {
+	return &NPlusOneDetector{
+		Threshold: 2,
+	}
+}
+
+// ReportDataSourceCall reports a call to a data source such as a database
+// query or http request from the resolver currently executing with ctx.
+// Calls are ignored when the n+1 detector is not enabled
+func ReportDataSourceCall(ctx context.Context) {
+	if ctx == nil {
+		return
+	}
+
+	frame, ok := ctx.Value(nPlusOneFrameKey{}).(*nPlusOneFrame)
+	if !ok {
+		return
+	}
+
+	frame.state.mx.Lock()
+	defer frame.state.mx.Unlock()
+
+	frame.stats.DataSourceCalls++
+	if frame.listItem && !frame.fetched {
+		frame.fetched = true
+		frame.stats.FetchingItemInvocations++
+	}
+}
+
+// Init initializes the per request state
+func (c *NPlusOneDetector) Init(ctx context.Context, p *graphql.Params) context.Context {
+	return context.WithValue(contextOrBackground(ctx), nPlusOneKey{}, &nPlusOneState{
+		fields: map[string]*NPlusOneFieldStats{},
+	})
+}
+
+// Name returns the extension name
+func (c *NPlusOneDetector) Name() string {
+	return NPlusOneExtensionName
+}
+
+// ParseDidStart is not used
+func (c *NPlusOneDetector) ParseDidStart(ctx context.Context) (context.Context, graphql.ParseFinishFunc) {
+	return ctx, func(err error) {}
+}
+
+// ValidationDidStart is not used
+func (c *NPlusOneDetector) ValidationDidStart(ctx context.Context) (context.Context, graphql.ValidationFinishFunc) {
+	return ctx, func(errs []gqlerrors.FormattedError) {}
+}
+
+// ExecutionDidStart is not used
+func (c *NPlusOneDetector) ExecutionDidStart(ctx context.Context) (context.Context, graphql.ExecutionFinishFunc) {
+	return ctx, func(r *graphql.Result) {}
+}
+
+// ResolveFieldDidStart tracks the resolver invocation. The invocation is added
+// to the resolver context so data source calls are attributed to it, including
+// calls made after the resolver has returned a thunk. Extensions that wrap the
+// context after the detector still add a layer for each resolved field
+func (c *NPlusOneDetector) ResolveFieldDidStart(ctx context.Context, info *graphql.ResolveInfo) (context.Context, graphql.ResolveFieldFinishFunc) {
+	state, ok := ctx.Value(nPlusOneKey{}).(*nPlusOneState)
+	if !ok {
+		return ctx, func(v interface{}, err error) {}
+	}
+
+	field := fmt.Sprintf("%s.%s", info.ParentType.Name(), info.FieldName)
+	listItem := isListItemPath(info.Path)
+
+	state.mx.Lock()
+	stats, ok := state.fields[field]
+	if !ok {
+		stats = &NPlusOneFieldStats{Field: field}
+		state.fields[field] = stats
+	}
+	stats.Invocations++
+	if listItem {
+		stats.ListItemInvocations++
+	}
+	state.mx.Unlock()
+
+	if previous, ok := ctx.(*nPlusOneContext); ok {
+		ctx = previous.Context
+	}
+
+	return &nPlusOneContext{
+		Context: ctx,
+		frame: &nPlusOneFrame{
+			state:    state,
+			stats:    stats,
+			listItem: listItem,
+		},
+	}, func(v interface{}, err error) {}
+}
+
+// HasResult always adds the statistics to the response
+func (c *NPlusOneDetector) HasResult() bool {
+	return true
+}
+
+// GetResult gets the statistics and warnings for the request
+func (c *NPlusOneDetector) GetResult(ctx context.Context) interface{} {
+	state, ok := ctx.Value(nPlusOneKey{}).(*nPlusOneState)
+	if !ok {
+		return nil
+	}
+
+	threshold := c.Threshold
+	if threshold < 1 {
+		threshold = 2
+	}
+
+	state.mx.Lock()
+	defer state.mx.Unlock()
+
+	result := &NPlusOneResult{
+		Warnings: []string{},
+		Fields:   []*NPlusOneFieldStats{},
+	}
+
+	for _, stats := range state.fields {
+		result.Fields = append(result.Fields, stats)
+	}
+	sort.Slice(result.Fields, func(i, j int) bool {
+		return result.Fields[i].Field < result.Fields[j].Field
+	})
+
+	for _, stats := range result.Fields {
+		if stats.FetchingItemInvocations < threshold {
+			continue
+		}
+
+		warning := fmt.Sprintf(
+			"possible N+1: %s was resolved %d times for list items making %d data source calls, consider batching",
+			stats.Field, stats.FetchingItemInvocations, stats.DataSourceCalls,
+		)
+		result.Warnings = append(result.Warnings, warning)
+		if c.Logger != nil {
+			c.Logger.Warnf("%s", warning)
+		}
+	}
+
+	return result
+}
+
+// determines if a path is within a list item
+func isListItemPath(path *graphql.ResponsePath) bool {
+	for p := path; p != nil; p = p.Prev {
+		if _, ok := p.Key.(int); ok {
+			return true
+		}
+	}
+	return false
+}
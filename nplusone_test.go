@@ -0,0 +1,173 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+)
+
+func TestNPlusOneDetector(t *testing.T) {
+	schema, err := MakeExecutableSchema(ExecutableSchema{
+		TypeDefs: `
+type Author {
+	name: String
+}
+
+type Post {
+	title: String
+	author: Author
+}
+
+type Query {
+	posts: [Post]
+}`,
+		Extensions: []graphql.Extension{NewNPlusOneDetector()},
+		Resolvers: ResolverMap{
+			"Query": &ObjectResolver{
+				Fields: FieldResolveMap{
+					"posts": &FieldResolve{
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							ReportDataSourceCall(p.Context)
+							return []map[string]interface{}{{"title": "a"}, {"title": "b"}, {"title": "c"}}, nil
+						},
+					},
+				},
+			},
+			"Post": &ObjectResolver{
+				Fields: FieldResolveMap{
+					"author": &FieldResolve{
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							ReportDataSourceCall(p.Context)
+							return map[string]interface{}{"name": "author"}, nil
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: `{ posts { title author { name } } }`,
+	})
+	if r.HasErrors() {
+		t.Fatal(r.Errors)
+	}
+
+	result, ok := r.Extensions[NPlusOneExtensionName].(*NPlusOneResult)
+	if !ok {
+		t.Fatalf("expected n+1 result in extensions, got %v", r.Extensions)
+	}
+
+	if len(result.Warnings) != 1 {
+		t.Fatalf("expected a single warning, got %v", result.Warnings)
+	}
+
+	for _, stats := range result.Fields {
+		switch stats.Field {
+		case "Post.author":
+			if stats.Invocations != 3 || stats.DataSourceCalls != 3 || stats.FetchingItemInvocations != 3 {
+				t.Errorf("unexpected stats for Post.author: %+v", stats)
+			}
+		case "Query.posts":
+			if stats.FetchingItemInvocations != 0 {
+				t.Errorf("expected Query.posts not to be a list item fetch: %+v", stats)
+			}
+		}
+	}
+}
+
+func TestNPlusOneDetectorThunks(t *testing.T) {
+	schema, err := MakeExecutableSchema(ExecutableSchema{
+		TypeDefs: `
+type Author {
+	name: String
+}
+
+type Post {
+	author: Author
+	likes: Int
+}
+
+type Query {
+	posts: [Post]
+}`,
+		Extensions: []graphql.Extension{NewNPlusOneDetector()},
+		Resolvers: ResolverMap{
+			"Query": &ObjectResolver{
+				Fields: FieldResolveMap{
+					"posts": &FieldResolve{
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							return []map[string]interface{}{{"likes": 1}, {"likes": 2}}, nil
+						},
+					},
+				},
+			},
+			"Post": &ObjectResolver{
+				Fields: FieldResolveMap{
+					// the fetch runs in a thunk after the other fields have started
+					"author": &FieldResolve{
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							return func() (interface{}, error) {
+								ReportDataSourceCall(p.Context)
+								return map[string]interface{}{"name": "author"}, nil
+							}, nil
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: `{ posts { author { name } likes } }`,
+	})
+	if r.HasErrors() {
+		t.Fatal(r.Errors)
+	}
+
+	result := r.Extensions[NPlusOneExtensionName].(*NPlusOneResult)
+	for _, stats := range result.Fields {
+		switch stats.Field {
+		case "Post.author":
+			if stats.DataSourceCalls != 2 || stats.FetchingItemInvocations != 2 {
+				t.Errorf("expected the thunk calls to be credited to Post.author: %+v", stats)
+			}
+		default:
+			if stats.DataSourceCalls != 0 {
+				t.Errorf("expected no data source calls for %s: %+v", stats.Field, stats)
+			}
+		}
+	}
+}
+
+func TestNPlusOneDetectorContext(t *testing.T) {
+	detector := NewNPlusOneDetector()
+	ctx := detector.Init(context.Background(), &graphql.Params{})
+	info := &graphql.ResolveInfo{
+		FieldName:  "title",
+		ParentType: graphql.NewObject(graphql.ObjectConfig{Name: "Post", Fields: graphql.Fields{}}),
+	}
+
+	// the executor passes the previous field context to the next field
+	first, _ := detector.ResolveFieldDidStart(ctx, info)
+	second, _ := detector.ResolveFieldDidStart(first, info)
+	if parent := second.(*nPlusOneContext).Context; parent != ctx {
+		t.Error("expected the previous invocation to be replaced instead of wrapped")
+	}
+
+	ReportDataSourceCall(first)
+	ReportDataSourceCall(second)
+	stats := first.Value(nPlusOneFrameKey{}).(*nPlusOneFrame).stats
+	if stats.Invocations != 2 || stats.DataSourceCalls != 2 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
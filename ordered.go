@@ -0,0 +1,175 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// OrderedMap a response object encoded to JSON with its keys in order
+type OrderedMap struct {
+	Keys   []string
+	Values map[string]interface{}
+}
+
+// MarshalJSON encodes the map with its keys in order
+func (m *OrderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range m.Keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(m.Values[key])
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// OrderResultData converts the objects of result data to OrderedMaps with
+// keys in the order they are selected by the operation. Keys not found in
+// the selection sets are added last in alphabetical order
+func OrderResultData(document *ast.Document, operationName string, data interface{}) interface{} {
+	if document == nil {
+		return data
+	}
+
+	var operation *ast.OperationDefinition
+	fragments := map[string]*ast.FragmentDefinition{}
+	for _, def := range document.Definitions {
+		switch d := def.(type) {
+		case *ast.OperationDefinition:
+			if operation == nil && (operationName == "" || (d.Name != nil && d.Name.Value == operationName)) {
+				operation = d
+			}
+		case *ast.FragmentDefinition:
+			fragments[d.Name.Value] = d
+		}
+	}
+
+	if operation == nil {
+		return data
+	}
+
+	return orderValue(data, []*ast.SelectionSet{operation.SelectionSet}, fragments)
+}
+
+// OrderQueryResultData parses the query and orders the result data, the
+// data is returned unchanged when the query cannot be parsed
+func OrderQueryResultData(query, operationName string, data interface{}) interface{} {
+	document, err := parser.Parse(parser.ParseParams{
+		Source: &source.Source{
+			Body: []byte(query),
+			Name: "GraphQL request",
+		},
+	})
+	if err != nil {
+		return data
+	}
+	return OrderResultData(document, operationName, data)
+}
+
+// orders a value using the selection sets that selected it
+func orderValue(value interface{}, sets []*ast.SelectionSet, fragments map[string]*ast.FragmentDefinition) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys, subSets := collectResponseKeys(sets, fragments, map[string]bool{})
+
+		ordered := &OrderedMap{
+			Keys:   make([]string, 0, len(v)),
+			Values: make(map[string]interface{}, len(v)),
+		}
+		for _, key := range keys {
+			if item, ok := v[key]; ok {
+				ordered.Keys = append(ordered.Keys, key)
+				ordered.Values[key] = orderValue(item, subSets[key], fragments)
+			}
+		}
+
+		if len(ordered.Keys) < len(v) {
+			remaining := []string{}
+			for key := range v {
+				if _, ok := ordered.Values[key]; !ok {
+					remaining = append(remaining, key)
+				}
+			}
+			sort.Strings(remaining)
+			for _, key := range remaining {
+				ordered.Keys = append(ordered.Keys, key)
+				ordered.Values[key] = v[key]
+			}
+		}
+		return ordered
+
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = orderValue(item, sets, fragments)
+		}
+		return items
+	}
+
+	return value
+}
+
+// collects the response keys of selection sets in order along with the
+// selection sets of each key, fragments are expanded in place
+func collectResponseKeys(sets []*ast.SelectionSet, fragments map[string]*ast.FragmentDefinition, visited map[string]bool) ([]string, map[string][]*ast.SelectionSet) {
+	keys := []string{}
+	subSets := map[string][]*ast.SelectionSet{}
+
+	var collect func(set *ast.SelectionSet)
+	collect = func(set *ast.SelectionSet) {
+		if set == nil {
+			return
+		}
+
+		for _, selection := range set.Selections {
+			switch sel := selection.(type) {
+			case *ast.Field:
+				key := sel.Name.Value
+				if sel.Alias != nil {
+					key = sel.Alias.Value
+				}
+				if _, ok := subSets[key]; !ok {
+					keys = append(keys, key)
+					subSets[key] = []*ast.SelectionSet{}
+				}
+				if sel.SelectionSet != nil {
+					subSets[key] = append(subSets[key], sel.SelectionSet)
+				}
+			case *ast.InlineFragment:
+				collect(sel.SelectionSet)
+			case *ast.FragmentSpread:
+				name := sel.Name.Value
+				if fragment, ok := fragments[name]; ok && !visited[name] {
+					visited[name] = true
+					collect(fragment.SelectionSet)
+					delete(visited, name)
+				}
+			}
+		}
+	}
+
+	for _, set := range sets {
+		collect(set)
+	}
+
+	return keys, subSets
+}
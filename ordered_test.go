@@ -0,0 +1,82 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+)
+
+func TestOrderResultData(t *testing.T) {
+	schema, err := MakeExecutableSchema(ExecutableSchema{
+		TypeDefs: `
+type User {
+	id: ID!
+	name: String
+	email: String
+	friends: [User]
+}
+
+type Query {
+	viewer: User
+	version: String
+}`,
+		Resolvers: ResolverMap{
+			"Query": &ObjectResolver{
+				Fields: FieldResolveMap{
+					"viewer": &FieldResolve{
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							return map[string]interface{}{
+								"id":    "1",
+								"name":  "zed",
+								"email": "zed@example.com",
+								"friends": []interface{}{
+									map[string]interface{}{"id": "2", "name": "amy", "email": "amy@example.com"},
+								},
+							}, nil
+						},
+					},
+					"version": &FieldResolve{
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							return "1.0", nil
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query := `
+query Viewer {
+	version
+	viewer {
+		name
+		...UserFields
+		friends { email name }
+	}
+}
+
+fragment UserFields on User {
+	zid: id
+	name
+	... on User { email }
+}`
+
+	r := graphql.Do(graphql.Params{Schema: schema, RequestString: query, OperationName: "Viewer"})
+	if r.HasErrors() {
+		t.Fatal(r.Errors)
+	}
+
+	b, err := json.Marshal(OrderQueryResultData(query, "Viewer", r.Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"version":"1.0","viewer":{"name":"zed","zid":"1","email":"zed@example.com","friends":[{"email":"amy@example.com","name":"amy"}]}}`
+	if string(b) != expected {
+		t.Errorf("expected %s, got %s", expected, b)
+	}
+}
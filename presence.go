@@ -0,0 +1,199 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+type presenceKey struct{}
+
+// records the raw request variables, coerced variables omit null values
+type presenceExtension struct{}
+
+// ProvidedArguments gets the field arguments and input object fields that were
+// explicitly provided, including through variables. Omitted arguments and
+// fields are not included, explicit nulls are included with a nil value and
+// provided values are the coerced values from the resolve params.
+// Requires the schema to be made with ArgumentPresence enabled to detect
+// explicit nulls in variables.
+//
+// graphql-go does not parse a literal null, updateUser(input: { bio: null })
+// is a syntax error, so only variables can provide an explicit null, e.g.
+// updateUser(input: { bio: $bio }) with {"bio": null}
+func ProvidedArguments(p graphql.ResolveParams) map[string]interface{} {
+	provided := map[string]interface{}{}
+	if len(p.Info.FieldASTs) == 0 {
+		return provided
+	}
+
+	variables := p.Info.VariableValues
+	if p.Context != nil {
+		if raw, ok := p.Context.Value(presenceKey{}).(map[string]interface{}); ok {
+			variables = raw
+		}
+	}
+
+	for _, arg := range p.Info.FieldASTs[0].Arguments {
+		if arg.Name == nil {
+			continue
+		}
+		if tree, ok := astPresence(arg.Value, variables); ok {
+			provided[arg.Name.Value] = mergePresence(tree, p.Args[arg.Name.Value])
+		}
+	}
+
+	return provided
+}
+
+// IsProvided determines if an argument or a nested input field was explicitly
+// provided, e.g. IsProvided(p, "input", "bio") for updateUser(input: { bio: $bio })
+func IsProvided(p graphql.ResolveParams, path ...string) bool {
+	if len(path) == 0 {
+		return false
+	}
+
+	var value interface{} = ProvidedArguments(p)
+	for _, key := range path {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if value, ok = m[key]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// gets the presence tree of an argument value, objects are maps of the
+// provided fields, lists are slices and all other values are leaves
+func astPresence(value ast.Value, variables map[string]interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case *ast.Variable:
+		raw, ok := variables[v.Name.Value]
+		if !ok {
+			return nil, false
+		}
+		return rawPresence(raw), true
+
+	case *ast.ObjectValue:
+		tree := map[string]interface{}{}
+		for _, field := range v.Fields {
+			if sub, ok := astPresence(field.Value, variables); ok {
+				tree[field.Name.Value] = sub
+			}
+		}
+		return tree, true
+
+	case *ast.ListValue:
+		tree := make([]interface{}, len(v.Values))
+		for i, item := range v.Values {
+			tree[i], _ = astPresence(item, variables)
+		}
+		return tree, true
+	}
+
+	return true, value != nil
+}
+
+// gets the presence tree of a raw variable value
+func rawPresence(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		tree := map[string]interface{}{}
+		for key, item := range v {
+			tree[key] = rawPresence(item)
+		}
+		return tree
+	case []interface{}:
+		tree := make([]interface{}, len(v))
+		for i, item := range v {
+			tree[i] = rawPresence(item)
+		}
+		return tree
+	case nil:
+		return nil
+	}
+	return true
+}
+
+// merges a presence tree with the coerced value, provided keys missing from
+// the coerced value were explicitly null
+func mergePresence(tree, coerced interface{}) interface{} {
+	if coerced == nil {
+		return nil
+	}
+
+	switch t := tree.(type) {
+	case map[string]interface{}:
+		values, ok := coerced.(map[string]interface{})
+		if !ok {
+			return coerced
+		}
+		merged := map[string]interface{}{}
+		for key, sub := range t {
+			merged[key] = mergePresence(sub, values[key])
+		}
+		return merged
+
+	case []interface{}:
+		values, ok := coerced.([]interface{})
+		if !ok || len(values) != len(t) {
+			return coerced
+		}
+		merged := make([]interface{}, len(t))
+		for i, sub := range t {
+			merged[i] = mergePresence(sub, values[i])
+		}
+		return merged
+	}
+
+	return coerced
+}
+
+// Init stores the raw request variables
+func (c *presenceExtension) Init(ctx context.Context, p *graphql.Params) context.Context {
+	variables := p.VariableValues
+	if variables == nil {
+		variables = map[string]interface{}{}
+	}
+	return context.WithValue(contextOrBackground(ctx), presenceKey{}, variables)
+}
+
+// Name returns the extension name
+func (c *presenceExtension) Name() string {
+	return "argumentPresence"
+}
+
+// ParseDidStart is not used
+func (c *presenceExtension) ParseDidStart(ctx context.Context) (context.Context, graphql.ParseFinishFunc) {
+	return ctx, func(err error) {}
+}
+
+// ValidationDidStart is not used
+func (c *presenceExtension) ValidationDidStart(ctx context.Context) (context.Context, graphql.ValidationFinishFunc) {
+	return ctx, func(errs []gqlerrors.FormattedError) {}
+}
+
+// ExecutionDidStart is not used
+func (c *presenceExtension) ExecutionDidStart(ctx context.Context) (context.Context, graphql.ExecutionFinishFunc) {
+	return ctx, func(r *graphql.Result) {}
+}
+
+// ResolveFieldDidStart is not used
+func (c *presenceExtension) ResolveFieldDidStart(ctx context.Context, info *graphql.ResolveInfo) (context.Context, graphql.ResolveFieldFinishFunc) {
+	return ctx, func(v interface{}, err error) {}
+}
+
+// HasResult is always false
+func (c *presenceExtension) HasResult() bool {
+	return false
+}
+
+// GetResult is not used
+func (c *presenceExtension) GetResult(ctx context.Context) interface{} {
+	return nil
+}
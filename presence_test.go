@@ -0,0 +1,122 @@
+package tools
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+)
+
+func TestProvidedArguments(t *testing.T) {
+	var provided map[string]interface{}
+	var bioProvided, nameProvided bool
+
+	schema, err := MakeExecutableSchema(ExecutableSchema{
+		TypeDefs: `
+input UserInput {
+	name: String
+	bio: String
+	tags: [String]
+}
+
+type Query {
+	version: String
+}
+
+type Mutation {
+	updateUser(id: ID!, input: UserInput, notify: Boolean = true): Boolean
+}`,
+		ArgumentPresence: true,
+		Resolvers: ResolverMap{
+			"Mutation": &ObjectResolver{
+				Fields: FieldResolveMap{
+					"updateUser": &FieldResolve{
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							provided = ProvidedArguments(p)
+							bioProvided = IsProvided(p, "input", "bio")
+							nameProvided = IsProvided(p, "input", "name")
+							return true, nil
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query     string
+		variables map[string]interface{}
+		expected  map[string]interface{}
+		bio, name bool
+	}{
+		{
+			query:     `mutation ($input: UserInput) { updateUser(id: "1", input: $input) }`,
+			variables: map[string]interface{}{"input": map[string]interface{}{"bio": nil, "name": "amy"}},
+			expected:  map[string]interface{}{"id": "1", "input": map[string]interface{}{"bio": nil, "name": "amy"}},
+			bio:       true,
+			name:      true,
+		},
+		{
+			query:    `mutation ($bio: String) { updateUser(id: "1", input: { bio: $bio, tags: ["a"] }) }`,
+			expected: map[string]interface{}{"id": "1", "input": map[string]interface{}{"tags": []interface{}{"a"}}},
+		},
+		{
+			query:     `mutation ($bio: String) { updateUser(id: "1", input: { bio: $bio }) }`,
+			variables: map[string]interface{}{"bio": nil},
+			expected:  map[string]interface{}{"id": "1", "input": map[string]interface{}{"bio": nil}},
+			bio:       true,
+		},
+	}
+
+	for _, test := range tests {
+		r := graphql.Do(graphql.Params{
+			Schema:         schema,
+			RequestString:  test.query,
+			VariableValues: test.variables,
+		})
+		if r.HasErrors() {
+			t.Fatal(r.Errors)
+		}
+
+		if !reflect.DeepEqual(provided, test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.query, test.expected, provided)
+		}
+		if bioProvided != test.bio || nameProvided != test.name {
+			t.Errorf("%s: expected bio %v and name %v to be provided, got %v and %v", test.query, test.bio, test.name, bioProvided, nameProvided)
+		}
+	}
+}
+
+func TestProvidedArgumentsLiteralNull(t *testing.T) {
+	schema, err := MakeExecutableSchema(ExecutableSchema{
+		TypeDefs: `
+input UserInput {
+	bio: String
+}
+
+type Query {
+	version: String
+}
+
+type Mutation {
+	updateUser(input: UserInput): Boolean
+}`,
+		ArgumentPresence: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// graphql-go does not parse a literal null, explicit nulls require variables
+	r := graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: `mutation { updateUser(input: { bio: null }) }`,
+	})
+	if !r.HasErrors() || !strings.Contains(r.Errors[0].Message, "Syntax Error") {
+		t.Errorf("expected a syntax error for a literal null, got %v", r.Errors)
+	}
+}
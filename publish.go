@@ -0,0 +1,274 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+const (
+	directivePublish   = "publish"
+	directiveSubscribe = "subscribe"
+)
+
+// PublishDirective publishes the result of a mutation field to a topic
+var PublishDirective = graphql.NewDirective(graphql.DirectiveConfig{
+	Name:        directivePublish,
+	Description: "Publishes the resolved result of a mutation to a PubSub topic. The topic may reference arguments, e.g. \"userUpdated:{id}\"",
+	Locations:   []string{graphql.DirectiveLocationFieldDefinition},
+	Args: graphql.FieldConfigArgument{
+		"topic": &graphql.ArgumentConfig{
+			Type: graphql.NewNonNull(graphql.String),
+		},
+	},
+})
+
+// SubscribeDirective subscribes a subscription field to a topic
+var SubscribeDirective = graphql.NewDirective(graphql.DirectiveConfig{
+	Name:        directiveSubscribe,
+	Description: "Subscribes to a PubSub topic and sends each payload. The topic may reference arguments. Each filter entry is an argument name compared to the payload field of the same name, or \"path.to.field:argument\". Filters on arguments that are not provided are ignored",
+	Locations:   []string{graphql.DirectiveLocationFieldDefinition},
+	Args: graphql.FieldConfigArgument{
+		"topic": &graphql.ArgumentConfig{
+			Type: graphql.NewNonNull(graphql.String),
+		},
+		"filter": &graphql.ArgumentConfig{
+			Type: graphql.NewList(graphql.NewNonNull(graphql.String)),
+		},
+	},
+})
+
+var topicArgRx = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// publishes mutation results
+func (c *registry) publishVisitor() *SchemaDirectiveVisitor {
+	return &SchemaDirectiveVisitor{
+		VisitFieldDefinition: func(p VisitFieldDefinitionParams) error {
+			mutationName := getRootTypeName(c.document, ast.OperationTypeMutation, DefaultRootMutationName)
+			if p.ParentName != mutationName {
+				return fmt.Errorf("@%s can only be applied to fields of %q, found on %s.%s", directivePublish, mutationName, p.ParentName, p.Config.Name)
+			}
+
+			if c.pubsub == nil {
+				return fmt.Errorf("@%s on %s.%s requires a PubSub", directivePublish, p.ParentName, p.Config.Name)
+			}
+
+			topic, _ := p.Args["topic"].(string)
+			resolve := p.Config.Resolve
+			if resolve == nil {
+				resolve = graphql.DefaultResolveFn
+			}
+
+			pubsub := c.pubsub
+			p.Config.Resolve = func(rp graphql.ResolveParams) (interface{}, error) {
+				result, err := resolve(rp)
+				if err != nil {
+					return result, err
+				}
+
+				expanded, err := ExpandTopic(topic, rp.Args)
+				if err != nil {
+					return result, err
+				}
+
+				if err := pubsub.Publish(contextOrBackground(rp.Context), expanded, result); err != nil {
+					return result, fmt.Errorf("failed to publish to %q: %v", expanded, err)
+				}
+
+				return result, nil
+			}
+
+			return nil
+		},
+	}
+}
+
+// generates the Subscribe and Resolve functions of subscription fields
+func (c *registry) subscribeVisitor() *SchemaDirectiveVisitor {
+	return &SchemaDirectiveVisitor{
+		VisitFieldDefinition: func(p VisitFieldDefinitionParams) error {
+			subscriptionName := getRootTypeName(c.document, ast.OperationTypeSubscription, DefaultRootSubscriptionName)
+			if p.ParentName != subscriptionName {
+				return fmt.Errorf("@%s can only be applied to fields of %q, found on %s.%s", directiveSubscribe, subscriptionName, p.ParentName, p.Config.Name)
+			}
+
+			if c.pubsub == nil {
+				return fmt.Errorf("@%s on %s.%s requires a PubSub", directiveSubscribe, p.ParentName, p.Config.Name)
+			}
+
+			topic, _ := p.Args["topic"].(string)
+			filters := []subscriptionFilter{}
+			if list, ok := p.Args["filter"].([]interface{}); ok {
+				for _, item := range list {
+					filter, err := parseSubscriptionFilter(fmt.Sprintf("%v", item))
+					if err != nil {
+						return fmt.Errorf("@%s on %s.%s: %v", directiveSubscribe, p.ParentName, p.Config.Name, err)
+					}
+					filters = append(filters, filter)
+				}
+			}
+
+			pubsub := c.pubsub
+			p.Config.Subscribe = func(rp graphql.ResolveParams) (interface{}, error) {
+				expanded, err := ExpandTopic(topic, rp.Args)
+				if err != nil {
+					return nil, err
+				}
+
+				ctx := contextOrBackground(rp.Context)
+				events, err := pubsub.Subscribe(ctx, expanded)
+				if err != nil {
+					return nil, err
+				}
+
+				ch := make(chan interface{})
+				go func() {
+					defer close(ch)
+					for payload := range events {
+						if !matchesSubscriptionFilters(filters, payload, rp.Args) {
+							continue
+						}
+						select {
+						case ch <- payload:
+						case <-ctx.Done():
+							return
+						}
+					}
+				}()
+
+				return ch, nil
+			}
+
+			// the payload is the result unless a resolver was supplied
+			if p.Config.Resolve == nil || isDefaultResolveFn(p.Config.Resolve) {
+				p.Config.Resolve = func(rp graphql.ResolveParams) (interface{}, error) {
+					return rp.Source, nil
+				}
+			}
+
+			return nil
+		},
+	}
+}
+
+// ExpandTopic replaces {argument} placeholders in a topic with argument values,
+// nested input fields can be referenced with a path such as {input.id}
+func ExpandTopic(topic string, args map[string]interface{}) (string, error) {
+	var err error
+	expanded := topicArgRx.ReplaceAllStringFunc(topic, func(match string) string {
+		path := strings.TrimSpace(match[1 : len(match)-1])
+		value, ok := lookupPath(args, strings.Split(path, "."))
+		if !ok || value == nil {
+			err = fmt.Errorf("topic %q references argument %q which was not provided", topic, path)
+			return match
+		}
+		return fmt.Sprintf("%v", value)
+	})
+	return expanded, err
+}
+
+// compares a payload value to an argument value
+type subscriptionFilter struct {
+	path     []string
+	argument string
+}
+
+func parseSubscriptionFilter(filter string) (subscriptionFilter, error) {
+	parts := strings.SplitN(filter, ":", 2)
+	path := strings.TrimSpace(parts[0])
+	argument := path
+	if len(parts) == 2 {
+		argument = strings.TrimSpace(parts[1])
+	}
+
+	if path == "" || argument == "" {
+		return subscriptionFilter{}, fmt.Errorf("invalid filter %q", filter)
+	}
+
+	return subscriptionFilter{
+		path:     strings.Split(path, "."),
+		argument: argument,
+	}, nil
+}
+
+func matchesSubscriptionFilters(filters []subscriptionFilter, payload interface{}, args map[string]interface{}) bool {
+	for _, filter := range filters {
+		expected, ok := args[filter.argument]
+		if !ok || expected == nil {
+			continue
+		}
+
+		actual, ok := lookupPath(payload, filter.path)
+		if !ok || fmt.Sprintf("%v", actual) != fmt.Sprintf("%v", expected) {
+			return false
+		}
+	}
+	return true
+}
+
+// looks up a value by path in maps and structs, struct fields are
+// matched by json tag or case insensitive name
+func lookupPath(value interface{}, path []string) (interface{}, bool) {
+	for _, key := range path {
+		v := reflect.ValueOf(value)
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return nil, false
+			}
+			v = v.Elem()
+		}
+
+		switch v.Kind() {
+		case reflect.Map:
+			if v.Type().Key().Kind() != reflect.String {
+				return nil, false
+			}
+			item := v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key()))
+			if !item.IsValid() {
+				return nil, false
+			}
+			value = item.Interface()
+		case reflect.Struct:
+			field, ok := structFieldByName(v, key)
+			if !ok {
+				return nil, false
+			}
+			value = field.Interface()
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+func structFieldByName(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		tag := strings.Split(field.Tag.Get("json"), ",")[0]
+		if tag == name || (tag == "" && strings.EqualFold(field.Name, name)) {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// determines if a resolve function is the graphql default
+func isDefaultResolveFn(fn graphql.FieldResolveFn) bool {
+	return reflect.ValueOf(fn).Pointer() == reflect.ValueOf(graphql.DefaultResolveFn).Pointer()
+}
+
+func contextOrBackground(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	return ctx
+}
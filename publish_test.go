@@ -0,0 +1,165 @@
+package tools
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/graphql-go/graphql"
+)
+
+func TestPublishSubscribe(t *testing.T) {
+	typeDefs := `
+type Post {
+	id: ID!
+	authorId: ID!
+	title: String!
+}
+
+type Query {
+	posts: [Post]
+}
+
+type Mutation {
+	addPost(authorId: ID!, title: String!): Post @publish(topic: "postAdded:{authorId}")
+}
+
+type Subscription {
+	postAdded(authorId: ID!, title: String): Post @subscribe(topic: "postAdded:{authorId}", filter: ["title"])
+}`
+
+	schema, err := MakeExecutableSchema(ExecutableSchema{
+		TypeDefs: typeDefs,
+		PubSub:   NewPubSub(),
+		Resolvers: ResolverMap{
+			"Mutation": &ObjectResolver{
+				Fields: FieldResolveMap{
+					"addPost": &FieldResolve{
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							return map[string]interface{}{
+								"id":       "1",
+								"authorId": p.Args["authorId"],
+								"title":    p.Args["title"],
+							}, nil
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	results := graphql.Subscribe(graphql.Params{
+		Schema:        schema,
+		Context:       ctx,
+		RequestString: `subscription { postAdded(authorId: "a", title: "match") { id title } }`,
+	})
+
+	// wait for the subscription to be registered
+	time.Sleep(50 * time.Millisecond)
+
+	for _, mutation := range []string{
+		`mutation { addPost(authorId: "b", title: "match") { id } }`,
+		`mutation { addPost(authorId: "a", title: "other") { id } }`,
+		`mutation { addPost(authorId: "a", title: "match") { id } }`,
+	} {
+		if r := graphql.Do(graphql.Params{Schema: schema, RequestString: mutation}); r.HasErrors() {
+			t.Fatal(r.Errors)
+		}
+	}
+
+	select {
+	case r := <-results:
+		if r.HasErrors() {
+			t.Fatal(r.Errors)
+		}
+		post := r.Data.(map[string]interface{})["postAdded"].(map[string]interface{})
+		if post["title"] != "match" {
+			t.Errorf("expected the filtered post, got %v", post)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for subscription result")
+	}
+
+	select {
+	case r := <-results:
+		t.Errorf("expected a single result, got %v", r)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestExpandTopic(t *testing.T) {
+	topic, err := ExpandTopic("user:{input.id}:{kind}", map[string]interface{}{
+		"input": map[string]interface{}{"id": 5},
+		"kind":  "updated",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if topic != "user:5:updated" {
+		t.Errorf("unexpected topic %q", topic)
+	}
+
+	if _, err := ExpandTopic("user:{id}", map[string]interface{}{}); err == nil {
+		t.Error("expected an error for a missing argument")
+	}
+}
+
+func TestMemoryPubSubSlowSubscriber(t *testing.T) {
+	pubsub := NewPubSub()
+	pubsub.BufferSize = 4
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	slow, err := pubsub.Subscribe(ctx, "topic")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const published = 50
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < published; i++ {
+			pubsub.Publish(context.Background(), "topic", i)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected publishing to a slow subscriber not to block")
+	}
+
+	// the oldest payloads are dropped and the subscription stays open
+	var received []interface{}
+	for len(received) < pubsub.BufferSize {
+		select {
+		case payload, ok := <-slow:
+			if !ok {
+				t.Fatal("expected the slow subscription to stay open")
+			}
+			received = append(received, payload)
+		case <-time.After(time.Second):
+			t.Fatalf("expected %d buffered payloads, got %d", pubsub.BufferSize, len(received))
+		}
+	}
+	if last := received[len(received)-1]; last != published-1 {
+		t.Errorf("expected the latest payload %d to be delivered, got %v", published-1, last)
+	}
+
+	pubsub.Publish(context.Background(), "topic", "after")
+	select {
+	case payload := <-slow:
+		if payload != "after" {
+			t.Errorf("unexpected payload %v", payload)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the slow subscriber to keep receiving payloads")
+	}
+}
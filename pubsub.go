@@ -0,0 +1,114 @@
+package tools
+
+import (
+	"context"
+	"sync"
+)
+
+// PubSub publishes payloads to topics and delivers them to subscribers.
+// Subscription channels are closed when the subscribe context is done
+type PubSub interface {
+	Publish(ctx context.Context, topic string, payload interface{}) error
+	Subscribe(ctx context.Context, topic string) (<-chan interface{}, error)
+}
+
+// defaultBufferSize the number of payloads buffered per subscriber by default
+const defaultBufferSize = 16
+
+// MemoryPubSub an in-memory PubSub for a single process
+type MemoryPubSub struct {
+	BufferSize int // payloads buffered per subscriber, defaults to 16
+
+	mx     sync.RWMutex
+	topics map[string]map[*memorySubscriber]struct{}
+}
+
+type memorySubscriber struct {
+	ctx context.Context
+	ch  chan interface{}
+}
+
+// NewPubSub creates a new in-memory PubSub
+func NewPubSub() *MemoryPubSub {
+	return &MemoryPubSub{
+		topics:     map[string]map[*memorySubscriber]struct{}{},
+		BufferSize: defaultBufferSize,
+	}
+}
+
+// Publish delivers the payload to every subscriber of the topic without
+// blocking. When a subscriber is too slow to keep up and its buffer is full
+// the oldest buffered payload is dropped to make room, so the subscription
+// stays open and always ends with the latest payload
+func (c *MemoryPubSub) Publish(ctx context.Context, topic string, payload interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	c.mx.RLock()
+	defer c.mx.RUnlock()
+
+	for sub := range c.topics[topic] {
+		for {
+			select {
+			case sub.ch <- payload:
+			default:
+				// drop the oldest payload and try again
+				select {
+				case <-sub.ch:
+				default:
+				}
+				continue
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// Subscribe subscribes to a topic until the context is done
+func (c *MemoryPubSub) Subscribe(ctx context.Context, topic string) (<-chan interface{}, error) {
+	sub := &memorySubscriber{
+		ctx: ctx,
+		ch:  make(chan interface{}, c.bufferSize()),
+	}
+
+	c.mx.Lock()
+	subs, ok := c.topics[topic]
+	if !ok {
+		subs = map[*memorySubscriber]struct{}{}
+		c.topics[topic] = subs
+	}
+	subs[sub] = struct{}{}
+	c.mx.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		c.unsubscribe(topic, sub)
+	}()
+
+	return sub.ch, nil
+}
+
+// removes a subscriber and closes its channel if it is still subscribed
+func (c *MemoryPubSub) unsubscribe(topic string, sub *memorySubscriber) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if _, ok := c.topics[topic][sub]; !ok {
+		return
+	}
+	delete(c.topics[topic], sub)
+	if len(c.topics[topic]) == 0 {
+		delete(c.topics, topic)
+	}
+	close(sub.ch)
+}
+
+// the subscriber buffer size
+func (c *MemoryPubSub) bufferSize() int {
+	if c.BufferSize > 0 {
+		return c.BufferSize
+	}
+	return defaultBufferSize
+}
@@ -9,10 +9,37 @@ import (
 	"github.com/graphql-go/graphql"
 	"github.com/graphql-go/graphql/language/ast"
 	"github.com/graphql-go/graphql/language/kinds"
+	"github.com/rohit20001221/graphql-go-tools/scalars"
 )
 
 var errUnresolvedDependencies = errors.New("unresolved dependencies")
 
+// scalars bound to SDL scalar definitions without a resolver
+var namedScalars = map[string]*graphql.Scalar{
+	"JSON":          scalars.ScalarJSON,
+	"StringSet":     scalars.ScalarStringSet,
+	"BoolString":    scalars.ScalarBoolString,
+	"QueryDocument": scalars.ScalarQueryDocument,
+}
+
+// built-in types used by introspection cannot be overridden
+var introspectionTypes = map[string]bool{
+	"String":  true,
+	"Boolean": true,
+}
+
+// creates the built-in types
+func builtinTypes() map[string]graphql.Type {
+	return map[string]graphql.Type{
+		"ID":       graphql.ID,
+		"String":   graphql.String,
+		"Int":      graphql.Int,
+		"Float":    graphql.Float,
+		"Boolean":  graphql.Boolean,
+		"DateTime": graphql.DateTime,
+	}
+}
+
 // registry the registry holds all of the types
 type registry struct {
 	ctx              context.Context
@@ -28,6 +55,11 @@ type registry struct {
 	maxIterations    int
 	iterations       int
 	dependencyMap    DependencyMap
+	pubsub           PubSub
+	jobs             *JobManager
+	errorMatchers    map[string]ErrorMatcher
+	addedTypes       map[string]bool
+	addedDirectives  map[string]bool
 }
 
 // newRegistry creates a new registry
@@ -43,23 +75,23 @@ func newRegistry(
 	}
 
 	r := &registry{
-		ctx: ctx,
-		types: map[string]graphql.Type{
-			"ID":       graphql.ID,
-			"String":   graphql.String,
-			"Int":      graphql.Int,
-			"Float":    graphql.Float,
-			"Boolean":  graphql.Boolean,
-			"DateTime": graphql.DateTime,
-		},
+		ctx:   ctx,
+		types: builtinTypes(),
 		directives: map[string]*graphql.Directive{
-			"include":    graphql.IncludeDirective,
-			"skip":       graphql.SkipDirective,
-			"deprecated": graphql.DeprecatedDirective,
-			"hide":       HideDirective,
+			"include":              graphql.IncludeDirective,
+			"skip":                 graphql.SkipDirective,
+			"deprecated":           graphql.DeprecatedDirective,
+			"hide":                 HideDirective,
+			directiveRelayMutation: RelayMutationDirective,
+			directivePublish:       PublishDirective,
+			directiveSubscribe:     SubscribeDirective,
+			directiveAsyncJob:      AsyncJobDirective,
+			directiveErrorsAsData:  ErrorsAsDataDirective,
 		},
+		addedTypes:       map[string]bool{},
+		addedDirectives:  map[string]bool{},
 		resolverMap:      resolverMap{},
-		directiveMap:     directiveMap,
+		directiveMap:     SchemaDirectiveVisitorMap{},
 		schemaDirectives: []*ast.Directive{},
 		document:         document,
 		extensions:       extensions,
@@ -68,12 +100,33 @@ func newRegistry(
 		maxIterations:    len(document.Definitions),
 	}
 
-	// import each resolver to the correct location
+	// built-in visitors can be replaced by user supplied visitors
+	r.directiveMap[directiveRelayMutation] = relayMutationVisitor
+	r.directiveMap[directivePublish] = r.publishVisitor()
+	r.directiveMap[directiveSubscribe] = r.subscribeVisitor()
+	r.directiveMap[directiveAsyncJob] = r.asyncJobVisitor()
+	r.directiveMap[directiveErrorsAsData] = r.errorsAsDataVisitor()
+	for name, visitor := range directiveMap {
+		r.directiveMap[name] = visitor
+	}
+
+	// import each resolver to the correct location, overrides are imported
+	// last so they replace built-ins regardless of map order
+	overrides := map[string]*OverrideResolver{}
 	for name, resolver := range resolvers {
+		if override, ok := resolver.(*OverrideResolver); ok {
+			overrides[name] = override
+			continue
+		}
 		if err := r.importResolver(name, resolver); err != nil {
 			return nil, err
 		}
 	}
+	for name, override := range overrides {
+		if err := r.importOverride(name, override); err != nil {
+			return nil, err
+		}
+	}
 
 	return r, nil
 }
@@ -131,6 +184,67 @@ func (c *registry) getDirective(name string) (*graphql.Directive, error) {
 	return nil, errUnresolvedDependencies
 }
 
+// GetType gets a built type for directive visitors
+func (c *registry) GetType(name string) (graphql.Type, error) {
+	return c.getType(name)
+}
+
+// GetDirective gets a directive for directive visitors
+func (c *registry) GetDirective(name string) (*graphql.Directive, error) {
+	if directive, ok := c.directives[name]; ok {
+		return directive, nil
+	}
+
+	for _, def := range c.unresolvedDefs {
+		if def.GetKind() == kinds.DirectiveDefinition && getNodeName(def) == name {
+			return nil, errUnresolvedDependencies
+		}
+	}
+
+	return nil, fmt.Errorf("no definition found for directive %q", name)
+}
+
+// AddType adds a type generated by a directive visitor. Visitors may run again
+// when a definition is retried so types added by visitors can be replaced
+func (c *registry) AddType(t graphql.Type) error {
+	if t == nil {
+		return fmt.Errorf("cannot add a nil type")
+	}
+
+	name := t.Name()
+	if existing, ok := c.types[name]; ok && existing != t && !c.addedTypes[name] {
+		return fmt.Errorf("type %q is already defined", name)
+	}
+	if _, ok := c.types[name]; !ok && c.willResolve(name) {
+		return fmt.Errorf("type %q is already defined", name)
+	}
+
+	c.types[name] = t
+	c.addedTypes[name] = true
+	return nil
+}
+
+// AddDirective adds a directive generated by a directive visitor
+func (c *registry) AddDirective(directive *graphql.Directive) error {
+	if directive == nil {
+		return fmt.Errorf("cannot add a nil directive")
+	}
+
+	name := directive.Name
+	if existing, ok := c.directives[name]; ok && existing != directive && !c.addedDirectives[name] {
+		return fmt.Errorf("directive %q is already defined", name)
+	}
+	for _, def := range c.document.Definitions {
+		if def.GetKind() == kinds.DirectiveDefinition && getNodeName(def) == name {
+			return fmt.Errorf("directive %q is already defined", name)
+		}
+	}
+
+	c.directives[name] = directive
+	c.addedDirectives[name] = true
+	return nil
+}
+
 // gets the extensions for the current type
 func (c *registry) getExtensions(name, kind string) []*ast.ObjectDefinition {
 	extensions := []*ast.ObjectDefinition{}
@@ -213,6 +327,12 @@ func (c *registry) importResolver(name string, resolver interface{}) error {
 		if _, ok := c.resolverMap[name]; !ok {
 			c.resolverMap[name] = res
 		}
+
+	case *InputObjectResolver:
+		if _, ok := c.resolverMap[name]; !ok {
+			c.resolverMap[name] = res
+		}
+
 	default:
 		return fmt.Errorf("invalid resolver type for %s", name)
 	}
@@ -220,6 +340,39 @@ func (c *registry) importResolver(name string, resolver interface{}) error {
 	return nil
 }
 
+// imports a resolver replacing any built-in with the same name
+func (c *registry) importOverride(name string, override *OverrideResolver) error {
+	if override.Resolver == nil {
+		return fmt.Errorf("override for %s has no resolver", name)
+	}
+
+	if _, ok := override.Resolver.(*graphql.Directive); ok {
+		delete(c.directives, strings.TrimLeft(name, "@"))
+		return c.importResolver(name, override.Resolver)
+	}
+
+	if introspectionTypes[name] {
+		return fmt.Errorf("built-in type %s is used by introspection and cannot be overridden", name)
+	}
+
+	// a scalar resolver for a built-in scalar creates a new scalar
+	if resolver, ok := override.Resolver.(*ScalarResolver); ok {
+		if _, isBuiltin := builtinTypes()[name]; isBuiltin {
+			c.types[name] = graphql.NewScalar(graphql.ScalarConfig{
+				Name:         name,
+				Serialize:    resolver.Serialize,
+				ParseValue:   resolver.ParseValue,
+				ParseLiteral: resolver.ParseLiteral,
+			})
+			return nil
+		}
+	}
+
+	delete(c.types, name)
+	delete(c.resolverMap, name)
+	return c.importResolver(name, override.Resolver)
+}
+
 func getNodeName(node ast.Node) string {
 	switch node.GetKind() {
 	case kinds.ObjectDefinition:
@@ -255,8 +408,10 @@ func (c *registry) willResolve(name string) bool {
 	return false
 }
 
-// iteratively resolves dependencies until all types are resolved
+// iteratively resolves dependencies until all types are resolved, the schema
+// definition is built last so it includes types added by directive visitors
 func (c *registry) resolveDefinitions() error {
+	var schemaDefinition *ast.SchemaDefinition
 	unresolved := []ast.Node{}
 
 	for len(c.unresolvedDefs) > 0 && c.iterations < c.maxIterations {
@@ -321,18 +476,16 @@ func (c *registry) resolveDefinitions() error {
 					}
 				}
 			case kinds.SchemaDefinition:
-				if err := c.buildSchemaFromAST(definition.(*ast.SchemaDefinition)); err != nil {
-					if err == errUnresolvedDependencies {
-						unresolved = append(unresolved, definition)
-					} else {
-						return err
-					}
-				}
+				schemaDefinition = definition.(*ast.SchemaDefinition)
 			}
 		}
 
 		// check if everything has been resolved
 		if len(unresolved) == 0 {
+			c.unresolvedDefs = unresolved
+			if schemaDefinition != nil {
+				return c.buildSchemaFromAST(schemaDefinition)
+			}
 			return nil
 		}
 
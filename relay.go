@@ -0,0 +1,262 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/kinds"
+)
+
+const (
+	directiveRelayMutation = "relayMutation"
+	clientMutationIDField  = "clientMutationId"
+	relayInputArgument     = "input"
+)
+
+// RelayMutationDirective generates relay style input and payload types for a mutation field
+var RelayMutationDirective = graphql.NewDirective(graphql.DirectiveConfig{
+	Name:        directiveRelayMutation,
+	Description: "Generates a single input argument and payload type for a mutation field. The resolver receives the declared arguments and returns the declared type",
+	Locations:   []string{graphql.DirectiveLocationFieldDefinition},
+	Args: graphql.FieldConfigArgument{
+		"inputName": &graphql.ArgumentConfig{
+			Type:        graphql.String,
+			Description: "Name of the generated input type, defaults to <Field>Input",
+		},
+		"payloadName": &graphql.ArgumentConfig{
+			Type:        graphql.String,
+			Description: "Name of the generated payload type, defaults to <Field>Payload",
+		},
+		"payloadField": &graphql.ArgumentConfig{
+			Type:        graphql.String,
+			Description: "Name of the payload field holding the result, defaults to the camel cased return type name",
+		},
+	},
+})
+
+// relayMutationVisitor unwraps the input argument before calling the resolver
+// and wraps the result in the payload
+var relayMutationVisitor = &SchemaDirectiveVisitor{
+	VisitFieldDefinition: func(p VisitFieldDefinitionParams) error {
+		payloadField, _ := p.Args["payloadField"].(string)
+		if payloadField == "" {
+			return fmt.Errorf("@%s on field %q has no payload field", directiveRelayMutation, p.Config.Name)
+		}
+
+		resolve := p.Config.Resolve
+		if resolve == nil {
+			resolve = graphql.DefaultResolveFn
+		}
+
+		p.Config.Resolve = func(rp graphql.ResolveParams) (interface{}, error) {
+			input, _ := rp.Args[relayInputArgument].(map[string]interface{})
+			args := map[string]interface{}{}
+			for name, value := range input {
+				if name != clientMutationIDField {
+					args[name] = value
+				}
+			}
+			rp.Args = args
+
+			result, err := resolve(rp)
+			if err != nil {
+				return nil, err
+			}
+
+			return map[string]interface{}{
+				clientMutationIDField: input[clientMutationIDField],
+				payloadField:          result,
+			}, nil
+		}
+
+		return nil
+	},
+}
+
+// expandRelayMutations replaces the arguments and return type of each mutation
+// field with a @relayMutation directive with generated input and payload types.
+// The resolved names are written back to the directive for the visitor
+func expandRelayMutations(document *ast.Document) (*ast.Document, error) {
+	mutationName := getRootTypeName(document, ast.OperationTypeMutation, DefaultRootMutationName)
+	generated := []ast.Node{}
+
+	for _, def := range document.Definitions {
+		var object *ast.ObjectDefinition
+		switch d := def.(type) {
+		case *ast.ObjectDefinition:
+			object = d
+		case *ast.TypeExtensionDefinition:
+			object = d.Definition
+		default:
+			continue
+		}
+
+		for _, field := range object.Fields {
+			directive := getASTDirective(field.Directives, directiveRelayMutation)
+			if directive == nil {
+				continue
+			}
+
+			if object.Name.Value != mutationName {
+				return nil, fmt.Errorf("@%s can only be applied to fields of %q, found on %s.%s", directiveRelayMutation, mutationName, object.Name.Value, field.Name.Value)
+			}
+
+			defs, err := expandRelayMutation(field, directive)
+			if err != nil {
+				return nil, err
+			}
+			generated = append(generated, defs...)
+		}
+	}
+
+	for _, def := range generated {
+		if err := AddDefinition(document, def); err != nil {
+			return nil, fmt.Errorf("@%s: %v", directiveRelayMutation, err)
+		}
+	}
+
+	return document, nil
+}
+
+// expands a single relay mutation field
+func expandRelayMutation(field *ast.FieldDefinition, directive *ast.Directive) ([]ast.Node, error) {
+	fieldName := field.Name.Value
+	returnType, err := identifyRootType(field.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	inputName := getASTDirectiveStringArg(directive, "inputName")
+	if inputName == "" {
+		inputName = upperFirst(fieldName) + "Input"
+	}
+	payloadName := getASTDirectiveStringArg(directive, "payloadName")
+	if payloadName == "" {
+		payloadName = upperFirst(fieldName) + "Payload"
+	}
+	payloadField := getASTDirectiveStringArg(directive, "payloadField")
+	if payloadField == "" {
+		payloadField = lowerFirst(returnType)
+	}
+
+	if payloadField == clientMutationIDField {
+		return nil, fmt.Errorf("@%s on field %q cannot use %q as the payload field", directiveRelayMutation, fieldName, clientMutationIDField)
+	}
+
+	input := ast.NewInputObjectDefinition(&ast.InputObjectDefinition{
+		Name:        ast.NewName(&ast.Name{Value: inputName}),
+		Description: ast.NewStringValue(&ast.StringValue{Value: fmt.Sprintf("Input for the %s mutation", fieldName)}),
+		Fields: []*ast.InputValueDefinition{
+			newInputValueDefinition(clientMutationIDField, ast.NewNamed(&ast.Named{
+				Name: ast.NewName(&ast.Name{Value: "String"}),
+			})),
+		},
+	})
+
+	for _, arg := range field.Arguments {
+		if arg.Name.Value == clientMutationIDField {
+			return nil, fmt.Errorf("@%s on field %q cannot declare a %q argument", directiveRelayMutation, fieldName, clientMutationIDField)
+		}
+		input.Fields = append(input.Fields, arg)
+	}
+
+	payload := ast.NewObjectDefinition(&ast.ObjectDefinition{
+		Name:        ast.NewName(&ast.Name{Value: payloadName}),
+		Description: ast.NewStringValue(&ast.StringValue{Value: fmt.Sprintf("Payload for the %s mutation", fieldName)}),
+		Fields: []*ast.FieldDefinition{
+			ast.NewFieldDefinition(&ast.FieldDefinition{
+				Name: ast.NewName(&ast.Name{Value: clientMutationIDField}),
+				Type: ast.NewNamed(&ast.Named{Name: ast.NewName(&ast.Name{Value: "String"})}),
+			}),
+			ast.NewFieldDefinition(&ast.FieldDefinition{
+				Name: ast.NewName(&ast.Name{Value: payloadField}),
+				Type: field.Type,
+			}),
+		},
+	})
+
+	// rewrite the field to accept the input and return the payload
+	field.Arguments = []*ast.InputValueDefinition{
+		newInputValueDefinition(relayInputArgument, ast.NewNonNull(&ast.NonNull{
+			Type: ast.NewNamed(&ast.Named{Name: ast.NewName(&ast.Name{Value: inputName})}),
+		})),
+	}
+	field.Type = ast.NewNamed(&ast.Named{Name: ast.NewName(&ast.Name{Value: payloadName})})
+	setASTDirectiveStringArg(directive, "payloadField", payloadField)
+
+	return []ast.Node{input, payload}, nil
+}
+
+// gets the names of the input types generated for @relayMutation fields
+func relayInputNames(document *ast.Document) map[string]bool {
+	mutationName := getRootTypeName(document, ast.OperationTypeMutation, DefaultRootMutationName)
+	names := map[string]bool{}
+
+	for _, def := range document.Definitions {
+		var object *ast.ObjectDefinition
+		switch d := def.(type) {
+		case *ast.ObjectDefinition:
+			object = d
+		case *ast.TypeExtensionDefinition:
+			object = d.Definition
+		default:
+			continue
+		}
+		if object.Name.Value != mutationName {
+			continue
+		}
+
+		for _, field := range object.Fields {
+			if getASTDirective(field.Directives, directiveRelayMutation) == nil {
+				continue
+			}
+			for _, arg := range field.Arguments {
+				if arg.Name.Value != relayInputArgument {
+					continue
+				}
+				if name, err := identifyRootType(arg.Type); err == nil {
+					names[name] = true
+				}
+			}
+		}
+	}
+	return names
+}
+
+func newInputValueDefinition(name string, t ast.Type) *ast.InputValueDefinition {
+	return ast.NewInputValueDefinition(&ast.InputValueDefinition{
+		Name: ast.NewName(&ast.Name{Value: name}),
+		Type: t,
+	})
+}
+
+// gets the name of a root operation type from the schema definition or the default
+func getRootTypeName(document *ast.Document, operation, defaultName string) string {
+	for _, def := range document.Definitions {
+		if def.GetKind() != kinds.SchemaDefinition {
+			continue
+		}
+		for _, op := range def.(*ast.SchemaDefinition).OperationTypes {
+			if op.Operation == operation {
+				return op.Type.Name.Value
+			}
+		}
+	}
+	return defaultName
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
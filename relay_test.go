@@ -0,0 +1,92 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/graphql-go/graphql"
+)
+
+func TestRelayMutation(t *testing.T) {
+	typeDefs := `
+type Foo {
+	id: ID!
+	name: String!
+}
+
+type Query {
+	foo: Foo
+}
+
+type Mutation {
+	createFoo(name: String!, prefix: String = "foo-"): Foo @relayMutation
+}`
+
+	schema, err := MakeExecutableSchema(ExecutableSchema{
+		TypeDefs: typeDefs,
+		Resolvers: ResolverMap{
+			"Mutation": &ObjectResolver{
+				Fields: FieldResolveMap{
+					"createFoo": &FieldResolve{
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							if _, ok := p.Args["input"]; ok {
+								t.Error("expected the input argument to be unwrapped")
+							}
+							return map[string]interface{}{
+								"id":   "1",
+								"name": p.Args["prefix"].(string) + p.Args["name"].(string),
+							}, nil
+						},
+					},
+				},
+			},
+		},
+	})
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if _, ok := schema.Type("CreateFooInput").(*graphql.InputObject); !ok {
+		t.Error("expected CreateFooInput to be generated")
+	}
+
+	r := graphql.Do(graphql.Params{
+		Schema: schema,
+		RequestString: `mutation {
+			createFoo(input: { name: "bar", clientMutationId: "abc" }) {
+				clientMutationId
+				foo {
+					name
+				}
+			}
+		}`,
+	})
+
+	if r.HasErrors() {
+		t.Error(r.Errors)
+		return
+	}
+
+	payload := r.Data.(map[string]interface{})["createFoo"].(map[string]interface{})
+	if payload["clientMutationId"] != "abc" {
+		t.Errorf("expected clientMutationId to be passed through, got %v", payload["clientMutationId"])
+	}
+
+	if name := payload["foo"].(map[string]interface{})["name"]; name != "foo-bar" {
+		t.Errorf("expected name foo-bar, got %v", name)
+	}
+}
+
+func TestRelayMutationOutsideMutation(t *testing.T) {
+	_, err := MakeExecutableSchema(ExecutableSchema{
+		TypeDefs: `
+type Query {
+	foo(name: String): String @relayMutation
+}`,
+	})
+
+	if err == nil {
+		t.Error("expected an error when @relayMutation is used outside of the mutation type")
+	}
+}
@@ -0,0 +1,507 @@
+// Package resolvercheck provides a go/analysis analyzer checking the Resolvers
+// of tools.ExecutableSchema literals against their schema language TypeDefs.
+//
+// TypeDefs are read from constant strings, string slices of constants and
+// variables assigned from tools.ReadSourceFiles with a constant path. Relative
+// paths are resolved from the directory of the source file. Schemas with
+// TypeDefs that cannot be determined statically are skipped
+package resolvercheck
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"strconv"
+
+	gqlast "github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	tools "github.com/rohit20001221/graphql-go-tools"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// the import path of the tools package
+const toolsPath = "github.com/rohit20001221/graphql-go-tools"
+
+// Analyzer reports resolver map entries for unknown types and fields, resolvers
+// of the wrong kind and abstract types without a way to resolve their type
+var Analyzer = &analysis.Analyzer{
+	Name:     "resolvercheck",
+	Doc:      "check ExecutableSchema resolver maps against the schema TypeDefs",
+	Run:      run,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+}
+
+// schema kinds
+const (
+	kindObject    = "object"
+	kindInterface = "interface"
+	kindUnion     = "union"
+	kindEnum      = "enum"
+	kindScalar    = "scalar"
+	kindInput     = "input object"
+)
+
+// resolver type names and the kind they configure
+var resolverKinds = map[string]string{
+	"ObjectResolver":      kindObject,
+	"InterfaceResolver":   kindInterface,
+	"UnionResolver":       kindUnion,
+	"EnumResolver":        kindEnum,
+	"ScalarResolver":      kindScalar,
+	"InputObjectResolver": kindInput,
+}
+
+// built-in scalars that may be supplied without a definition
+var builtinScalars = map[string]bool{
+	"String":   true,
+	"Int":      true,
+	"Float":    true,
+	"Boolean":  true,
+	"ID":       true,
+	"DateTime": true,
+}
+
+// a type definition from the schema
+type schemaType struct {
+	kind          string
+	fields        map[string]bool
+	possibleTypes []string
+	builtin       bool // the type is resolved by a built-in directive unless a resolver is supplied
+}
+
+// a resolver map entry
+type resolverEntry struct {
+	key         *ast.BasicLit
+	kind        string
+	fields      *ast.CompositeLit
+	resolveType bool
+	isTypeOf    bool
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	insp.Preorder([]ast.Node{(*ast.CompositeLit)(nil)}, func(n ast.Node) {
+		lit := n.(*ast.CompositeLit)
+		if !isToolsType(pass.TypesInfo.TypeOf(lit), "ExecutableSchema") {
+			return
+		}
+
+		var typeDefs, resolvers ast.Expr
+		for _, elt := range lit.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				continue
+			}
+			switch key, _ := kv.Key.(*ast.Ident); {
+			case key == nil:
+			case key.Name == "TypeDefs":
+				typeDefs = kv.Value
+			case key.Name == "Resolvers":
+				resolvers = kv.Value
+			}
+		}
+		if typeDefs == nil || resolvers == nil {
+			return
+		}
+
+		sources, ok := typeDefSources(pass, typeDefs)
+		if !ok {
+			return
+		}
+
+		schemaTypes, err := parseTypes(sources)
+		if err != nil {
+			pass.Reportf(typeDefs.Pos(), "invalid TypeDefs: %v", err)
+			return
+		}
+
+		resolverLit, ok := definition(pass, resolvers).(*ast.CompositeLit)
+		if !ok {
+			return
+		}
+
+		checkResolvers(pass, lit, resolverLit, schemaTypes)
+	})
+
+	return nil, nil
+}
+
+// checks the resolver map entries
+func checkResolvers(pass *analysis.Pass, schema, resolvers *ast.CompositeLit, schemaTypes map[string]*schemaType) {
+	entries := map[string]*resolverEntry{}
+	for _, elt := range resolvers.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key, ok := kv.Key.(*ast.BasicLit)
+		if !ok || key.Kind != token.STRING {
+			continue
+		}
+		name, err := strconv.Unquote(key.Value)
+		if err != nil {
+			continue
+		}
+
+		entry := resolverEntryOf(pass, key, kv.Value)
+		if entry == nil {
+			continue
+		}
+		entries[name] = entry
+
+		t, ok := schemaTypes[name]
+		if !ok {
+			if entry.kind != kindScalar || !builtinScalars[name] {
+				pass.Reportf(key.Pos(), "resolver for unknown type %q", name)
+			}
+			continue
+		}
+
+		if entry.kind != t.kind {
+			pass.Reportf(kv.Value.Pos(), "%s resolver supplied for %s %q", entry.kind, t.kind, name)
+			continue
+		}
+
+		if entry.fields != nil {
+			for _, fieldElt := range entry.fields.Elts {
+				fieldKV, ok := fieldElt.(*ast.KeyValueExpr)
+				if !ok {
+					continue
+				}
+				fieldKey, ok := fieldKV.Key.(*ast.BasicLit)
+				if !ok || fieldKey.Kind != token.STRING {
+					continue
+				}
+				fieldName, err := strconv.Unquote(fieldKey.Value)
+				if err == nil && !t.fields[fieldName] {
+					pass.Reportf(fieldKey.Pos(), "resolver for unknown field %q on %s %q", fieldName, t.kind, name)
+				}
+			}
+		}
+	}
+
+	// abstract types need a ResolveType or IsTypeOf on each possible type
+	for name, t := range schemaTypes {
+		if t.kind != kindInterface && t.kind != kindUnion {
+			continue
+		}
+		entry, ok := entries[name]
+		if ok && entry.resolveType || !ok && t.builtin {
+			continue
+		}
+
+		resolvable := len(t.possibleTypes) > 0
+		for _, possibleType := range t.possibleTypes {
+			if entry, ok := entries[possibleType]; !ok || !entry.isTypeOf {
+				resolvable = false
+				break
+			}
+		}
+		if resolvable {
+			continue
+		}
+
+		pos := schema.Pos()
+		if entry, ok := entries[name]; ok {
+			pos = entry.key.Pos()
+		}
+		pass.Reportf(pos, "missing ResolveType for %s %q", t.kind, name)
+	}
+}
+
+// gets the resolver entry of a resolver map value
+func resolverEntryOf(pass *analysis.Pass, key *ast.BasicLit, value ast.Expr) *resolverEntry {
+	t := pass.TypesInfo.TypeOf(value)
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+
+	named, ok := t.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil || named.Obj().Pkg().Path() != toolsPath {
+		return nil
+	}
+	kind, ok := resolverKinds[named.Obj().Name()]
+	if !ok {
+		return nil
+	}
+
+	entry := &resolverEntry{key: key, kind: kind}
+
+	// inspect literal resolvers for their fields and type resolution
+	if unary, ok := value.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+		value = unary.X
+	}
+	lit, ok := value.(*ast.CompositeLit)
+	if !ok {
+		// the fields and functions of resolvers built elsewhere are unknown
+		entry.resolveType = true
+		entry.isTypeOf = true
+		return entry
+	}
+
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		ident, ok := kv.Key.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		switch ident.Name {
+		case "Fields":
+			entry.fields, _ = definition(pass, kv.Value).(*ast.CompositeLit)
+		case "ResolveType":
+			entry.resolveType = !isNil(kv.Value)
+		case "IsTypeOf":
+			entry.isTypeOf = !isNil(kv.Value)
+		}
+	}
+
+	return entry
+}
+
+// gets the schema sources of a TypeDefs expression
+func typeDefSources(pass *analysis.Pass, expr ast.Expr) ([]string, bool) {
+	if s, ok := constantString(pass, expr); ok {
+		return []string{s}, true
+	}
+
+	switch e := definition(pass, expr).(type) {
+	case *ast.CompositeLit:
+		sources := []string{}
+		for _, elt := range e.Elts {
+			elementSources, ok := typeDefSources(pass, elt)
+			if !ok {
+				return nil, false
+			}
+			sources = append(sources, elementSources...)
+		}
+		return sources, true
+
+	case *ast.CallExpr:
+		if !isToolsFunc(pass, e.Fun, "ReadSourceFiles") || len(e.Args) == 0 {
+			return nil, false
+		}
+		path, ok := constantString(pass, e.Args[0])
+		if !ok {
+			return nil, false
+		}
+
+		recursive := false
+		if len(e.Args) > 1 {
+			if tv, ok := pass.TypesInfo.Types[e.Args[1]]; ok && tv.Value != nil && tv.Value.Kind() == constant.Bool {
+				recursive = constant.BoolVal(tv.Value)
+			}
+		}
+
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(pass.Fset.File(e.Pos()).Name()), path)
+		}
+
+		typeDefs, err := tools.ReadSourceFiles(path, recursive)
+		if err != nil {
+			pass.Reportf(e.Pos(), "failed to read TypeDefs: %v", err)
+			return nil, false
+		}
+		return []string{typeDefs}, true
+	}
+
+	return nil, false
+}
+
+// parses the type definitions and extensions of the sources
+func parseTypes(sources []string) (map[string]*schemaType, error) {
+	schemaTypes := map[string]*schemaType{}
+	get := func(name, kind string) *schemaType {
+		t, ok := schemaTypes[name]
+		if !ok {
+			t = &schemaType{kind: kind, fields: map[string]bool{}}
+			schemaTypes[name] = t
+		}
+		return t
+	}
+
+	// parse the sources into one document expanded by the built-in transforms
+	// so generated types and fields are known
+	document := &gqlast.Document{}
+	for _, s := range sources {
+		doc, err := parser.Parse(parser.ParseParams{
+			Source: &source.Source{Body: []byte(s), Name: "GraphQL"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		document.Definitions = append(document.Definitions, doc.Definitions...)
+	}
+	document, err := tools.ExpandDocument(document)
+	if err != nil {
+		return nil, err
+	}
+
+	interfaces := map[string][]string{}
+	builtin := map[string]bool{}
+	for _, def := range document.Definitions {
+		switch d := def.(type) {
+		case *gqlast.TypeExtensionDefinition:
+			def = d.Definition
+		}
+
+		switch d := def.(type) {
+		case *gqlast.ObjectDefinition:
+			t := get(d.Name.Value, kindObject)
+			for _, field := range d.Fields {
+				t.fields[field.Name.Value] = true
+
+				// @errorsAsData resolves the type of its union and the Error interface
+				for _, directive := range field.Directives {
+					if directive.Name.Value != "errorsAsData" {
+						continue
+					}
+					builtin["Error"] = true
+					for _, arg := range directive.Arguments {
+						if value, ok := arg.Value.(*gqlast.StringValue); ok && arg.Name.Value == "name" {
+							builtin[value.Value] = true
+						}
+					}
+				}
+			}
+			for _, iface := range d.Interfaces {
+				interfaces[iface.Name.Value] = append(interfaces[iface.Name.Value], d.Name.Value)
+			}
+		case *gqlast.InterfaceDefinition:
+			t := get(d.Name.Value, kindInterface)
+			for _, field := range d.Fields {
+				t.fields[field.Name.Value] = true
+			}
+		case *gqlast.UnionDefinition:
+			t := get(d.Name.Value, kindUnion)
+			for _, member := range d.Types {
+				t.possibleTypes = append(t.possibleTypes, member.Name.Value)
+			}
+		case *gqlast.EnumDefinition:
+			get(d.Name.Value, kindEnum)
+		case *gqlast.ScalarDefinition:
+			get(d.Name.Value, kindScalar)
+		case *gqlast.InputObjectDefinition:
+			get(d.Name.Value, kindInput)
+		}
+	}
+
+	for name := range builtin {
+		if t, ok := schemaTypes[name]; ok {
+			t.builtin = true
+		}
+	}
+
+	for name, implementations := range interfaces {
+		if t, ok := schemaTypes[name]; ok && t.kind == kindInterface {
+			t.possibleTypes = append(t.possibleTypes, implementations...)
+		}
+	}
+
+	return schemaTypes, nil
+}
+
+// follows an identifier to the expression assigned in its declaration
+func definition(pass *analysis.Pass, expr ast.Expr) ast.Expr {
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return expr
+	}
+
+	obj, ok := pass.TypesInfo.Uses[ident].(*types.Var)
+	if !ok {
+		return expr
+	}
+
+	var value ast.Expr
+	for _, file := range pass.Files {
+		if file.Pos() > obj.Pos() || obj.Pos() > file.End() {
+			continue
+		}
+
+		ast.Inspect(file, func(n ast.Node) bool {
+			if value != nil {
+				return false
+			}
+			switch s := n.(type) {
+			case *ast.AssignStmt:
+				value = assignedValue(pass, obj, s.Lhs, s.Rhs)
+			case *ast.ValueSpec:
+				lhs := make([]ast.Expr, len(s.Names))
+				for i, name := range s.Names {
+					lhs[i] = name
+				}
+				value = assignedValue(pass, obj, lhs, s.Values)
+			}
+			return value == nil
+		})
+	}
+
+	if value == nil {
+		return expr
+	}
+	return value
+}
+
+// gets the value assigned to the definition of obj
+func assignedValue(pass *analysis.Pass, obj types.Object, lhs, rhs []ast.Expr) ast.Expr {
+	for i, l := range lhs {
+		ident, ok := l.(*ast.Ident)
+		if !ok || pass.TypesInfo.Defs[ident] != obj {
+			continue
+		}
+		switch {
+		case len(rhs) == len(lhs):
+			return rhs[i]
+		case len(rhs) == 1 && i == 0:
+			// the first result of a multi value call
+			return rhs[0]
+		}
+	}
+	return nil
+}
+
+// gets the value of a constant string expression
+func constantString(pass *analysis.Pass, expr ast.Expr) (string, bool) {
+	tv, ok := pass.TypesInfo.Types[expr]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return "", false
+	}
+	return constant.StringVal(tv.Value), true
+}
+
+// determines if a type is the named type of the tools package
+func isToolsType(t types.Type, name string) bool {
+	named, ok := t.(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == toolsPath && named.Obj().Name() == name
+}
+
+// determines if an expression is the function of the tools package
+func isToolsFunc(pass *analysis.Pass, expr ast.Expr, name string) bool {
+	var ident *ast.Ident
+	switch e := expr.(type) {
+	case *ast.SelectorExpr:
+		ident = e.Sel
+	case *ast.Ident:
+		ident = e
+	default:
+		return false
+	}
+
+	fn, ok := pass.TypesInfo.Uses[ident].(*types.Func)
+	return ok && fn.Pkg() != nil && fn.Pkg().Path() == toolsPath && fn.Name() == name
+}
+
+// determines if an expression is nil
+func isNil(expr ast.Expr) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == "nil"
+}
@@ -0,0 +1,127 @@
+package a
+
+import tools "github.com/rohit20001221/graphql-go-tools"
+
+const typeDefs = `
+enum Role {
+	ADMIN
+}
+
+interface Node {
+	id: ID!
+}
+
+union SearchResult = User
+
+type User implements Node {
+	id: ID!
+	name: String
+}
+
+type Query {
+	user: User
+}`
+
+func constantTypeDefs() {
+	_ = tools.ExecutableSchema{ // want `missing ResolveType for union "SearchResult"`
+		TypeDefs: typeDefs,
+		Resolvers: tools.ResolverMap{
+			"Query": &tools.ObjectResolver{
+				Fields: tools.FieldResolveMap{
+					"user":  &tools.FieldResolve{},
+					"users": &tools.FieldResolve{}, // want `resolver for unknown field "users" on object "Query"`
+				},
+			},
+			"Usr":  &tools.ObjectResolver{}, // want `resolver for unknown type "Usr"`
+			"Role": &tools.ObjectResolver{}, // want `object resolver supplied for enum "Role"`
+			"Node": &tools.InterfaceResolver{ // want `missing ResolveType for interface "Node"`
+				Fields: tools.FieldResolveMap{},
+			},
+			"ID":   &tools.ScalarResolver{},
+			"User": &tools.InputObjectResolver{}, // want `input object resolver supplied for object "User"`
+		},
+	}
+}
+
+func resolvedTypes() {
+	resolvers := tools.ResolverMap{
+		"Node": &tools.InterfaceResolver{
+			ResolveType: func() {},
+		},
+		"User": &tools.ObjectResolver{
+			IsTypeOf: func() {},
+		},
+	}
+
+	_ = tools.ExecutableSchema{
+		TypeDefs:  typeDefs,
+		Resolvers: resolvers,
+	}
+}
+
+func fileTypeDefs() {
+	schema, _ := tools.ReadSourceFiles("./schema")
+
+	_ = tools.ExecutableSchema{
+		TypeDefs: schema,
+		Resolvers: tools.ResolverMap{
+			"Book": &tools.ObjectResolver{
+				Fields: tools.FieldResolveMap{
+					"author": &tools.FieldResolve{}, // want `resolver for unknown field "author" on object "Book"`
+				},
+			},
+		},
+	}
+}
+
+func dynamicTypeDefs(typeDefs string) {
+	_ = tools.ExecutableSchema{
+		TypeDefs: typeDefs,
+		Resolvers: tools.ResolverMap{
+			"Unknown": &tools.ObjectResolver{},
+		},
+	}
+}
+
+const builtinTypeDefs = `
+interface Error {
+	message: String!
+}
+
+type NotFound implements Error {
+	message: String!
+}
+
+type Book {
+	title: String
+}
+
+type Query {
+	book(id: ID!): Book @errorsAsData(types: ["NotFound"])
+}
+
+type Mutation {
+	importBooks: Boolean @asyncJob
+}`
+
+func builtinDirectiveTypes() {
+	_ = tools.ExecutableSchema{
+		TypeDefs: builtinTypeDefs,
+		Resolvers: tools.ResolverMap{
+			"Query": &tools.ObjectResolver{
+				Fields: tools.FieldResolveMap{
+					"book": &tools.FieldResolve{},
+					"job":  &tools.FieldResolve{},
+				},
+			},
+			"JobStatus": &tools.EnumResolver{},
+		},
+	}
+
+	_ = tools.ExecutableSchema{
+		TypeDefs: builtinTypeDefs,
+		Resolvers: tools.ResolverMap{
+			"Error": &tools.InterfaceResolver{}, // want `missing ResolveType for interface "Error"`
+		},
+	}
+}
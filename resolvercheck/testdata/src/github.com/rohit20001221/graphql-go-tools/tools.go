@@ -0,0 +1,45 @@
+// Package tools is a stub of the tools package for the analyzer tests
+package tools
+
+type ExecutableSchema struct {
+	TypeDefs  interface{}
+	Resolvers map[string]interface{}
+}
+
+type ResolverMap map[string]interface{}
+
+type FieldResolveMap map[string]*FieldResolve
+
+type FieldResolve struct {
+	Resolve interface{}
+}
+
+type ObjectResolver struct {
+	IsTypeOf interface{}
+	Fields   FieldResolveMap
+}
+
+type InterfaceResolver struct {
+	ResolveType interface{}
+	Fields      FieldResolveMap
+}
+
+type UnionResolver struct {
+	ResolveType interface{}
+}
+
+type EnumResolver struct {
+	Values map[string]interface{}
+}
+
+type InputObjectResolver struct {
+	Type interface{}
+}
+
+type ScalarResolver struct {
+	Serialize interface{}
+}
+
+func ReadSourceFiles(p string, recursive ...bool) (string, error) {
+	return "", nil
+}
@@ -14,6 +14,17 @@ type Resolver interface {
 // Accept generic interfaces and identify types at build
 type ResolverMap map[string]interface{}
 
+// OverrideResolver replaces a built-in type or directive with the same name
+type OverrideResolver struct {
+	Resolver interface{}
+}
+
+// Override wraps a resolver, type or directive so it replaces the built-in
+// with the same name. ScalarResolvers overriding a built-in scalar create a new scalar
+func Override(resolver interface{}) *OverrideResolver {
+	return &OverrideResolver{Resolver: resolver}
+}
+
 // internal resolver map
 type resolverMap map[string]Resolver
 
@@ -79,3 +90,18 @@ type EnumResolver struct {
 func (c *EnumResolver) getKind() string {
 	return kinds.EnumDefinition
 }
+
+// InputObjectResolver decodes coerced input object values into Go values.
+// Type is a value of the Go type, e.g. UserInput{} or &UserInput{}, whose
+// fields are matched by json tag or name. ParseValue converts the value
+// instead when set. Resolvers for inputs generated by @relayMutation are
+// ignored, input objects nested in them are still decoded
+type InputObjectResolver struct {
+	Type       interface{}
+	ParseValue func(value map[string]interface{}) (interface{}, error)
+}
+
+// GetKind gets the kind
+func (c *InputObjectResolver) getKind() string {
+	return kinds.InputObjectDefinition
+}
@@ -0,0 +1,124 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+func TestOverrideBuiltinScalar(t *testing.T) {
+	schema, err := MakeExecutableSchema(ExecutableSchema{
+		TypeDefs: `type Query { id: ID }`,
+		Resolvers: ResolverMap{
+			"ID": Override(&ScalarResolver{
+				Serialize: func(value interface{}) interface{} {
+					return value.(int) * 10
+				},
+				ParseValue: func(value interface{}) interface{} {
+					return value
+				},
+				ParseLiteral: func(valueAST ast.Value) interface{} {
+					return valueAST.GetValue()
+				},
+			}),
+			"Query": &ObjectResolver{
+				Fields: FieldResolveMap{
+					"id": &FieldResolve{
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							return 4, nil
+						},
+					},
+				},
+			},
+		},
+	})
+
+	if err != nil {
+		t.Fatalf("failed to make schema: %v", err)
+	}
+
+	r := graphql.Do(graphql.Params{Schema: schema, RequestString: `{ id }`})
+	if r.HasErrors() {
+		t.Fatalf("unexpected errors: %v", r.Errors)
+	}
+
+	if id := r.Data.(map[string]interface{})["id"]; id != 40 {
+		t.Errorf("expected the overridden ID to serialize 40, got %v", id)
+	}
+}
+
+func TestOverrideIntrospectionScalar(t *testing.T) {
+	_, err := MakeExecutableSchema(ExecutableSchema{
+		TypeDefs: `type Query { name: String }`,
+		Resolvers: ResolverMap{
+			"String": Override(&ScalarResolver{}),
+		},
+	})
+
+	if err == nil || !strings.Contains(err.Error(), "introspection") {
+		t.Errorf("expected an introspection override error, got %v", err)
+	}
+}
+
+func TestNamedScalars(t *testing.T) {
+	schema, err := MakeExecutableSchema(ExecutableSchema{
+		TypeDefs: `
+scalar JSON
+scalar StringSet
+
+type Query {
+	data(value: JSON): JSON
+	tags: StringSet
+}`,
+		Resolvers: ResolverMap{
+			"Query": &ObjectResolver{
+				Fields: FieldResolveMap{
+					"data": &FieldResolve{
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							return p.Args["value"], nil
+						},
+					},
+					"tags": &FieldResolve{
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							return []string{"a", "b"}, nil
+						},
+					},
+				},
+			},
+		},
+	})
+
+	if err != nil {
+		t.Fatalf("failed to make schema: %v", err)
+	}
+
+	r := graphql.Do(graphql.Params{Schema: schema, RequestString: `{ data(value: { foo: "bar" }) tags }`})
+	if r.HasErrors() {
+		t.Fatalf("unexpected errors: %v", r.Errors)
+	}
+
+	data := r.Data.(map[string]interface{})
+	if data["data"].(map[string]interface{})["foo"] != "bar" {
+		t.Errorf("expected JSON value to be bound, got %v", data["data"])
+	}
+	if tags, ok := data["tags"].([]string); !ok || len(tags) != 2 {
+		t.Errorf("expected StringSet value to be bound, got %v", data["tags"])
+	}
+}
+
+func TestMissingScalarResolver(t *testing.T) {
+	_, err := MakeExecutableSchema(ExecutableSchema{
+		TypeDefs: `
+scalar Money
+
+type Query {
+	price: Money
+}`,
+	})
+
+	if err == nil || !strings.Contains(err.Error(), `"Money"`) {
+		t.Errorf("expected a missing scalar resolver error, got %v", err)
+	}
+}
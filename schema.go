@@ -33,7 +33,30 @@ type ExecutableSchema struct {
 	Resolvers        map[string]interface{}    // a map of Resolver, Directive, Scalar, Enum, Object, InputObject, Union, or Interface
 	SchemaDirectives SchemaDirectiveVisitorMap // Map of SchemaDirectiveVisitor
 	Extensions       []graphql.Extension       // GraphQL extensions
+	PubSub           PubSub                    // PubSub used by the @publish and @subscribe directives
+	Jobs             *JobManager               // JobManager used by the @asyncJob directive
+	ErrorMatchers    map[string]ErrorMatcher   // ErrorMatchers used by the @errorsAsData directive, keyed by error type
 	Debug            bool                      // Prints debug messages during compile
+
+	// StrictResults validates resolver results against the field types and
+	// returns an error with the path and offending value, for development
+	StrictResults bool
+
+	// Ancestry records the parent fields of each resolver so they can be
+	// accessed with Ancestors
+	Ancestry bool
+
+	// ArgumentPresence records the raw request variables so ProvidedArguments
+	// can detect explicit nulls passed through variables
+	ArgumentPresence bool
+
+	// IntrospectDirectives the directives exposed by the _appliedDirectives
+	// root field, the field is not added when empty
+	IntrospectDirectives []string
+
+	// DocumentTransforms modify the merged document before the types are built,
+	// they run in order after ConcatenateTypeDefs and before the built-in transforms
+	DocumentTransforms []func(*ast.Document) (*ast.Document, error)
 }
 
 // Document returns the document
@@ -49,13 +72,46 @@ func (c *ExecutableSchema) Make(ctx context.Context) (graphql.Schema, error) {
 		return graphql.Schema{}, err
 	}
 
+	// transform the document before building
+	if document, err = c.transformDocument(document); err != nil {
+		return graphql.Schema{}, err
+	}
+
+	// add the applied directives field before the types are built
+	if len(c.IntrospectDirectives) > 0 {
+		if err := addAppliedDirectivesField(document); err != nil {
+			return graphql.Schema{}, err
+		}
+	}
+
 	c.document = document
 
+	// add the extensions storing per request state
+	extensions := append([]graphql.Extension{}, c.Extensions...)
+	if c.Ancestry {
+		extensions = append(extensions, &ancestryExtension{})
+	}
+	if c.ArgumentPresence {
+		extensions = append(extensions, &presenceExtension{})
+	}
+
 	// create a new registry
-	registry, err := newRegistry(ctx, c.Resolvers, c.SchemaDirectives, c.Extensions, document)
+	registry, err := newRegistry(ctx, c.Resolvers, c.SchemaDirectives, extensions, document)
 	if err != nil {
 		return graphql.Schema{}, err
 	}
+	registry.pubsub = c.PubSub
+	registry.jobs = c.Jobs
+	if err := registry.importJobResolvers(); err != nil {
+		return graphql.Schema{}, err
+	}
+	registry.errorMatchers = c.ErrorMatchers
+	if err := registry.importErrorsAsDataResolvers(); err != nil {
+		return graphql.Schema{}, err
+	}
+	if len(c.IntrospectDirectives) > 0 {
+		registry.importAppliedDirectivesResolver(c.IntrospectDirectives)
+	}
 
 	if registry.dependencyMap, err = registry.IdentifyDependencies(); err != nil {
 		return graphql.Schema{}, err
@@ -68,6 +124,9 @@ func (c *ExecutableSchema) Make(ctx context.Context) (graphql.Schema, error) {
 
 	// check if schema was created by definition
 	if registry.schema != nil {
+		if err := c.finishSchema(registry, *registry.schema); err != nil {
+			return graphql.Schema{}, err
+		}
 		return *registry.schema, nil
 	}
 
@@ -87,12 +146,33 @@ func (c *ExecutableSchema) Make(ctx context.Context) (graphql.Schema, error) {
 		Subscription: subscription,
 		Types:        registry.typeArray(),
 		Directives:   registry.directiveArray(),
-		Extensions:   c.Extensions,
+		Extensions:   extensions,
 	}
 
 	schema, err := graphql.NewSchema(*schemaConfig)
-	// create a new schema
-	return schema, err
+	if err != nil {
+		return schema, err
+	}
+
+	if err := c.finishSchema(registry, schema); err != nil {
+		return graphql.Schema{}, err
+	}
+	return schema, nil
+}
+
+// applies the schema options that require the built schema
+func (c *ExecutableSchema) finishSchema(registry *registry, schema graphql.Schema) error {
+	if err := registry.decodeInputArguments(schema); err != nil {
+		return err
+	}
+	registry.resolveErrorData(schema)
+	if c.StrictResults {
+		validateResults(schema)
+	}
+	if c.Ancestry {
+		recordAncestry(schema)
+	}
+	return nil
 }
 
 // build a schema from an ast
@@ -0,0 +1,157 @@
+package schemadiff
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+)
+
+// Version a named schema snapshot such as a release tag or git revision
+type Version struct {
+	Name   string
+	Schema graphql.Schema
+}
+
+// TypeChanges the changes affecting a single type or directive
+type TypeChanges struct {
+	Type        string  `json:"type"`
+	Description string  `json:"description,omitempty"`
+	Changes     Changes `json:"changes"`
+}
+
+// Release the changes between two consecutive versions grouped by type
+type Release struct {
+	From      string        `json:"from"`
+	To        string        `json:"to"`
+	Breaking  []TypeChanges `json:"breaking"`
+	Dangerous []TypeChanges `json:"dangerous"`
+	Additive  []TypeChanges `json:"additive"`
+}
+
+// Empty determines if the release has no changes
+func (r Release) Empty() bool {
+	return len(r.Breaking) == 0 && len(r.Dangerous) == 0 && len(r.Additive) == 0
+}
+
+// Changelog releases ordered from the newest to the oldest
+type Changelog []Release
+
+// NewChangelog diffs each version against the previous, versions are
+// ordered from the oldest to the newest
+func NewChangelog(versions []Version) Changelog {
+	changelog := Changelog{}
+	for i := len(versions) - 1; i > 0; i-- {
+		from, to := versions[i-1], versions[i]
+		changes := Diff(from.Schema, to.Schema)
+
+		changelog = append(changelog, Release{
+			From:      from.Name,
+			To:        to.Name,
+			Breaking:  groupByType(changes.Filter(Breaking), from.Schema, to.Schema),
+			Dangerous: groupByType(changes.Filter(Dangerous), from.Schema, to.Schema),
+			Additive:  groupByType(changes.Filter(Safe), from.Schema, to.Schema),
+		})
+	}
+	return changelog
+}
+
+// groups changes by the type in their path, descriptions are taken from the
+// new schema or the old schema for removed types
+func groupByType(changes Changes, oldSchema, newSchema graphql.Schema) []TypeChanges {
+	groups := []TypeChanges{}
+	index := map[string]int{}
+
+	for _, change := range changes {
+		name := affectedType(change.Path)
+		i, ok := index[name]
+		if !ok {
+			i = len(groups)
+			index[name] = i
+			groups = append(groups, TypeChanges{
+				Type:        name,
+				Description: typeDescription(name, oldSchema, newSchema),
+				Changes:     Changes{},
+			})
+		}
+		groups[i].Changes = append(groups[i].Changes, change)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Type < groups[j].Type
+	})
+	return groups
+}
+
+// gets the type or directive name of a change path
+func affectedType(path string) string {
+	if i := strings.IndexAny(path, ".("); i != -1 {
+		return path[:i]
+	}
+	return path
+}
+
+func typeDescription(name string, oldSchema, newSchema graphql.Schema) string {
+	for _, schema := range []graphql.Schema{newSchema, oldSchema} {
+		if t := schema.Type(name); t != nil && describe(t) != "" {
+			return describe(t)
+		}
+		if strings.HasPrefix(name, "@") {
+			if directive := schema.Directive(strings.TrimPrefix(name, "@")); directive != nil && directive.Description != "" {
+				return directive.Description
+			}
+		}
+	}
+	return ""
+}
+
+// gets the description of a type, objects do not return their description
+// from Description in graphql-go
+func describe(t graphql.Type) string {
+	if object, ok := t.(*graphql.Object); ok {
+		return object.PrivateDescription
+	}
+	return t.Description()
+}
+
+// WriteMarkdown renders the changelog as markdown
+func (c Changelog) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("# Changelog\n")
+
+	for _, release := range c {
+		fmt.Fprintf(&b, "\n## %s\n\n", release.To)
+		fmt.Fprintf(&b, "Changes since %s.\n", release.From)
+
+		if release.Empty() {
+			b.WriteString("\nNo schema changes.\n")
+			continue
+		}
+
+		writeMarkdownSection(&b, "Breaking changes", release.Breaking)
+		writeMarkdownSection(&b, "Dangerous changes", release.Dangerous)
+		writeMarkdownSection(&b, "Additive changes", release.Additive)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeMarkdownSection(b *strings.Builder, title string, groups []TypeChanges) {
+	if len(groups) == 0 {
+		return
+	}
+
+	fmt.Fprintf(b, "\n### %s\n", title)
+	for _, group := range groups {
+		fmt.Fprintf(b, "\n#### `%s`\n\n", group.Type)
+		if group.Description != "" {
+			fmt.Fprintf(b, "> %s\n\n", strings.Join(strings.Split(strings.TrimSpace(group.Description), "\n"), "\n> "))
+		}
+		for _, change := range group.Changes {
+			fmt.Fprintf(b, "- %s\n", change.Message)
+		}
+	}
+}
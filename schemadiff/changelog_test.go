@@ -0,0 +1,97 @@
+package schemadiff
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestChangelog(t *testing.T) {
+	v1 := loadSchema(t, `
+"A user account"
+type User {
+	id: ID!
+	name: String
+	email: String
+}
+
+type Query {
+	user(id: ID!): User
+}`)
+
+	v2 := loadSchema(t, `
+"A user account"
+type User {
+	id: ID!
+	name: String
+}
+
+type Query {
+	user(id: ID!, active: Boolean): User
+}`)
+
+	v3 := loadSchema(t, `
+"A user account"
+type User {
+	id: ID!
+	name: String
+	createdAt: String
+}
+
+type Query {
+	user(id: ID!, active: Boolean): User
+}`)
+
+	changelog := NewChangelog([]Version{
+		{Name: "v1", Schema: v1},
+		{Name: "v2", Schema: v2},
+		{Name: "v3", Schema: v3},
+	})
+
+	if len(changelog) != 2 {
+		t.Fatalf("expected 2 releases, got %d", len(changelog))
+	}
+
+	latest := changelog[0]
+	if latest.From != "v2" || latest.To != "v3" {
+		t.Errorf("expected the newest release first, got %s..%s", latest.From, latest.To)
+	}
+	if len(latest.Breaking) != 0 || len(latest.Dangerous) != 0 || len(latest.Additive) != 1 {
+		t.Fatalf("unexpected v3 changes: %+v", latest)
+	}
+
+	previous := changelog[1]
+	if len(previous.Breaking) != 1 || previous.Breaking[0].Type != "User" {
+		t.Fatalf("expected a breaking change to User, got %+v", previous.Breaking)
+	}
+	if previous.Breaking[0].Description != "A user account" {
+		t.Errorf("expected the type description, got %q", previous.Breaking[0].Description)
+	}
+	if len(previous.Dangerous) != 1 || previous.Dangerous[0].Type != "Query" {
+		t.Errorf("expected a dangerous change to Query, got %+v", previous.Dangerous)
+	}
+
+	var buf bytes.Buffer
+	if err := changelog.WriteMarkdown(&buf); err != nil {
+		t.Fatal(err)
+	}
+	markdown := buf.String()
+
+	for _, expected := range []string{
+		"## v3",
+		"### Additive changes",
+		"- field User.createdAt of type String was added",
+		"## v2",
+		"### Breaking changes",
+		"> A user account",
+		"- field User.email was removed",
+		"### Dangerous changes",
+	} {
+		if !strings.Contains(markdown, expected) {
+			t.Errorf("expected markdown to contain %q\n%s", expected, markdown)
+		}
+	}
+	if strings.Index(markdown, "## v3") > strings.Index(markdown, "## v2") {
+		t.Errorf("expected releases from newest to oldest\n%s", markdown)
+	}
+}
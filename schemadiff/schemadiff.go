@@ -0,0 +1,460 @@
+// Package schemadiff compares two schemas and classifies each change
+// as breaking, dangerous or safe for existing clients
+package schemadiff
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+)
+
+// Criticality the impact of a change on existing clients
+type Criticality string
+
+// change criticality levels
+const (
+	// Breaking changes fail existing operations
+	Breaking Criticality = "BREAKING"
+	// Dangerous changes may change the behavior of existing operations
+	Dangerous Criticality = "DANGEROUS"
+	// Safe changes do not affect existing operations
+	Safe Criticality = "SAFE"
+)
+
+// Change a single difference between two schemas
+type Change struct {
+	Criticality Criticality `json:"criticality"`
+	Path        string      `json:"path"`
+	Message     string      `json:"message"`
+}
+
+// Changes a list of schema changes
+type Changes []Change
+
+// HasBreaking determines if any change is breaking
+func (c Changes) HasBreaking() bool {
+	return len(c.Filter(Breaking)) > 0
+}
+
+// Filter returns the changes with the criticality
+func (c Changes) Filter(criticality Criticality) Changes {
+	filtered := Changes{}
+	for _, change := range c {
+		if change.Criticality == criticality {
+			filtered = append(filtered, change)
+		}
+	}
+	return filtered
+}
+
+// Diff compares the old and new schema, changes are sorted by path
+func Diff(oldSchema, newSchema graphql.Schema) Changes {
+	d := &differ{changes: Changes{}}
+
+	oldTypes := userTypes(oldSchema)
+	newTypes := userTypes(newSchema)
+
+	for name, oldType := range oldTypes {
+		newType, ok := newTypes[name]
+		if !ok {
+			d.add(Breaking, name, "type %s was removed", name)
+			continue
+		}
+		d.diffType(oldType, newType)
+	}
+
+	for name, newType := range newTypes {
+		if _, ok := oldTypes[name]; !ok {
+			d.add(Safe, name, "%s %s was added", typeKind(newType), name)
+		}
+	}
+
+	d.diffRoot("query", oldSchema.QueryType(), newSchema.QueryType())
+	d.diffRoot("mutation", oldSchema.MutationType(), newSchema.MutationType())
+	d.diffRoot("subscription", oldSchema.SubscriptionType(), newSchema.SubscriptionType())
+	d.diffDirectives(oldSchema.Directives(), newSchema.Directives())
+
+	sort.SliceStable(d.changes, func(i, j int) bool {
+		return d.changes[i].Path < d.changes[j].Path
+	})
+	return d.changes
+}
+
+type differ struct {
+	changes Changes
+}
+
+func (d *differ) add(criticality Criticality, path, format string, args ...interface{}) {
+	d.changes = append(d.changes, Change{
+		Criticality: criticality,
+		Path:        path,
+		Message:     fmt.Sprintf(format, args...),
+	})
+}
+
+// gets the named types that are not introspection types
+func userTypes(schema graphql.Schema) map[string]graphql.Type {
+	types := map[string]graphql.Type{}
+	for name, t := range schema.TypeMap() {
+		if !strings.HasPrefix(name, "__") {
+			types[name] = t
+		}
+	}
+	return types
+}
+
+func typeKind(t graphql.Type) string {
+	switch t.(type) {
+	case *graphql.Object:
+		return "object"
+	case *graphql.Interface:
+		return "interface"
+	case *graphql.Union:
+		return "union"
+	case *graphql.Enum:
+		return "enum"
+	case *graphql.InputObject:
+		return "input"
+	case *graphql.Scalar:
+		return "scalar"
+	}
+	return "type"
+}
+
+func (d *differ) diffRoot(operation string, oldRoot, newRoot *graphql.Object) {
+	oldName, newName := "", ""
+	if oldRoot != nil {
+		oldName = oldRoot.Name()
+	}
+	if newRoot != nil {
+		newName = newRoot.Name()
+	}
+
+	switch {
+	case oldName == newName:
+	case newName == "":
+		d.add(Breaking, "schema."+operation, "%s root type %s was removed", operation, oldName)
+	case oldName == "":
+		d.add(Safe, "schema."+operation, "%s root type %s was added", operation, newName)
+	default:
+		d.add(Breaking, "schema."+operation, "%s root type changed from %s to %s", operation, oldName, newName)
+	}
+}
+
+func (d *differ) diffType(oldType, newType graphql.Type) {
+	name := oldType.Name()
+	if typeKind(oldType) != typeKind(newType) {
+		d.add(Breaking, name, "%s changed from %s to %s", name, typeKind(oldType), typeKind(newType))
+		return
+	}
+
+	if describe(oldType) != describe(newType) {
+		d.add(Safe, name, "description of %s changed", name)
+	}
+
+	switch o := oldType.(type) {
+	case *graphql.Object:
+		n := newType.(*graphql.Object)
+		d.diffFields(name, o.Fields(), n.Fields())
+		d.diffInterfaces(name, o.Interfaces(), n.Interfaces())
+	case *graphql.Interface:
+		d.diffFields(name, o.Fields(), newType.(*graphql.Interface).Fields())
+	case *graphql.Union:
+		d.diffUnion(name, o.Types(), newType.(*graphql.Union).Types())
+	case *graphql.Enum:
+		d.diffEnum(name, o.Values(), newType.(*graphql.Enum).Values())
+	case *graphql.InputObject:
+		d.diffInputFields(name, o.Fields(), newType.(*graphql.InputObject).Fields())
+	}
+}
+
+func (d *differ) diffFields(typeName string, oldFields, newFields graphql.FieldDefinitionMap) {
+	for name, oldField := range oldFields {
+		path := typeName + "." + name
+		newField, ok := newFields[name]
+		if !ok {
+			if oldField.DeprecationReason != "" {
+				d.add(Breaking, path, "deprecated field %s was removed", path)
+			} else {
+				d.add(Breaking, path, "field %s was removed", path)
+			}
+			continue
+		}
+
+		if !isSafeOutputChange(oldField.Type, newField.Type) {
+			d.add(Breaking, path, "field %s changed type from %s to %s", path, oldField.Type, newField.Type)
+		} else if oldField.Type.String() != newField.Type.String() {
+			d.add(Safe, path, "field %s changed type from %s to %s", path, oldField.Type, newField.Type)
+		}
+
+		if oldField.DeprecationReason == "" && newField.DeprecationReason != "" {
+			d.add(Safe, path, "field %s was deprecated", path)
+		} else if oldField.DeprecationReason != "" && newField.DeprecationReason == "" {
+			d.add(Safe, path, "field %s is no longer deprecated", path)
+		}
+
+		if oldField.Description != newField.Description {
+			d.add(Safe, path, "description of %s changed", path)
+		}
+
+		d.diffArgs(path, oldField.Args, newField.Args)
+	}
+
+	for name, newField := range newFields {
+		if _, ok := oldFields[name]; !ok {
+			path := typeName + "." + name
+			d.add(Safe, path, "field %s of type %s was added", path, newField.Type)
+		}
+	}
+}
+
+func (d *differ) diffArgs(fieldPath string, oldArgs, newArgs []*graphql.Argument) {
+	oldMap := map[string]*graphql.Argument{}
+	for _, arg := range oldArgs {
+		oldMap[arg.Name()] = arg
+	}
+
+	newMap := map[string]*graphql.Argument{}
+	for _, arg := range newArgs {
+		newMap[arg.Name()] = arg
+	}
+
+	for name, oldArg := range oldMap {
+		path := fieldPath + "(" + name + ")"
+		newArg, ok := newMap[name]
+		if !ok {
+			d.add(Breaking, path, "argument %s was removed", path)
+			continue
+		}
+
+		if !isSafeInputChange(oldArg.Type, newArg.Type) {
+			d.add(Breaking, path, "argument %s changed type from %s to %s", path, oldArg.Type, newArg.Type)
+		} else if oldArg.Type.String() != newArg.Type.String() {
+			d.add(Safe, path, "argument %s changed type from %s to %s", path, oldArg.Type, newArg.Type)
+		}
+
+		if !reflect.DeepEqual(oldArg.DefaultValue, newArg.DefaultValue) {
+			d.add(Dangerous, path, "default value of argument %s changed from %v to %v", path, oldArg.DefaultValue, newArg.DefaultValue)
+		}
+	}
+
+	for name, newArg := range newMap {
+		if _, ok := oldMap[name]; ok {
+			continue
+		}
+
+		path := fieldPath + "(" + name + ")"
+		if isRequired(newArg.Type, newArg.DefaultValue) {
+			d.add(Breaking, path, "required argument %s was added", path)
+		} else {
+			d.add(Dangerous, path, "optional argument %s was added", path)
+		}
+	}
+}
+
+func (d *differ) diffInputFields(typeName string, oldFields, newFields graphql.InputObjectFieldMap) {
+	for name, oldField := range oldFields {
+		path := typeName + "." + name
+		newField, ok := newFields[name]
+		if !ok {
+			d.add(Breaking, path, "input field %s was removed", path)
+			continue
+		}
+
+		if !isSafeInputChange(oldField.Type, newField.Type) {
+			d.add(Breaking, path, "input field %s changed type from %s to %s", path, oldField.Type, newField.Type)
+		} else if oldField.Type.String() != newField.Type.String() {
+			d.add(Safe, path, "input field %s changed type from %s to %s", path, oldField.Type, newField.Type)
+		}
+
+		if !reflect.DeepEqual(oldField.DefaultValue, newField.DefaultValue) {
+			d.add(Dangerous, path, "default value of input field %s changed from %v to %v", path, oldField.DefaultValue, newField.DefaultValue)
+		}
+	}
+
+	for name, newField := range newFields {
+		if _, ok := oldFields[name]; ok {
+			continue
+		}
+
+		path := typeName + "." + name
+		if isRequired(newField.Type, newField.DefaultValue) {
+			d.add(Breaking, path, "required input field %s was added", path)
+		} else {
+			d.add(Dangerous, path, "optional input field %s was added", path)
+		}
+	}
+}
+
+func (d *differ) diffInterfaces(typeName string, oldIfaces, newIfaces []*graphql.Interface) {
+	oldNames := map[string]bool{}
+	for _, iface := range oldIfaces {
+		oldNames[iface.Name()] = true
+	}
+
+	newNames := map[string]bool{}
+	for _, iface := range newIfaces {
+		newNames[iface.Name()] = true
+	}
+
+	for name := range oldNames {
+		if !newNames[name] {
+			d.add(Breaking, typeName, "%s no longer implements interface %s", typeName, name)
+		}
+	}
+	for name := range newNames {
+		if !oldNames[name] {
+			d.add(Dangerous, typeName, "%s implements interface %s", typeName, name)
+		}
+	}
+}
+
+func (d *differ) diffUnion(typeName string, oldTypes, newTypes []*graphql.Object) {
+	oldNames := map[string]bool{}
+	for _, t := range oldTypes {
+		oldNames[t.Name()] = true
+	}
+
+	newNames := map[string]bool{}
+	for _, t := range newTypes {
+		newNames[t.Name()] = true
+	}
+
+	for name := range oldNames {
+		if !newNames[name] {
+			d.add(Breaking, typeName, "member %s was removed from union %s", name, typeName)
+		}
+	}
+	for name := range newNames {
+		if !oldNames[name] {
+			d.add(Dangerous, typeName, "member %s was added to union %s", name, typeName)
+		}
+	}
+}
+
+func (d *differ) diffEnum(typeName string, oldValues, newValues []*graphql.EnumValueDefinition) {
+	oldMap := map[string]*graphql.EnumValueDefinition{}
+	for _, value := range oldValues {
+		oldMap[value.Name] = value
+	}
+
+	newMap := map[string]*graphql.EnumValueDefinition{}
+	for _, value := range newValues {
+		newMap[value.Name] = value
+	}
+
+	for name, oldValue := range oldMap {
+		path := typeName + "." + name
+		newValue, ok := newMap[name]
+		if !ok {
+			d.add(Breaking, path, "enum value %s was removed", path)
+			continue
+		}
+		if oldValue.DeprecationReason == "" && newValue.DeprecationReason != "" {
+			d.add(Safe, path, "enum value %s was deprecated", path)
+		}
+	}
+
+	for name := range newMap {
+		if _, ok := oldMap[name]; !ok {
+			path := typeName + "." + name
+			d.add(Dangerous, path, "enum value %s was added", path)
+		}
+	}
+}
+
+func (d *differ) diffDirectives(oldDirectives, newDirectives []*graphql.Directive) {
+	oldMap := map[string]*graphql.Directive{}
+	for _, directive := range oldDirectives {
+		oldMap[directive.Name] = directive
+	}
+
+	newMap := map[string]*graphql.Directive{}
+	for _, directive := range newDirectives {
+		newMap[directive.Name] = directive
+	}
+
+	for name, oldDirective := range oldMap {
+		path := "@" + name
+		newDirective, ok := newMap[name]
+		if !ok {
+			d.add(Breaking, path, "directive %s was removed", path)
+			continue
+		}
+
+		locations := map[string]bool{}
+		for _, loc := range newDirective.Locations {
+			locations[loc] = true
+		}
+		for _, loc := range oldDirective.Locations {
+			if !locations[loc] {
+				d.add(Breaking, path, "location %s was removed from directive %s", loc, path)
+			}
+		}
+
+		d.diffArgs(path, oldDirective.Args, newDirective.Args)
+	}
+
+	for name := range newMap {
+		if _, ok := oldMap[name]; !ok {
+			d.add(Safe, "@"+name, "directive @%s was added", name)
+		}
+	}
+}
+
+// determines if an argument or input field must be supplied
+func isRequired(t graphql.Type, defaultValue interface{}) bool {
+	_, nonNull := t.(*graphql.NonNull)
+	return nonNull && defaultValue == nil
+}
+
+// output types may become non-null but not nullable
+func isSafeOutputChange(oldType, newType graphql.Type) bool {
+	switch o := oldType.(type) {
+	case *graphql.NonNull:
+		n, ok := newType.(*graphql.NonNull)
+		return ok && isSafeOutputChange(o.OfType, n.OfType)
+	case *graphql.List:
+		if n, ok := newType.(*graphql.NonNull); ok {
+			newType = n.OfType
+		}
+		n, ok := newType.(*graphql.List)
+		return ok && isSafeOutputChange(o.OfType, n.OfType)
+	default:
+		if n, ok := newType.(*graphql.NonNull); ok {
+			newType = n.OfType
+		}
+		return namedTypeName(newType) != "" && namedTypeName(oldType) == namedTypeName(newType)
+	}
+}
+
+// input types may become nullable but not non-null
+func isSafeInputChange(oldType, newType graphql.Type) bool {
+	switch n := newType.(type) {
+	case *graphql.NonNull:
+		o, ok := oldType.(*graphql.NonNull)
+		return ok && isSafeInputChange(o.OfType, n.OfType)
+	case *graphql.List:
+		if o, ok := oldType.(*graphql.NonNull); ok {
+			oldType = o.OfType
+		}
+		o, ok := oldType.(*graphql.List)
+		return ok && isSafeInputChange(o.OfType, n.OfType)
+	default:
+		if o, ok := oldType.(*graphql.NonNull); ok {
+			oldType = o.OfType
+		}
+		return namedTypeName(oldType) != "" && namedTypeName(oldType) == namedTypeName(newType)
+	}
+}
+
+func namedTypeName(t graphql.Type) string {
+	switch t.(type) {
+	case *graphql.List, *graphql.NonNull, nil:
+		return ""
+	}
+	return t.Name()
+}
@@ -0,0 +1,125 @@
+package schemadiff
+
+import (
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/rohit20001221/graphql-go-tools/codegen"
+)
+
+func loadSchema(t *testing.T, typeDefs string) graphql.Schema {
+	schema, err := codegen.LoadSchema(typeDefs)
+	if err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+	return schema
+}
+
+func TestDiff(t *testing.T) {
+	oldSchema := loadSchema(t, `
+enum Role { ADMIN USER }
+
+input UserFilter {
+	name: String
+}
+
+type User {
+	id: ID!
+	name: String
+	email: String
+	role: Role
+}
+
+type Query {
+	user(id: ID!): User
+	users(filter: UserFilter, limit: Int = 10): [User]
+}`)
+
+	newSchema := loadSchema(t, `
+enum Role { ADMIN USER GUEST }
+
+input UserFilter {
+	name: String
+	active: Boolean!
+}
+
+type User {
+	id: ID!
+	name: String!
+	role: Role
+	createdAt: String
+}
+
+type Group {
+	name: String
+}
+
+type Query {
+	user(id: ID): User
+	users(filter: UserFilter, limit: Int = 20, offset: Int): [User]
+}`)
+
+	expected := map[string]Criticality{
+		"Group":               Safe,
+		"Query.users(limit)":  Dangerous,
+		"Query.users(offset)": Dangerous,
+		"Query.user(id)":      Safe,
+		"Role.GUEST":          Dangerous,
+		"User.createdAt":      Safe,
+		"User.email":          Breaking,
+		"User.name":           Safe,
+		"UserFilter.active":   Breaking,
+	}
+
+	changes := Diff(oldSchema, newSchema)
+	found := map[string]Criticality{}
+	for _, change := range changes {
+		found[change.Path] = change.Criticality
+	}
+
+	for path, criticality := range expected {
+		if found[path] != criticality {
+			t.Errorf("expected %s change for %s, got %q", criticality, path, found[path])
+		}
+	}
+
+	if len(changes) != len(expected) {
+		t.Errorf("expected %d changes, got %d: %v", len(expected), len(changes), changes)
+	}
+
+	if !changes.HasBreaking() {
+		t.Error("expected breaking changes")
+	}
+}
+
+func TestDiffOutputNullability(t *testing.T) {
+	oldSchema := loadSchema(t, `type Query { names: [String!]! }`)
+	newSchema := loadSchema(t, `type Query { names: [String] }`)
+
+	changes := Diff(oldSchema, newSchema)
+	if len(changes) != 1 || changes[0].Criticality != Breaking {
+		t.Errorf("expected a single breaking change, got %v", changes)
+	}
+
+	if changes := Diff(newSchema, oldSchema); changes.HasBreaking() {
+		t.Errorf("expected no breaking changes, got %v", changes)
+	}
+}
+
+func TestDiffObjectDescription(t *testing.T) {
+	oldSchema := loadSchema(t, `
+"A user"
+type User { id: ID! }
+
+type Query { user: User }`)
+	newSchema := loadSchema(t, `
+"A registered user"
+type User { id: ID! }
+
+type Query { user: User }`)
+
+	changes := Diff(oldSchema, newSchema)
+	if len(changes) != 1 || changes[0].Path != "User" || changes[0].Criticality != Safe {
+		t.Errorf("expected a safe description change of User, got %v", changes)
+	}
+}
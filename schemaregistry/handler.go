@@ -0,0 +1,172 @@
+package schemaregistry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// request body size limit for published schemas
+const maxSchemaSize = 10 << 20
+
+// Handler returns the registry http handler
+//
+//	GET  /schemas/{service}/{variant}                    latest SDL, ?format=introspection or ?format=json
+//	POST /schemas/{service}/{variant}                    publish SDL, ?force=true to allow breaking changes, ?dryRun=true to check only
+//	GET  /schemas/{service}/{variant}/history            version history without SDL
+//	GET  /schemas/{service}/{variant}/versions/{version} SDL of a version, supports ?format
+func (c *Registry) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /schemas/{service}/{variant}", c.handleLatest)
+	mux.HandleFunc("POST /schemas/{service}/{variant}", c.handlePublish)
+	mux.HandleFunc("GET /schemas/{service}/{variant}/history", c.handleHistory)
+	mux.HandleFunc("GET /schemas/{service}/{variant}/versions/{version}", c.handleVersion)
+	return mux
+}
+
+// publish request body for application/json requests
+type publishRequest struct {
+	SDL   string `json:"sdl"`
+	Force bool   `json:"force"`
+}
+
+func (c *Registry) handleLatest(w http.ResponseWriter, r *http.Request) {
+	version, err := c.Store.Latest(r.Context(), r.PathValue("service"), r.PathValue("variant"))
+	if err != nil {
+		c.writeError(w, err)
+		return
+	}
+	c.writeVersion(w, r, version)
+}
+
+func (c *Registry) handleVersion(w http.ResponseWriter, r *http.Request) {
+	number, err := strconv.Atoi(r.PathValue("version"))
+	if err != nil {
+		http.Error(w, "invalid version", http.StatusBadRequest)
+		return
+	}
+
+	version, err := c.Store.Get(r.Context(), r.PathValue("service"), r.PathValue("variant"), number)
+	if err != nil {
+		c.writeError(w, err)
+		return
+	}
+	c.writeVersion(w, r, version)
+}
+
+func (c *Registry) handleHistory(w http.ResponseWriter, r *http.Request) {
+	history, err := c.Store.History(r.Context(), r.PathValue("service"), r.PathValue("variant"))
+	if err != nil {
+		c.writeError(w, err)
+		return
+	}
+
+	versions := []*SchemaVersion{}
+	for _, version := range history {
+		v := *version
+		v.SDL = ""
+		versions = append(versions, &v)
+	}
+	writeJSON(w, http.StatusOK, versions)
+}
+
+func (c *Registry) handlePublish(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSchemaSize))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		http.Error(w, fmt.Sprintf("schema exceeds the maximum size of %d bytes", maxSchemaSize), http.StatusRequestEntityTooLarge)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	req := publishRequest{SDL: string(body)}
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		req = publishRequest{}
+		if err := json.Unmarshal(body, &req); err != nil {
+			http.Error(w, "invalid JSON body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	force := req.Force || queryBool(r, "force")
+	service, variant := r.PathValue("service"), r.PathValue("variant")
+
+	if queryBool(r, "dryRun") {
+		changes, err := c.Check(r.Context(), service, variant, req.SDL)
+		if err != nil {
+			c.writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, &PublishResult{Changes: changes})
+		return
+	}
+
+	result, err := c.Publish(r.Context(), service, variant, req.SDL, force)
+	var breaking *BreakingChangesError
+	if errors.As(err, &breaking) {
+		writeJSON(w, http.StatusConflict, result)
+		return
+	} else if err != nil {
+		c.writeError(w, err)
+		return
+	}
+
+	status := http.StatusOK
+	if result.Published {
+		status = http.StatusCreated
+	}
+	writeJSON(w, status, result)
+}
+
+// writes a version as SDL, introspection or JSON
+func (c *Registry) writeVersion(w http.ResponseWriter, r *http.Request, version *SchemaVersion) {
+	w.Header().Set("X-Schema-Version", strconv.Itoa(version.Version))
+
+	switch r.URL.Query().Get("format") {
+	case "introspection":
+		result, err := c.Introspect(version)
+		if err != nil {
+			c.writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, result)
+	case "json":
+		writeJSON(w, http.StatusOK, version)
+	default:
+		w.Header().Set("Content-Type", "application/graphql; charset=utf-8")
+		io.WriteString(w, version.SDL)
+	}
+}
+
+func (c *Registry) writeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if errors.Is(err, ErrInvalid) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// storage errors can contain file paths, they are only logged
+	c.log().Errorf("schema registry: %v", err)
+	http.Error(w, "internal schema registry error", http.StatusInternalServerError)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func queryBool(r *http.Request, name string) bool {
+	value, _ := strconv.ParseBool(r.URL.Query().Get(name))
+	return value
+}
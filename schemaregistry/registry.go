@@ -0,0 +1,158 @@
+// Package schemaregistry provides a schema registry storing versioned schemas
+// per service and variant. Schemas are checked for breaking changes against
+// the latest version when they are published
+package schemaregistry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	tools "github.com/rohit20001221/graphql-go-tools"
+	"github.com/rohit20001221/graphql-go-tools/codegen"
+	"github.com/rohit20001221/graphql-go-tools/schemadiff"
+	"github.com/rohit20001221/graphql-go-tools/server/logger"
+)
+
+// BreakingChangesError is returned when publishing a schema with breaking changes
+type BreakingChangesError struct {
+	Changes schemadiff.Changes
+}
+
+func (e *BreakingChangesError) Error() string {
+	return fmt.Sprintf("schema has %d breaking changes", len(e.Changes.Filter(schemadiff.Breaking)))
+}
+
+// PublishResult the result of publishing or checking a schema
+type PublishResult struct {
+	Published bool               `json:"published"`
+	Version   *SchemaVersion     `json:"version,omitempty"`
+	Changes   schemadiff.Changes `json:"changes"`
+}
+
+// Registry the schema registry
+type Registry struct {
+	Store  Store
+	Logger logger.Logger
+
+	// publish locks per service and variant
+	locks sync.Map
+}
+
+// New creates a new registry
+func New(store Store) *Registry {
+	return &Registry{
+		Store:  store,
+		Logger: &logger.NoopLogger{},
+	}
+}
+
+// Check compares a schema to the latest version without publishing it
+func (c *Registry) Check(ctx context.Context, service, variant, sdl string) (schemadiff.Changes, error) {
+	if err := validateName("service", service); err != nil {
+		return nil, err
+	}
+	if err := validateName("variant", variant); err != nil {
+		return nil, err
+	}
+
+	schema, err := codegen.LoadSchema(sdl)
+	if err != nil {
+		return nil, fmt.Errorf("%w schema: %v", ErrInvalid, err)
+	}
+
+	latest, err := c.Store.Latest(ctx, service, variant)
+	if errors.Is(err, ErrNotFound) {
+		return schemadiff.Changes{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	latestSchema, err := codegen.LoadSchema(latest.SDL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load version %d: %v", latest.Version, err)
+	}
+
+	return schemadiff.Diff(latestSchema, schema), nil
+}
+
+// Publish stores a schema as a new version. Schemas with breaking changes are
+// rejected with a BreakingChangesError unless force is true. Publishing a schema
+// identical to the latest version returns the latest version. Publishes to the
+// same service and variant are serialized so each version is checked against
+// the version before it
+func (c *Registry) Publish(ctx context.Context, service, variant, sdl string, force bool) (*PublishResult, error) {
+	// the check against the latest version and the save must not interleave
+	// with another publish or a version would be saved unchecked
+	unlock := c.lock(service, variant)
+	defer unlock()
+
+	changes, err := c.Check(ctx, service, variant, sdl)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := hashSDL(sdl)
+	latest, err := c.Store.Latest(ctx, service, variant)
+	if err == nil && latest.Hash == hash {
+		return &PublishResult{Version: latest, Changes: changes}, nil
+	} else if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	if changes.HasBreaking() && !force {
+		return &PublishResult{Changes: changes}, &BreakingChangesError{Changes: changes}
+	}
+
+	version := &SchemaVersion{
+		Service:   service,
+		Variant:   variant,
+		Hash:      hash,
+		SDL:       sdl,
+		Changes:   changes,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := c.Store.Save(ctx, version); err != nil {
+		return nil, err
+	}
+
+	c.log().Infof("published %s/%s version %d with %d changes", service, variant, version.Version, len(changes))
+	return &PublishResult{Published: true, Version: version, Changes: changes}, nil
+}
+
+// Introspect executes the introspection query against a schema version
+func (c *Registry) Introspect(version *SchemaVersion) (*graphql.Result, error) {
+	schema, err := codegen.LoadSchema(version.SDL)
+	if err != nil {
+		return nil, err
+	}
+
+	return graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: tools.IntrospectionQuery,
+	}), nil
+}
+
+// locks publishing to a service and variant
+func (c *Registry) lock(service, variant string) func() {
+	mx, _ := c.locks.LoadOrStore(memoryKey(service, variant), &sync.Mutex{})
+	mx.(*sync.Mutex).Lock()
+	return mx.(*sync.Mutex).Unlock
+}
+
+func (c *Registry) log() logger.Logger {
+	if c.Logger == nil {
+		return &logger.NoopLogger{}
+	}
+	return c.Logger
+}
+
+func hashSDL(sdl string) string {
+	sum := sha256.Sum256([]byte(sdl))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,203 @@
+package schemaregistry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRegistryHandler(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(New(store).Handler())
+	defer server.Close()
+
+	publish := func(sdl, query string) (*http.Response, *PublishResult) {
+		res, err := http.Post(server.URL+"/schemas/users/prod"+query, "application/graphql", strings.NewReader(sdl))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+
+		var result PublishResult
+		if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+			t.Fatalf("failed to decode publish result: %v", err)
+		}
+		return res, &result
+	}
+
+	res, result := publish(`type Query { user(id: ID!): String, users: [String] }`, "")
+	if res.StatusCode != http.StatusCreated || result.Version.Version != 1 {
+		t.Fatalf("expected version 1 to be created, got %d %+v", res.StatusCode, result)
+	}
+
+	res, _ = publish(`type Query { user(id: ID!): String, users: [String] }`, "")
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("expected an unchanged schema not to be published, got %d", res.StatusCode)
+	}
+
+	res, result = publish(`type Query { user(id: ID!): String }`, "")
+	if res.StatusCode != http.StatusConflict || !result.Changes.HasBreaking() {
+		t.Fatalf("expected breaking changes to be rejected, got %d %+v", res.StatusCode, result)
+	}
+
+	res, result = publish(`type Query { user(id: ID!): String }`, "?force=true")
+	if res.StatusCode != http.StatusCreated || result.Version.Version != 2 {
+		t.Fatalf("expected version 2 to be forced, got %d %+v", res.StatusCode, result)
+	}
+
+	res, err = http.Get(server.URL + "/schemas/users/prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	if string(body) != `type Query { user(id: ID!): String }` || res.Header.Get("X-Schema-Version") != "2" {
+		t.Errorf("unexpected latest schema %q", body)
+	}
+
+	res, err = http.Get(server.URL + "/schemas/users/prod/history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var history []*SchemaVersion
+	json.NewDecoder(res.Body).Decode(&history)
+	res.Body.Close()
+	if len(history) != 2 || history[1].SDL != "" {
+		t.Errorf("expected 2 versions without SDL, got %+v", history)
+	}
+
+	res, err = http.Get(server.URL + "/schemas/users/prod/versions/1?format=introspection")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var introspection map[string]interface{}
+	json.NewDecoder(res.Body).Decode(&introspection)
+	res.Body.Close()
+	if _, ok := introspection["data"].(map[string]interface{})["__schema"]; !ok {
+		t.Errorf("expected an introspection result, got %v", introspection)
+	}
+
+	res, err = http.Get(server.URL + "/schemas/orders/prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusNotFound {
+		t.Errorf("expected not found for an unknown service, got %d", res.StatusCode)
+	}
+
+	res, err = http.Post(server.URL+"/schemas/users/prod", "application/graphql", strings.NewReader("type Query { a: String }"+strings.Repeat(" ", maxSchemaSize)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected an oversized schema to be rejected, got %d", res.StatusCode)
+	}
+}
+
+// widens the window between reading the latest version and saving
+type slowStore struct {
+	*MemoryStore
+}
+
+func (c slowStore) Latest(ctx context.Context, service, variant string) (*SchemaVersion, error) {
+	time.Sleep(5 * time.Millisecond)
+	return c.MemoryStore.Latest(ctx, service, variant)
+}
+
+func TestRegistryConcurrentPublish(t *testing.T) {
+	ctx := context.Background()
+
+	// each schema is additive to the first version but removes the field
+	// added by the other, so only one of them can be published
+	for i := 0; i < 5; i++ {
+		registry := New(slowStore{NewMemoryStore()})
+		if _, err := registry.Publish(ctx, "users", "prod", `type Query { a: String }`, false); err != nil {
+			t.Fatal(err)
+		}
+
+		var wg sync.WaitGroup
+		errs := make([]error, 2)
+		for n, sdl := range []string{`type Query { a: String, b: String }`, `type Query { a: String, c: String }`} {
+			wg.Add(1)
+			go func(n int, sdl string) {
+				defer wg.Done()
+				_, errs[n] = registry.Publish(ctx, "users", "prod", sdl, false)
+			}(n, sdl)
+		}
+		wg.Wait()
+
+		rejected := 0
+		for _, err := range errs {
+			var breaking *BreakingChangesError
+			if errors.As(err, &breaking) {
+				rejected++
+			} else if err != nil {
+				t.Fatal(err)
+			}
+		}
+		if rejected != 1 {
+			t.Fatalf("expected exactly one concurrent publish to be rejected, %d were", rejected)
+		}
+	}
+}
+
+// a store failing with errors that contain file paths
+type failingStore struct {
+	Store
+}
+
+func (s *failingStore) Latest(ctx context.Context, service, variant string) (*SchemaVersion, error) {
+	return nil, errors.New("open /var/lib/schemas/users/prod/latest.json: permission denied")
+}
+
+// records the logged errors
+type errorLogger struct {
+	mx     sync.Mutex
+	errors []string
+}
+
+func (l *errorLogger) Infof(format string, data ...interface{})  {}
+func (l *errorLogger) Debugf(format string, data ...interface{}) {}
+func (l *errorLogger) Warnf(format string, data ...interface{})  {}
+func (l *errorLogger) Errorf(format string, data ...interface{}) {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	l.errors = append(l.errors, fmt.Sprintf(format, data...))
+}
+
+func TestRegistryHandlerStoreError(t *testing.T) {
+	log := &errorLogger{}
+	registry := New(&failingStore{})
+	registry.Logger = log
+
+	server := httptest.NewServer(registry.Handler())
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/schemas/users/prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusInternalServerError || strings.Contains(string(body), "/var/lib") {
+		t.Errorf("expected a generic internal error, got %d %s", res.StatusCode, body)
+	}
+	if len(log.errors) != 1 || !strings.Contains(log.errors[0], "/var/lib/schemas") {
+		t.Errorf("expected the store error to be logged, got %v", log.errors)
+	}
+}
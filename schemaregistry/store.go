@@ -0,0 +1,290 @@
+package schemaregistry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rohit20001221/graphql-go-tools/schemadiff"
+)
+
+// ErrNotFound is returned when a service, variant or version has no schema
+var ErrNotFound = errors.New("schema not found")
+
+// ErrInvalid is wrapped by errors for invalid names and schemas
+var ErrInvalid = errors.New("invalid")
+
+var nameRx = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]*$`)
+
+// SchemaVersion a published schema
+type SchemaVersion struct {
+	Service   string             `json:"service"`
+	Variant   string             `json:"variant"`
+	Version   int                `json:"version"`
+	Hash      string             `json:"hash"`
+	SDL       string             `json:"sdl,omitempty"`
+	Changes   schemadiff.Changes `json:"changes"`
+	CreatedAt time.Time          `json:"createdAt"`
+}
+
+// Store stores versioned schemas per service and variant
+type Store interface {
+	// Latest gets the latest version or ErrNotFound
+	Latest(ctx context.Context, service, variant string) (*SchemaVersion, error)
+	// Get gets a version or ErrNotFound
+	Get(ctx context.Context, service, variant string, version int) (*SchemaVersion, error)
+	// History gets all versions, oldest first
+	History(ctx context.Context, service, variant string) ([]*SchemaVersion, error)
+	// Save stores a schema as the next version and sets its Version
+	Save(ctx context.Context, schema *SchemaVersion) error
+}
+
+// validates a service or variant name
+func validateName(kind, name string) error {
+	if !nameRx.MatchString(name) {
+		return fmt.Errorf("%w %s name %q", ErrInvalid, kind, name)
+	}
+	return nil
+}
+
+// MemoryStore an in-memory Store
+type MemoryStore struct {
+	mx       sync.RWMutex
+	versions map[string][]*SchemaVersion
+}
+
+// NewMemoryStore creates a new in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		versions: map[string][]*SchemaVersion{},
+	}
+}
+
+func memoryKey(service, variant string) string {
+	return service + "/" + variant
+}
+
+// Latest gets the latest version
+func (c *MemoryStore) Latest(ctx context.Context, service, variant string) (*SchemaVersion, error) {
+	c.mx.RLock()
+	defer c.mx.RUnlock()
+
+	versions := c.versions[memoryKey(service, variant)]
+	if len(versions) == 0 {
+		return nil, ErrNotFound
+	}
+	return versions[len(versions)-1], nil
+}
+
+// Get gets a version
+func (c *MemoryStore) Get(ctx context.Context, service, variant string, version int) (*SchemaVersion, error) {
+	c.mx.RLock()
+	defer c.mx.RUnlock()
+
+	versions := c.versions[memoryKey(service, variant)]
+	if version < 1 || version > len(versions) {
+		return nil, ErrNotFound
+	}
+	return versions[version-1], nil
+}
+
+// History gets all versions
+func (c *MemoryStore) History(ctx context.Context, service, variant string) ([]*SchemaVersion, error) {
+	c.mx.RLock()
+	defer c.mx.RUnlock()
+
+	versions := c.versions[memoryKey(service, variant)]
+	if len(versions) == 0 {
+		return nil, ErrNotFound
+	}
+	return append([]*SchemaVersion{}, versions...), nil
+}
+
+// Save stores the next version
+func (c *MemoryStore) Save(ctx context.Context, schema *SchemaVersion) error {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	key := memoryKey(schema.Service, schema.Variant)
+	schema.Version = len(c.versions[key]) + 1
+	c.versions[key] = append(c.versions[key], schema)
+	return nil
+}
+
+// FileStore stores each version as a JSON file in <dir>/<service>/<variant>
+type FileStore struct {
+	mx  sync.Mutex
+	dir string
+}
+
+// NewFileStore creates a file store in a directory, the directory is created if required
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+func (c *FileStore) variantDir(service, variant string) (string, error) {
+	if err := validateName("service", service); err != nil {
+		return "", err
+	}
+	if err := validateName("variant", variant); err != nil {
+		return "", err
+	}
+	return filepath.Join(c.dir, service, variant), nil
+}
+
+// lists the version numbers in ascending order
+func (c *FileStore) versions(dir string) ([]int, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return []int{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	versions := []int{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		if version, err := strconv.Atoi(strings.TrimSuffix(name, ".json")); err == nil {
+			versions = append(versions, version)
+		}
+	}
+	sort.Ints(versions)
+	return versions, nil
+}
+
+func (c *FileStore) read(dir string, version int) (*SchemaVersion, error) {
+	data, err := os.ReadFile(filepath.Join(dir, fmt.Sprintf("%06d.json", version)))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	var schema SchemaVersion
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("failed to read version %d: %v", version, err)
+	}
+	return &schema, nil
+}
+
+// Latest gets the latest version
+func (c *FileStore) Latest(ctx context.Context, service, variant string) (*SchemaVersion, error) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	dir, err := c.variantDir(service, variant)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := c.versions(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, ErrNotFound
+	}
+	return c.read(dir, versions[len(versions)-1])
+}
+
+// Get gets a version
+func (c *FileStore) Get(ctx context.Context, service, variant string, version int) (*SchemaVersion, error) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	dir, err := c.variantDir(service, variant)
+	if err != nil {
+		return nil, err
+	}
+	return c.read(dir, version)
+}
+
+// History gets all versions
+func (c *FileStore) History(ctx context.Context, service, variant string) ([]*SchemaVersion, error) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	dir, err := c.variantDir(service, variant)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := c.versions(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, ErrNotFound
+	}
+
+	history := []*SchemaVersion{}
+	for _, version := range versions {
+		schema, err := c.read(dir, version)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, schema)
+	}
+	return history, nil
+}
+
+// Save writes the next version, files are written to a temporary file and
+// renamed so readers never see partial versions
+func (c *FileStore) Save(ctx context.Context, schema *SchemaVersion) error {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	dir, err := c.variantDir(schema.Service, schema.Variant)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	versions, err := c.versions(dir)
+	if err != nil {
+		return err
+	}
+
+	schema.Version = 1
+	if len(versions) > 0 {
+		schema.Version = versions[len(versions)-1] + 1
+	}
+
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, ".version-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filepath.Join(dir, fmt.Sprintf("%06d.json", schema.Version)))
+}
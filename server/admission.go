@@ -0,0 +1,334 @@
+package server
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// admission defaults
+const (
+	DefaultAdmissionQueueTimeout = time.Second
+	DefaultAdmissionRetryAfter   = time.Second
+	DefaultAdaptiveWindow        = time.Second
+)
+
+// errOverloaded is returned to websocket operations rejected by admission control
+var errOverloaded = errors.New("server is overloaded, retry later")
+
+// ClassifyFunc gets the priority of a request, higher priorities leave the
+// queue first and displace lower priorities when the queue is full
+type ClassifyFunc func(r *http.Request, opts *RequestOptions) int
+
+// AdmissionOptions limits the number of concurrently executing operations.
+// Requests over the limit wait in a bounded priority queue and are rejected
+// with 503 and Retry-After when the queue is full or the wait times out.
+// GraphiQL and Playground page loads are not counted. Websocket operation
+// starts wait for admission the same way and are rejected with an error, the
+// slot is released once the operation has started so subscriptions do not
+// hold it for their lifetime
+type AdmissionOptions struct {
+	MaxConcurrent int           // maximum executing operations, required
+	MaxQueue      int           // maximum waiting requests, requests are rejected immediately when zero
+	QueueTimeout  time.Duration // maximum wait in the queue, defaults to 1s
+	RetryAfter    time.Duration // Retry-After sent with rejections, defaults to 1s
+	Classifier    ClassifyFunc  // request priority, all requests have priority 0 when nil
+	Adaptive      *AdaptiveLimitOptions
+}
+
+// AdaptiveLimitOptions adjusts the concurrency limit from observed latency.
+// The limit is decreased at most once per window when the average latency
+// exceeds the target and increased by one while the limit is saturated and
+// latency is below the target
+type AdaptiveLimitOptions struct {
+	TargetLatency time.Duration // required
+	MinConcurrent int           // lower bound of the limit, defaults to 1
+	Decrease      float64       // multiplier applied when over the target, defaults to 0.9
+	Window        time.Duration // minimum time between decreases, defaults to 1s
+}
+
+// AdmissionStats admission control counters
+type AdmissionStats struct {
+	Limit    int           `json:"limit"`
+	InFlight int           `json:"inFlight"`
+	Queued   int           `json:"queued"`
+	Admitted int64         `json:"admitted"`
+	Rejected int64         `json:"rejected"`
+	Latency  time.Duration `json:"latency"` // moving average of execution latency
+}
+
+// a request waiting for admission
+type admissionWaiter struct {
+	priority int
+	seq      uint64
+	index    int
+	ready    chan struct{}
+	admitted bool
+}
+
+// waiters ordered by priority then arrival
+type admissionQueue []*admissionWaiter
+
+func (q admissionQueue) Len() int { return len(q) }
+
+func (q admissionQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q admissionQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *admissionQueue) Push(x interface{}) {
+	w := x.(*admissionWaiter)
+	w.index = len(*q)
+	*q = append(*q, w)
+}
+
+func (q *admissionQueue) Pop() interface{} {
+	old := *q
+	w := old[len(old)-1]
+	old[len(old)-1] = nil
+	w.index = -1
+	*q = old[:len(old)-1]
+	return w
+}
+
+// admissionController admits requests up to the concurrency limit
+type admissionController struct {
+	options *AdmissionOptions
+
+	mx        sync.Mutex
+	limit     float64
+	inFlight  int
+	queue     admissionQueue
+	seq       uint64
+	admitted  int64
+	rejected  int64
+	latency   float64
+	decreased time.Time // the time of the last limit decrease
+}
+
+func newAdmissionController(options *AdmissionOptions) *admissionController {
+	limit := options.MaxConcurrent
+	if limit < 1 {
+		limit = 1
+	}
+	return &admissionController{
+		options: options,
+		limit:   float64(limit),
+	}
+}
+
+// acquire waits for admission, the release function must be called once the
+// operation has finished. False is returned when the request is rejected
+func (c *admissionController) acquire(ctx context.Context, priority int) (func(), bool) {
+	c.mx.Lock()
+
+	if c.inFlight < int(c.limit) && len(c.queue) == 0 {
+		c.inFlight++
+		c.admitted++
+		c.mx.Unlock()
+		return c.releaseFunc(), true
+	}
+
+	if len(c.queue) >= c.options.MaxQueue {
+		// displace the lowest priority waiter
+		lowest := c.lowest()
+		if lowest == nil || lowest.priority >= priority {
+			c.rejected++
+			c.mx.Unlock()
+			return nil, false
+		}
+		heap.Remove(&c.queue, lowest.index)
+		close(lowest.ready)
+	}
+
+	c.seq++
+	w := &admissionWaiter{
+		priority: priority,
+		seq:      c.seq,
+		ready:    make(chan struct{}),
+	}
+	heap.Push(&c.queue, w)
+	c.mx.Unlock()
+
+	timeout := c.options.QueueTimeout
+	if timeout <= 0 {
+		timeout = DefaultAdmissionQueueTimeout
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-w.ready:
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if w.admitted {
+		return c.releaseFunc(), true
+	}
+	if w.index >= 0 {
+		heap.Remove(&c.queue, w.index)
+	}
+	c.rejected++
+	return nil, false
+}
+
+// gets the lowest priority waiter, the most recent on ties
+func (c *admissionController) lowest() *admissionWaiter {
+	var lowest *admissionWaiter
+	for _, w := range c.queue {
+		if lowest == nil || w.priority < lowest.priority || (w.priority == lowest.priority && w.seq > lowest.seq) {
+			lowest = w
+		}
+	}
+	return lowest
+}
+
+// creates the release function of an admitted request
+func (c *admissionController) releaseFunc() func() {
+	start := time.Now()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.release(time.Since(start))
+		})
+	}
+}
+
+// releases a slot, adapts the limit and admits waiting requests
+func (c *admissionController) release(latency time.Duration) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	saturated := c.inFlight >= int(c.limit)
+	c.inFlight--
+	c.adapt(latency, saturated)
+	c.admitWaiting()
+}
+
+// admits an operation start, the slot is released without a latency sample
+// once the operation has started. False is returned when it is rejected
+func (c *admissionController) admitStart(ctx context.Context, priority int) bool {
+	if _, ok := c.acquire(ctx, priority); !ok {
+		return false
+	}
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	c.inFlight--
+	c.admitWaiting()
+	return true
+}
+
+// admits waiting requests up to the limit
+func (c *admissionController) admitWaiting() {
+	for c.inFlight < int(c.limit) && len(c.queue) > 0 {
+		w := heap.Pop(&c.queue).(*admissionWaiter)
+		w.admitted = true
+		c.inFlight++
+		c.admitted++
+		close(w.ready)
+	}
+}
+
+// adjusts the limit from the moving average latency
+func (c *admissionController) adapt(latency time.Duration, saturated bool) {
+	if c.latency == 0 {
+		c.latency = float64(latency)
+	} else {
+		c.latency = 0.9*c.latency + 0.1*float64(latency)
+	}
+
+	adaptive := c.options.Adaptive
+	if adaptive == nil || adaptive.TargetLatency <= 0 {
+		return
+	}
+
+	minLimit := float64(adaptive.MinConcurrent)
+	if minLimit < 1 {
+		minLimit = 1
+	}
+	maxLimit := float64(c.options.MaxConcurrent)
+	if maxLimit < minLimit {
+		maxLimit = minLimit
+	}
+
+	if c.latency > float64(adaptive.TargetLatency) {
+		window := adaptive.Window
+		if window <= 0 {
+			window = DefaultAdaptiveWindow
+		}
+
+		// the average lags behind, decrease once per window instead of per release
+		if now := time.Now(); now.Sub(c.decreased) >= window {
+			decrease := adaptive.Decrease
+			if decrease <= 0 || decrease >= 1 {
+				decrease = 0.9
+			}
+			c.limit *= decrease
+			c.decreased = now
+		}
+	} else if saturated || len(c.queue) > 0 {
+		c.limit++
+	}
+
+	if c.limit < minLimit {
+		c.limit = minLimit
+	} else if c.limit > maxLimit {
+		c.limit = maxLimit
+	}
+}
+
+// gets the admission counters
+func (c *admissionController) stats() AdmissionStats {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	return AdmissionStats{
+		Limit:    int(c.limit),
+		InFlight: c.inFlight,
+		Queued:   len(c.queue),
+		Admitted: c.admitted,
+		Rejected: c.rejected,
+		Latency:  time.Duration(c.latency),
+	}
+}
+
+// rejects a request with 503 and Retry-After
+func (s *Server) rejectOverloaded(w http.ResponseWriter) {
+	retryAfter := s.options.Admission.RetryAfter
+	if retryAfter <= 0 {
+		retryAfter = DefaultAdmissionRetryAfter
+	}
+	seconds := int((retryAfter + time.Second - 1) / time.Second)
+
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	w.Write([]byte(`{"errors":[{"message":"server is overloaded, retry later"}]}`))
+}
+
+// AdmissionStats gets the admission control counters, the zero value is
+// returned when admission control is not enabled
+func (s *Server) AdmissionStats() AdmissionStats {
+	if s.admission == nil {
+		return AdmissionStats{}
+	}
+	return s.admission.stats()
+}
@@ -0,0 +1,164 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/graphql-go/graphql"
+)
+
+func TestAdmissionPriority(t *testing.T) {
+	c := newAdmissionController(&AdmissionOptions{
+		MaxConcurrent: 1,
+		MaxQueue:      1,
+		QueueTimeout:  time.Second,
+	})
+
+	release, ok := c.acquire(context.Background(), 0)
+	if !ok {
+		t.Fatal("expected the first request to be admitted")
+	}
+
+	results := make(chan string, 2)
+	var wg sync.WaitGroup
+	wait := func(name string, priority int) {
+		defer wg.Done()
+		if release, ok := c.acquire(context.Background(), priority); ok {
+			results <- name
+			release()
+		} else {
+			results <- name + " rejected"
+		}
+	}
+
+	wg.Add(1)
+	go wait("low", 0)
+	for c.stats().Queued != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// the full queue displaces the lower priority request
+	wg.Add(1)
+	go wait("high", 1)
+	if got := <-results; got != "low rejected" {
+		t.Errorf("expected the low priority request to be rejected, got %s", got)
+	}
+
+	// an equal priority request is rejected immediately
+	if _, ok := c.acquire(context.Background(), 1); ok {
+		t.Error("expected a request to be rejected when the queue is full")
+	}
+
+	release()
+	if got := <-results; got != "high" {
+		t.Errorf("expected the high priority request to be admitted, got %s", got)
+	}
+	wg.Wait()
+
+	if stats := c.stats(); stats.InFlight != 0 || stats.Admitted != 2 || stats.Rejected != 2 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestAdmissionAdaptiveLimit(t *testing.T) {
+	c := newAdmissionController(&AdmissionOptions{
+		MaxConcurrent: 10,
+		Adaptive: &AdaptiveLimitOptions{
+			TargetLatency: time.Millisecond,
+			MinConcurrent: 2,
+		},
+	})
+
+	for i := 0; i < 50; i++ {
+		c.mx.Lock()
+		c.inFlight++
+		c.mx.Unlock()
+		c.release(10 * time.Millisecond)
+	}
+	if limit := c.stats().Limit; limit != 9 {
+		t.Errorf("expected the limit to decrease once per window to 9, got %d", limit)
+	}
+
+	for i := 0; i < 50; i++ {
+		c.mx.Lock()
+		c.inFlight++
+		c.decreased = time.Time{} // the window has passed
+		c.mx.Unlock()
+		c.release(10 * time.Millisecond)
+	}
+	if limit := c.stats().Limit; limit != 2 {
+		t.Errorf("expected the limit to decrease to 2, got %d", limit)
+	}
+}
+
+func TestAdmissionReject(t *testing.T) {
+	block := make(chan struct{})
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"slow": &graphql.Field{
+					Type: graphql.String,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						<-block
+						return "done", nil
+					},
+				},
+			},
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := New(schema, &Options{
+		Admission: &AdmissionOptions{
+			MaxConcurrent: 1,
+			RetryAfter:    2 * time.Second,
+		},
+		GraphiQL: &GraphiQLOptions{},
+		WS:       &WSOptions{},
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/graphql?query={slow}", nil))
+	}()
+	for s.AdmissionStats().InFlight != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/graphql?query={slow}", nil))
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "2" || !strings.Contains(w.Body.String(), "overloaded") {
+		t.Errorf("expected a 503 with Retry-After, got %d %v %s", w.Code, w.Header(), w.Body.String())
+	}
+
+	// GraphiQL page loads are not counted
+	w = httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/graphql", nil)
+	r.Header.Set("Accept", "text/html")
+	s.ServeHTTP(w, r)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "<html") {
+		t.Errorf("expected the GraphiQL page to be served, got %d %s", w.Code, w.Body.String())
+	}
+
+	// websocket operations wait for admission the same way
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	msg := startWSOperation(t, srv.URL, map[string]interface{}{"query": "{ slow }"})
+	data, _ := json.Marshal(msg.Payload)
+	if msg.Type != "error" || !strings.Contains(string(data), "overloaded") {
+		t.Errorf("expected the websocket operation to be rejected, got %s %s", msg.Type, data)
+	}
+
+	close(block)
+	<-done
+}
@@ -0,0 +1,449 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/graphql-go/graphql"
+	"github.com/rohit20001221/graphql-go-tools/server/logger"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultEnvPrefix the prefix of environment variables overriding the config,
+// e.g. GRAPHQL_SERVER_CORS_ALLOWED_ORIGINS or GRAPHQL_SERVER_LOG_LEVEL
+const DefaultEnvPrefix = "GRAPHQL_SERVER"
+
+// UI types
+const (
+	UINone       = "none"
+	UIGraphiQL   = "graphiql"
+	UIPlayground = "playground"
+)
+
+// Config a declarative server configuration loaded from a YAML or JSON file
+type Config struct {
+	Pretty           bool                   `json:"pretty" yaml:"pretty"`
+	OrderedResponses bool                   `json:"orderedResponses" yaml:"orderedResponses"`
+	Endpoints        EndpointsConfig        `json:"endpoints" yaml:"endpoints"`
+	UI               UIConfig               `json:"ui" yaml:"ui"`
+	CORS             CORSConfig             `json:"cors" yaml:"cors"`
+	Limits           LimitsConfig           `json:"limits" yaml:"limits"`
+	WebSocket        WebSocketConfig        `json:"websocket" yaml:"websocket"`
+	PersistedQueries PersistedQueriesConfig `json:"persistedQueries" yaml:"persistedQueries"`
+	Admission        AdmissionConfig        `json:"admission" yaml:"admission"`
+	Fragments        FragmentsConfig        `json:"fragments" yaml:"fragments"`
+	Log              LogConfig              `json:"log" yaml:"log"`
+}
+
+// EndpointsConfig paths served by Server.Handler
+type EndpointsConfig struct {
+	GraphQL string `json:"graphql" yaml:"graphql"`
+	Health  string `json:"health" yaml:"health"`
+}
+
+// UIConfig the graphql ui served to browsers
+type UIConfig struct {
+	Type                 string `json:"type" yaml:"type"` // none, graphiql or playground
+	Version              string `json:"version" yaml:"version"`
+	SSL                  bool   `json:"ssl" yaml:"ssl"`
+	SubscriptionEndpoint string `json:"subscriptionEndpoint" yaml:"subscriptionEndpoint"`
+}
+
+// CORSConfig cross origin resource sharing
+type CORSConfig struct {
+	Enabled          bool     `json:"enabled" yaml:"enabled"`
+	AllowedOrigins   []string `json:"allowedOrigins" yaml:"allowedOrigins"`
+	AllowedMethods   []string `json:"allowedMethods" yaml:"allowedMethods"`
+	AllowedHeaders   []string `json:"allowedHeaders" yaml:"allowedHeaders"`
+	AllowCredentials bool     `json:"allowCredentials" yaml:"allowCredentials"`
+	MaxAge           int      `json:"maxAge" yaml:"maxAge"`
+}
+
+// LimitsConfig request limits, zero is unlimited
+type LimitsConfig struct {
+	MaxBodyBytes   int64 `json:"maxBodyBytes" yaml:"maxBodyBytes"`
+	MaxQueryLength int   `json:"maxQueryLength" yaml:"maxQueryLength"`
+}
+
+// WebSocketConfig graphql-ws subscriptions
+type WebSocketConfig struct {
+	Enabled               bool     `json:"enabled" yaml:"enabled"`
+	ReadLimit             int64    `json:"readLimit" yaml:"readLimit"`
+	ConnectionInitTimeout Duration `json:"connectionInitTimeout" yaml:"connectionInitTimeout"`
+	KeepAliveInterval     Duration `json:"keepAliveInterval" yaml:"keepAliveInterval"`
+}
+
+// PersistedQueriesConfig persisted queries loaded from a JSON file of hash to query
+type PersistedQueriesConfig struct {
+	File string `json:"file" yaml:"file"`
+	Only bool   `json:"only" yaml:"only"`
+}
+
+// FragmentsConfig shared fragments loaded from a .graphql file or directory
+// and validated against the schema by NewFromConfig
+type FragmentsConfig struct {
+	Path string `json:"path" yaml:"path"`
+}
+
+// AdmissionConfig concurrency limits and load shedding, the Classifier is
+// supplied in code
+type AdmissionConfig struct {
+	Enabled       bool     `json:"enabled" yaml:"enabled"`
+	MaxConcurrent int      `json:"maxConcurrent" yaml:"maxConcurrent"`
+	MaxQueue      int      `json:"maxQueue" yaml:"maxQueue"`
+	QueueTimeout  Duration `json:"queueTimeout" yaml:"queueTimeout"`
+	RetryAfter    Duration `json:"retryAfter" yaml:"retryAfter"`
+	TargetLatency Duration `json:"targetLatency" yaml:"targetLatency"` // enables adaptive limits
+	MinConcurrent int      `json:"minConcurrent" yaml:"minConcurrent"`
+}
+
+// LogConfig logging used when no Logger is supplied in code
+type LogConfig struct {
+	Level string `json:"level" yaml:"level"` // debug, info, warn or error
+}
+
+// Duration a time.Duration written as a string such as "30s"
+type Duration time.Duration
+
+// UnmarshalText parses a duration
+func (d *Duration) UnmarshalText(text []byte) error {
+	duration, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(duration)
+	return nil
+}
+
+// MarshalText formats a duration
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// DefaultConfig returns the default configuration
+func DefaultConfig() *Config {
+	return &Config{
+		Endpoints: EndpointsConfig{
+			GraphQL: DefaultEndpoint,
+		},
+		UI: UIConfig{
+			Type: UINone,
+		},
+		Limits: LimitsConfig{
+			MaxBodyBytes: 1 << 20,
+		},
+		WebSocket: WebSocketConfig{
+			Enabled:               true,
+			ReadLimit:             4096,
+			ConnectionInitTimeout: Duration(10 * time.Second),
+		},
+		Log: LogConfig{
+			Level: "info",
+		},
+	}
+}
+
+// LoadConfig loads a YAML or JSON config file over the defaults, applies
+// environment variable overrides with DefaultEnvPrefix and validates it.
+// Files ending in .json are parsed as JSON, all others as YAML
+func LoadConfig(path string) (*Config, error) {
+	config := DefaultConfig()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, config)
+	} else {
+		err = yaml.Unmarshal(data, config)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %v", path, err)
+	}
+
+	if err := config.ApplyEnv(DefaultEnvPrefix); err != nil {
+		return nil, err
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// ApplyEnv overrides config values from environment variables named by the
+// prefix and the upper snake cased field path, lists are comma separated
+func (c *Config) ApplyEnv(prefix string) error {
+	return applyEnv(prefix, reflect.ValueOf(c).Elem())
+}
+
+func applyEnv(name string, v reflect.Value) error {
+	if v.Kind() == reflect.Struct && !v.Addr().Type().Implements(textUnmarshalerType) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			tag := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+			if err := applyEnv(name+"_"+upperSnakeCase(tag), v.Field(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return nil
+	}
+
+	if unmarshaler, ok := v.Addr().Interface().(interface{ UnmarshalText([]byte) error }); ok {
+		if err := unmarshaler.UnmarshalText([]byte(value)); err != nil {
+			return fmt.Errorf("invalid %s: %v", name, err)
+		}
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %v", name, err)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %v", name, err)
+		}
+		v.SetInt(i)
+	case reflect.Slice:
+		items := []string{}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported environment variable %s", name)
+	}
+	return nil
+}
+
+var textUnmarshalerType = reflect.TypeOf((*interface{ UnmarshalText([]byte) error })(nil)).Elem()
+
+// converts camelCase to UPPER_SNAKE_CASE
+func upperSnakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if i > 0 && unicode.IsUpper(r) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
+// Validate validates the config
+func (c *Config) Validate() error {
+	if !strings.HasPrefix(c.Endpoints.GraphQL, "/") {
+		return fmt.Errorf("endpoints.graphql must be a path starting with /, got %q", c.Endpoints.GraphQL)
+	}
+	if c.Endpoints.Health != "" && !strings.HasPrefix(c.Endpoints.Health, "/") {
+		return fmt.Errorf("endpoints.health must be a path starting with /, got %q", c.Endpoints.Health)
+	}
+	if c.Endpoints.Health == c.Endpoints.GraphQL {
+		return fmt.Errorf("endpoints.health cannot be the graphql endpoint")
+	}
+
+	switch c.UI.Type {
+	case "", UINone, UIGraphiQL, UIPlayground:
+	default:
+		return fmt.Errorf("ui.type must be one of none, graphiql or playground, got %q", c.UI.Type)
+	}
+
+	if c.CORS.Enabled {
+		if len(c.CORS.AllowedOrigins) == 0 {
+			return fmt.Errorf("cors.allowedOrigins is required when cors is enabled")
+		}
+		for _, origin := range c.CORS.AllowedOrigins {
+			if origin == "*" && c.CORS.AllowCredentials {
+				return fmt.Errorf("cors.allowCredentials cannot be used with the * origin")
+			}
+		}
+		if c.CORS.MaxAge < 0 {
+			return fmt.Errorf("cors.maxAge cannot be negative")
+		}
+	}
+
+	if c.Limits.MaxBodyBytes < 0 || c.Limits.MaxQueryLength < 0 {
+		return fmt.Errorf("limits cannot be negative")
+	}
+
+	if c.WebSocket.ReadLimit < 0 || c.WebSocket.ConnectionInitTimeout < 0 || c.WebSocket.KeepAliveInterval < 0 {
+		return fmt.Errorf("websocket limits and timeouts cannot be negative")
+	}
+
+	if c.PersistedQueries.Only && c.PersistedQueries.File == "" {
+		return fmt.Errorf("persistedQueries.file is required when persistedQueries.only is set")
+	}
+
+	if c.Admission.Enabled {
+		if c.Admission.MaxConcurrent < 1 {
+			return fmt.Errorf("admission.maxConcurrent must be at least 1 when admission is enabled")
+		}
+		if c.Admission.MaxQueue < 0 || c.Admission.QueueTimeout < 0 || c.Admission.RetryAfter < 0 || c.Admission.TargetLatency < 0 || c.Admission.MinConcurrent < 0 {
+			return fmt.Errorf("admission limits and timeouts cannot be negative")
+		}
+		if c.Admission.MinConcurrent > c.Admission.MaxConcurrent {
+			return fmt.Errorf("admission.minConcurrent cannot exceed admission.maxConcurrent")
+		}
+	}
+
+	if _, err := logger.ParseLevel(c.Log.Level); err != nil {
+		return fmt.Errorf("log.level: %v", err)
+	}
+
+	return nil
+}
+
+// Options merges the config with code supplied options. Functions such as
+// RootValueFunc, ContextFunc, WS.AuthenticateFunc, WS.StartOperationFunc and
+// Admission.Classifier, the Logger and the Container are kept from base, the
+// settings present in the config replace those of base
+func (c *Config) Options(base *Options) (*Options, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	options := &Options{}
+	if base != nil {
+		*options = *base
+	}
+
+	options.Pretty = c.Pretty
+	options.OrderedResponses = c.OrderedResponses
+	options.Endpoint = c.Endpoints.GraphQL
+	options.HealthEndpoint = c.Endpoints.Health
+	options.MaxBodyBytes = c.Limits.MaxBodyBytes
+	options.MaxQueryLength = c.Limits.MaxQueryLength
+
+	switch c.UI.Type {
+	case UIGraphiQL:
+		options.Playground = nil
+		options.GraphiQL = &GraphiQLOptions{
+			Version:              defaultString(c.UI.Version, GraphiqlVersion),
+			SSL:                  c.UI.SSL,
+			SubscriptionEndpoint: c.UI.SubscriptionEndpoint,
+		}
+	case UIPlayground:
+		options.GraphiQL = nil
+		options.Playground = &PlaygroundOptions{
+			Version:              defaultString(c.UI.Version, PlaygroundVersion),
+			SSL:                  c.UI.SSL,
+			SubscriptionEndpoint: c.UI.SubscriptionEndpoint,
+		}
+	default:
+		options.GraphiQL = nil
+		options.Playground = nil
+	}
+
+	options.CORS = nil
+	if c.CORS.Enabled {
+		options.CORS = &CORSOptions{
+			AllowedOrigins:   c.CORS.AllowedOrigins,
+			AllowedMethods:   c.CORS.AllowedMethods,
+			AllowedHeaders:   c.CORS.AllowedHeaders,
+			AllowCredentials: c.CORS.AllowCredentials,
+			MaxAge:           c.CORS.MaxAge,
+		}
+	}
+
+	if c.WebSocket.Enabled {
+		ws := &WSOptions{}
+		if base != nil && base.WS != nil {
+			*ws = *base.WS
+		}
+		ws.ReadLimit = c.WebSocket.ReadLimit
+		ws.ConnectionInitTimeout = time.Duration(c.WebSocket.ConnectionInitTimeout)
+		ws.KeepAliveInterval = time.Duration(c.WebSocket.KeepAliveInterval)
+		options.WS = ws
+	} else {
+		options.WS = nil
+	}
+
+	if c.PersistedQueries.File != "" {
+		queries, err := LoadPersistedQueries(c.PersistedQueries.File)
+		if err != nil {
+			return nil, err
+		}
+		options.PersistedQueries = &PersistedQueryOptions{
+			Store: queries,
+			Only:  c.PersistedQueries.Only,
+		}
+	}
+
+	if c.Admission.Enabled {
+		admission := &AdmissionOptions{}
+		if base != nil && base.Admission != nil {
+			*admission = *base.Admission
+		}
+		admission.MaxConcurrent = c.Admission.MaxConcurrent
+		admission.MaxQueue = c.Admission.MaxQueue
+		admission.QueueTimeout = time.Duration(c.Admission.QueueTimeout)
+		admission.RetryAfter = time.Duration(c.Admission.RetryAfter)
+		admission.Adaptive = nil
+		if c.Admission.TargetLatency > 0 {
+			adaptive := &AdaptiveLimitOptions{}
+			if base != nil && base.Admission != nil && base.Admission.Adaptive != nil {
+				*adaptive = *base.Admission.Adaptive
+			}
+			adaptive.TargetLatency = time.Duration(c.Admission.TargetLatency)
+			adaptive.MinConcurrent = c.Admission.MinConcurrent
+			admission.Adaptive = adaptive
+		}
+		options.Admission = admission
+	} else {
+		options.Admission = nil
+	}
+
+	if options.Logger == nil {
+		level, _ := logger.ParseLevel(c.Log.Level)
+		options.Logger = logger.NewStdLogger(level)
+	}
+
+	return options, nil
+}
+
+// NewFromConfig creates a server from a config merged with code supplied options
+func NewFromConfig(schema graphql.Schema, config *Config, base *Options) (*Server, error) {
+	options, err := config.Options(base)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Fragments.Path != "" {
+		fragments, err := LoadFragments(config.Fragments.Path, schema)
+		if err != nil {
+			return nil, err
+		}
+		options.Fragments = fragments
+	}
+	return New(schema, options), nil
+}
+
+func defaultString(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,292 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rohit20001221/graphql-go-tools/server/logger"
+)
+
+func writeConfig(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		content string
+	}{
+		{"server.yaml", `
+pretty: true
+endpoints:
+  health: /healthz
+ui:
+  type: playground
+cors:
+  enabled: true
+  allowedOrigins: [https://example.com]
+websocket:
+  keepAliveInterval: 30s
+admission:
+  enabled: true
+  maxConcurrent: 8
+  queueTimeout: 250ms
+`},
+		{"server.json", `{
+	"pretty": true,
+	"endpoints": {"health": "/healthz"},
+	"ui": {"type": "playground"},
+	"cors": {"enabled": true, "allowedOrigins": ["https://example.com"]},
+	"websocket": {"keepAliveInterval": "30s"},
+	"admission": {"enabled": true, "maxConcurrent": 8, "queueTimeout": "250ms"}
+}`},
+	} {
+		config, err := LoadConfig(writeConfig(t, tt.name, tt.content))
+		if err != nil {
+			t.Fatalf("%s: failed to load the config: %v", tt.name, err)
+		}
+
+		if !config.Pretty || config.Endpoints.Health != "/healthz" || config.UI.Type != UIPlayground {
+			t.Errorf("%s: unexpected config %+v", tt.name, config)
+		}
+		if !reflect.DeepEqual(config.CORS.AllowedOrigins, []string{"https://example.com"}) {
+			t.Errorf("%s: unexpected allowed origins %v", tt.name, config.CORS.AllowedOrigins)
+		}
+		if config.WebSocket.KeepAliveInterval != Duration(30*time.Second) {
+			t.Errorf("%s: unexpected keep alive interval %v", tt.name, time.Duration(config.WebSocket.KeepAliveInterval))
+		}
+		if config.Admission.MaxConcurrent != 8 || config.Admission.QueueTimeout != Duration(250*time.Millisecond) {
+			t.Errorf("%s: unexpected admission %+v", tt.name, config.Admission)
+		}
+
+		// values missing from the file keep their defaults
+		if config.Endpoints.GraphQL != DefaultEndpoint || config.Limits.MaxBodyBytes != 1<<20 || config.Log.Level != "info" {
+			t.Errorf("%s: expected the defaults to be kept %+v", tt.name, config)
+		}
+		if !config.WebSocket.Enabled || config.WebSocket.ReadLimit != 4096 || config.WebSocket.ConnectionInitTimeout != Duration(10*time.Second) {
+			t.Errorf("%s: expected the websocket defaults to be kept %+v", tt.name, config.WebSocket)
+		}
+	}
+
+	if _, err := LoadConfig(writeConfig(t, "invalid.yaml", "websocket:\n  keepAliveInterval: often\n")); err == nil {
+		t.Error("expected an invalid duration to fail")
+	}
+	if _, err := LoadConfig(writeConfig(t, "invalid.yaml", "ui:\n  type: voyager\n")); err == nil {
+		t.Error("expected an invalid config to fail validation")
+	}
+}
+
+func TestConfigApplyEnv(t *testing.T) {
+	t.Setenv("GRAPHQL_SERVER_PRETTY", "true")
+	t.Setenv("GRAPHQL_SERVER_CORS_ALLOWED_ORIGINS", "https://a.example.com, https://b.example.com,")
+	t.Setenv("GRAPHQL_SERVER_LIMITS_MAX_BODY_BYTES", "2048")
+	t.Setenv("GRAPHQL_SERVER_WEBSOCKET_KEEP_ALIVE_INTERVAL", "15s")
+	t.Setenv("GRAPHQL_SERVER_PERSISTED_QUERIES_FILE", "queries.json")
+	t.Setenv("GRAPHQL_SERVER_ADMISSION_TARGET_LATENCY", "100ms")
+
+	config := DefaultConfig()
+	if err := config.ApplyEnv(DefaultEnvPrefix); err != nil {
+		t.Fatal(err)
+	}
+
+	if !config.Pretty {
+		t.Error("expected pretty to be overridden")
+	}
+	if !reflect.DeepEqual(config.CORS.AllowedOrigins, []string{"https://a.example.com", "https://b.example.com"}) {
+		t.Errorf("unexpected allowed origins %v", config.CORS.AllowedOrigins)
+	}
+	if config.Limits.MaxBodyBytes != 2048 {
+		t.Errorf("unexpected max body bytes %d", config.Limits.MaxBodyBytes)
+	}
+	if config.WebSocket.KeepAliveInterval != Duration(15*time.Second) {
+		t.Errorf("unexpected keep alive interval %v", time.Duration(config.WebSocket.KeepAliveInterval))
+	}
+	if config.PersistedQueries.File != "queries.json" {
+		t.Errorf("unexpected persisted queries file %q", config.PersistedQueries.File)
+	}
+	if config.Admission.TargetLatency != Duration(100*time.Millisecond) {
+		t.Errorf("unexpected target latency %v", time.Duration(config.Admission.TargetLatency))
+	}
+
+	// variables that are not set keep the current values
+	if config.WebSocket.ReadLimit != 4096 || config.Endpoints.GraphQL != DefaultEndpoint {
+		t.Errorf("expected unset values to be kept %+v", config)
+	}
+
+	for name, value := range map[string]string{
+		"GRAPHQL_SERVER_PRETTY":                            "maybe",
+		"GRAPHQL_SERVER_LIMITS_MAX_QUERY_LENGTH":           "long",
+		"GRAPHQL_SERVER_WEBSOCKET_CONNECTION_INIT_TIMEOUT": "soon",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, value)
+			if err := DefaultConfig().ApplyEnv(DefaultEnvPrefix); err == nil || !strings.Contains(err.Error(), name) {
+				t.Errorf("expected an error naming %s, got %v", name, err)
+			}
+		})
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		modify func(c *Config)
+		err    string
+	}{
+		{"graphql endpoint", func(c *Config) { c.Endpoints.GraphQL = "graphql" }, "endpoints.graphql"},
+		{"health endpoint", func(c *Config) { c.Endpoints.Health = "health" }, "endpoints.health must be a path"},
+		{"same endpoints", func(c *Config) { c.Endpoints.Health = c.Endpoints.GraphQL }, "endpoints.health cannot be the graphql endpoint"},
+		{"ui type", func(c *Config) { c.UI.Type = "voyager" }, "ui.type"},
+		{"cors origins", func(c *Config) { c.CORS.Enabled = true }, "cors.allowedOrigins"},
+		{"cors credentials", func(c *Config) {
+			c.CORS = CORSConfig{Enabled: true, AllowedOrigins: []string{"*"}, AllowCredentials: true}
+		}, "cors.allowCredentials"},
+		{"cors max age", func(c *Config) {
+			c.CORS = CORSConfig{Enabled: true, AllowedOrigins: []string{"*"}, MaxAge: -1}
+		}, "cors.maxAge"},
+		{"limits", func(c *Config) { c.Limits.MaxQueryLength = -1 }, "limits cannot be negative"},
+		{"websocket", func(c *Config) { c.WebSocket.KeepAliveInterval = -1 }, "websocket limits"},
+		{"persisted queries", func(c *Config) { c.PersistedQueries.Only = true }, "persistedQueries.file"},
+		{"admission max concurrent", func(c *Config) { c.Admission.Enabled = true }, "admission.maxConcurrent"},
+		{"admission negative", func(c *Config) {
+			c.Admission = AdmissionConfig{Enabled: true, MaxConcurrent: 1, QueueTimeout: -1}
+		}, "admission limits"},
+		{"admission min concurrent", func(c *Config) {
+			c.Admission = AdmissionConfig{Enabled: true, MaxConcurrent: 1, MinConcurrent: 2}
+		}, "admission.minConcurrent"},
+		{"log level", func(c *Config) { c.Log.Level = "verbose" }, "log.level"},
+	} {
+		config := DefaultConfig()
+		tt.modify(config)
+		if err := config.Validate(); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: expected an error containing %q, got %v", tt.name, tt.err, err)
+		}
+	}
+
+	if err := DefaultConfig().Validate(); err != nil {
+		t.Errorf("expected the default config to be valid: %v", err)
+	}
+}
+
+func TestConfigOptions(t *testing.T) {
+	errStart := errors.New("rate limited")
+	log := logger.NewStdLogger(logger.LevelError)
+
+	base := &Options{
+		RootValueFunc: func(ctx context.Context, r *http.Request) map[string]interface{} {
+			return map[string]interface{}{}
+		},
+		Logger:         log,
+		MaxQueryLength: 10,
+		WS: &WSOptions{
+			ReadLimit: 1,
+			StartOperationFunc: func(ctx context.Context) error {
+				return errStart
+			},
+		},
+		Admission: &AdmissionOptions{
+			MaxConcurrent: 1,
+			Classifier: func(r *http.Request, opts *RequestOptions) int {
+				return 1
+			},
+			Adaptive: &AdaptiveLimitOptions{
+				Decrease: 0.5,
+			},
+		},
+	}
+
+	config := DefaultConfig()
+	config.Limits.MaxQueryLength = 100
+	config.WebSocket.KeepAliveInterval = Duration(time.Minute)
+	config.Admission = AdmissionConfig{
+		Enabled:       true,
+		MaxConcurrent: 4,
+		TargetLatency: Duration(time.Second),
+		MinConcurrent: 2,
+	}
+
+	options, err := config.Options(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// hooks are kept from base
+	if options.RootValueFunc == nil || options.Logger != log {
+		t.Error("expected the base functions and logger to be kept")
+	}
+	if options.WS == nil || options.WS.StartOperationFunc == nil || options.WS.StartOperationFunc(context.Background()) != errStart {
+		t.Error("expected the websocket StartOperationFunc to be kept")
+	}
+	if options.Admission == nil || options.Admission.Classifier == nil || options.Admission.Classifier(nil, nil) != 1 {
+		t.Error("expected the admission Classifier to be kept")
+	}
+
+	// settings come from the config
+	if options.MaxQueryLength != 100 || options.Endpoint != DefaultEndpoint {
+		t.Errorf("unexpected options %+v", options)
+	}
+	if options.WS.ReadLimit != 4096 || options.WS.KeepAliveInterval != time.Minute {
+		t.Errorf("unexpected websocket options %+v", options.WS)
+	}
+	if a := options.Admission; a.MaxConcurrent != 4 || a.Adaptive == nil || a.Adaptive.TargetLatency != time.Second || a.Adaptive.MinConcurrent != 2 || a.Adaptive.Decrease != 0.5 {
+		t.Errorf("unexpected admission options %+v", options.Admission)
+	}
+
+	// base is not modified
+	if base.WS.ReadLimit != 1 || base.Admission.MaxConcurrent != 1 || base.Admission.Adaptive.TargetLatency != 0 {
+		t.Error("expected base to be unchanged")
+	}
+
+	config.WebSocket.Enabled = false
+	config.Admission.Enabled = false
+	if options, err = config.Options(base); err != nil {
+		t.Fatal(err)
+	}
+	if options.WS != nil || options.Admission != nil {
+		t.Error("expected websockets and admission to be disabled by the config")
+	}
+
+	// a logger is created from the config without a base logger
+	if options, err = config.Options(nil); err != nil || options.Logger == nil {
+		t.Errorf("expected a logger to be created, got %v", err)
+	}
+}
+
+func TestNewFromConfig(t *testing.T) {
+	schema := makeFragmentSchema(t)
+
+	config := DefaultConfig()
+	config.Fragments.Path = writeFragments(t, map[string]string{
+		"user.graphql": "fragment UserName on User { name }",
+	})
+	s, err := NewFromConfig(schema, config, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.options.Fragments == nil || s.options.WS == nil {
+		t.Errorf("unexpected options %+v", s.options)
+	}
+
+	config.Fragments.Path = writeFragments(t, map[string]string{
+		"user.graphql": "fragment UserEmail on User { email }",
+	})
+	if _, err := NewFromConfig(schema, config, nil); err == nil {
+		t.Error("expected fragments invalid for the schema to fail")
+	}
+
+	config.UI.Type = "voyager"
+	if _, err := NewFromConfig(schema, config, nil); err == nil {
+		t.Error("expected an invalid config to fail")
+	}
+}
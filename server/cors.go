@@ -0,0 +1,75 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// CORSOptions cross origin resource sharing options
+type CORSOptions struct {
+	AllowedOrigins   []string // allowed origins, "*" allows any origin
+	AllowedMethods   []string // defaults to GET, POST and OPTIONS
+	AllowedHeaders   []string // defaults to Content-Type and Authorization
+	AllowCredentials bool
+	MaxAge           int // seconds preflight responses may be cached
+}
+
+// sets the CORS headers, returns true if the request was a preflight request
+func (s *Server) handleCORS(w http.ResponseWriter, r *http.Request) bool {
+	cors := s.options.CORS
+	origin := r.Header.Get("Origin")
+	if origin == "" || !cors.allowsOrigin(origin) {
+		return false
+	}
+
+	header := w.Header()
+	header.Add("Vary", "Origin")
+	if cors.allowsAnyOrigin() && !cors.AllowCredentials {
+		header.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		header.Set("Access-Control-Allow-Origin", origin)
+	}
+	if cors.AllowCredentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+
+	if r.Method != http.MethodOptions || r.Header.Get("Access-Control-Request-Method") == "" {
+		return false
+	}
+
+	methods := cors.AllowedMethods
+	if len(methods) == 0 {
+		methods = []string{http.MethodGet, http.MethodPost, http.MethodOptions}
+	}
+	headers := cors.AllowedHeaders
+	if len(headers) == 0 {
+		headers = []string{"Content-Type", "Authorization"}
+	}
+
+	header.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+	header.Set("Access-Control-Allow-Headers", strings.Join(headers, ", "))
+	if cors.MaxAge > 0 {
+		header.Set("Access-Control-Max-Age", strconv.Itoa(cors.MaxAge))
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return true
+}
+
+func (c *CORSOptions) allowsAnyOrigin() bool {
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *CORSOptions) allowsOrigin(origin string) bool {
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,284 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/printer"
+	"github.com/graphql-go/graphql/language/source"
+	tools "github.com/rohit20001221/graphql-go-tools"
+)
+
+// validation rules applied to shared fragments, rules for operations and
+// unused fragments do not apply to a document of fragments
+var fragmentRules = []graphql.ValidationRuleFn{
+	graphql.ArgumentsOfCorrectTypeRule,
+	graphql.FieldsOnCorrectTypeRule,
+	graphql.FragmentsOnCompositeTypesRule,
+	graphql.KnownArgumentNamesRule,
+	graphql.KnownDirectivesRule,
+	graphql.KnownFragmentNamesRule,
+	graphql.KnownTypeNamesRule,
+	graphql.NoFragmentCyclesRule,
+	graphql.OverlappingFieldsCanBeMergedRule,
+	graphql.PossibleFragmentSpreadsRule,
+	graphql.ProvidedNonNullArgumentsRule,
+	graphql.ScalarLeafsRule,
+	graphql.UniqueArgumentNamesRule,
+	graphql.UniqueFragmentNamesRule,
+	graphql.UniqueInputFieldNamesRule,
+}
+
+// a shared fragment and the fragments it spreads
+type sharedFragment struct {
+	definition *ast.FragmentDefinition
+	source     string
+	spreads    []string
+}
+
+// FragmentsVersionHeader the response header publishing the version of the
+// shared fragments
+const FragmentsVersionHeader = "X-Fragments-Version"
+
+// FragmentRegistry named fragments shared with clients. Fragments spread by
+// an incoming document but not defined in it are appended to the document.
+// Fragments are validated against the schema and can be added while the
+// server is running
+type FragmentRegistry struct {
+	mx        sync.RWMutex
+	schema    graphql.Schema
+	fragments map[string]*sharedFragment
+	version   string
+}
+
+// NewFragmentRegistry creates an empty fragment registry validating
+// fragments against the schema
+func NewFragmentRegistry(schema graphql.Schema) *FragmentRegistry {
+	r := &FragmentRegistry{
+		schema:    schema,
+		fragments: map[string]*sharedFragment{},
+	}
+	r.version = r.hash()
+	return r
+}
+
+// LoadFragments loads the fragments of a .graphql file or a directory of
+// .graphql files and validates them against the schema
+func LoadFragments(path string, schema graphql.Schema) (*FragmentRegistry, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var fragments string
+	if info.IsDir() {
+		fragments, err = tools.ReadSourceFiles(path, true)
+	} else {
+		var data []byte
+		data, err = os.ReadFile(path)
+		fragments = string(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	registry := NewFragmentRegistry(schema)
+	if err := registry.Add(fragments); err != nil {
+		return nil, fmt.Errorf("invalid fragments %s: %v", path, err)
+	}
+	return registry, nil
+}
+
+// Add parses and adds fragment definitions, the document may only contain
+// fragments. The fragments are validated against the schema together with
+// the fragments already added, no fragment is added when any of them is invalid
+func (r *FragmentRegistry) Add(fragments string) error {
+	document, err := parser.Parse(parser.ParseParams{
+		Source: &source.Source{
+			Body: []byte(fragments),
+			Name: "GraphQL fragments",
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	added := map[string]*sharedFragment{}
+	for _, def := range document.Definitions {
+		fragment, ok := def.(*ast.FragmentDefinition)
+		if !ok {
+			return fmt.Errorf("only fragments can be shared, found %s", def.GetKind())
+		}
+
+		name := fragment.Name.Value
+		if _, ok := r.fragments[name]; ok {
+			return fmt.Errorf("fragment %q is already defined", name)
+		}
+		if _, ok := added[name]; ok {
+			return fmt.Errorf("fragment %q is already defined", name)
+		}
+
+		added[name] = &sharedFragment{
+			definition: fragment,
+			source:     printer.Print(fragment).(string),
+			spreads:    fragmentSpreads(fragment.SelectionSet, nil),
+		}
+	}
+
+	// validate the added fragments with the fragments they may spread
+	validate := &ast.Document{Kind: "Document"}
+	for _, fragment := range r.fragments {
+		validate.Definitions = append(validate.Definitions, fragment.definition)
+	}
+	for _, fragment := range added {
+		validate.Definitions = append(validate.Definitions, fragment.definition)
+	}
+	if err := validateFragments(r.schema, validate); err != nil {
+		return err
+	}
+
+	for name, fragment := range added {
+		r.fragments[name] = fragment
+	}
+	r.version = r.hash()
+	return nil
+}
+
+// validates a document of fragments against the schema
+func validateFragments(schema graphql.Schema, document *ast.Document) error {
+	result := graphql.ValidateDocument(&schema, document, fragmentRules)
+	if result.IsValid {
+		return nil
+	}
+
+	messages := []string{}
+	for _, err := range result.Errors {
+		messages = append(messages, err.Message)
+	}
+	return errors.New(strings.Join(messages, "; "))
+}
+
+// Names gets the fragment names in alphabetical order
+func (r *FragmentRegistry) Names() []string {
+	r.mx.RLock()
+	defer r.mx.RUnlock()
+	return r.names()
+}
+
+func (r *FragmentRegistry) names() []string {
+	names := make([]string, 0, len(r.fragments))
+	for name := range r.fragments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Version a hash of the fragments that changes whenever a fragment changes.
+// The server publishes it in the X-Fragments-Version response header so
+// clients can detect updates
+func (r *FragmentRegistry) Version() string {
+	r.mx.RLock()
+	defer r.mx.RUnlock()
+	return r.version
+}
+
+// hashes the fragment sources
+func (r *FragmentRegistry) hash() string {
+	hash := sha256.New()
+	for _, name := range r.names() {
+		hash.Write([]byte(r.fragments[name].source))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:16]
+}
+
+// Expand appends the shared fragments spread by a query that it does not
+// define, including fragments spread by shared fragments. Queries that
+// cannot be parsed are returned unchanged so execution reports the error
+func (r *FragmentRegistry) Expand(query string) string {
+	if r == nil || !strings.Contains(query, "...") {
+		return query
+	}
+
+	r.mx.RLock()
+	defer r.mx.RUnlock()
+
+	if len(r.fragments) == 0 {
+		return query
+	}
+
+	document, err := parser.Parse(parser.ParseParams{
+		Source: &source.Source{
+			Body: []byte(query),
+			Name: "GraphQL request",
+		},
+	})
+	if err != nil {
+		return query
+	}
+
+	defined := map[string]bool{}
+	pending := []string{}
+	for _, def := range document.Definitions {
+		switch d := def.(type) {
+		case *ast.FragmentDefinition:
+			defined[d.Name.Value] = true
+			pending = fragmentSpreads(d.SelectionSet, pending)
+		case *ast.OperationDefinition:
+			pending = fragmentSpreads(d.SelectionSet, pending)
+		}
+	}
+
+	appended := []string{}
+	for len(pending) > 0 {
+		name := pending[0]
+		pending = pending[1:]
+		if defined[name] {
+			continue
+		}
+		fragment, ok := r.fragments[name]
+		if !ok {
+			continue
+		}
+		defined[name] = true
+		appended = append(appended, fragment.source)
+		pending = append(pending, fragment.spreads...)
+	}
+
+	if len(appended) == 0 {
+		return query
+	}
+	return query + "\n\n" + strings.Join(appended, "\n\n")
+}
+
+// collects the names of fragments spread in a selection set
+func fragmentSpreads(set *ast.SelectionSet, names []string) []string {
+	if set == nil {
+		return names
+	}
+
+	for _, selection := range set.Selections {
+		switch sel := selection.(type) {
+		case *ast.Field:
+			names = fragmentSpreads(sel.SelectionSet, names)
+		case *ast.InlineFragment:
+			names = fragmentSpreads(sel.SelectionSet, names)
+		case *ast.FragmentSpread:
+			names = append(names, sel.Name.Value)
+		}
+	}
+	return names
+}
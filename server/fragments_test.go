@@ -0,0 +1,200 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	tools "github.com/rohit20001221/graphql-go-tools"
+)
+
+func makeFragmentSchema(t *testing.T) graphql.Schema {
+	schema, err := tools.MakeExecutableSchema(tools.ExecutableSchema{
+		TypeDefs: `
+type Address {
+	city: String
+}
+
+type User {
+	id: ID!
+	name: String
+	address: Address
+}
+
+type Query {
+	user: User
+}`,
+		Resolvers: tools.ResolverMap{
+			"Query": &tools.ObjectResolver{
+				Fields: tools.FieldResolveMap{
+					"user": &tools.FieldResolve{
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							return map[string]interface{}{
+								"id":      "1",
+								"name":    "alice",
+								"address": map[string]interface{}{"city": "Paris"},
+							}, nil
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to make schema: %v", err)
+	}
+	return schema
+}
+
+func writeFragments(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestFragmentRegistry(t *testing.T) {
+	schema := makeFragmentSchema(t)
+	dir := writeFragments(t, map[string]string{
+		"user.graphql":           `fragment UserFields on User { id name address { ...AddressFields } }`,
+		"shared/address.graphql": `fragment AddressFields on Address { city }`,
+	})
+
+	fragments, err := LoadFragments(dir, schema)
+	if err != nil {
+		t.Fatalf("failed to load fragments: %v", err)
+	}
+	if names := strings.Join(fragments.Names(), ","); names != "AddressFields,UserFields" {
+		t.Errorf("unexpected fragments %s", names)
+	}
+	version := fragments.Version()
+
+	srv := httptest.NewServer(New(schema, &Options{Fragments: fragments}))
+	defer srv.Close()
+
+	query := func(q string) map[string]interface{} {
+		body, _ := json.Marshal(map[string]string{"query": q})
+		res, err := http.Post(srv.URL, "application/json", strings.NewReader(string(body)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+
+		var result map[string]interface{}
+		if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	result := query(`{ user { ...UserFields } }`)
+	data, _ := json.Marshal(result)
+	if string(data) != `{"data":{"user":{"address":{"city":"Paris"},"id":"1","name":"alice"}}}` {
+		t.Errorf("expected shared fragments to be appended, got %s", data)
+	}
+
+	// fragments defined by the document are not replaced
+	result = query(`{ user { ...UserFields } } fragment UserFields on User { name }`)
+	data, _ = json.Marshal(result)
+	if string(data) != `{"data":{"user":{"name":"alice"}}}` {
+		t.Errorf("expected the document fragment to be used, got %s", data)
+	}
+
+	// the version is published with each response
+	res, err := http.Get(srv.URL + "?query={user{id}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if got := res.Header.Get(FragmentsVersionHeader); got != version {
+		t.Errorf("expected the fragments version %s in the response, got %q", version, got)
+	}
+
+	// fragments can be added while documents are expanded
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			fragments.Expand(`{ user { ...UserFields } }`)
+		}
+	}()
+	if err := fragments.Add(`fragment UserName on User { name }`); err != nil {
+		t.Fatal(err)
+	}
+	<-done
+	if fragments.Version() == version {
+		t.Error("expected the version to change when a fragment is added")
+	}
+
+	if expanded := fragments.Expand(`{ user { id } }`); expanded != `{ user { id } }` {
+		t.Errorf("expected documents without spreads to be unchanged, got %s", expanded)
+	}
+
+	// the version changes with the fragments
+	dir = writeFragments(t, map[string]string{
+		"user.graphql": `fragment UserFields on User { id }`,
+	})
+	changed, err := LoadFragments(dir, schema)
+	if err != nil {
+		t.Fatalf("failed to load fragments: %v", err)
+	}
+	if changed.Version() == version {
+		t.Error("expected the version to change")
+	}
+}
+
+func TestFragmentRegistryValidation(t *testing.T) {
+	schema := makeFragmentSchema(t)
+
+	for name, content := range map[string]string{
+		"unknown field": `fragment UserFields on User { email }`,
+		"unknown type":  `fragment PostFields on Post { id }`,
+		"operation":     `query { user { id } }`,
+		"duplicate":     `fragment A on User { id } fragment A on User { name }`,
+	} {
+		dir := writeFragments(t, map[string]string{"fragments.graphql": content})
+		if _, err := LoadFragments(dir, schema); err == nil {
+			t.Errorf("%s: expected fragments to be rejected", name)
+		}
+	}
+}
+
+func TestFragmentRegistryAddValidation(t *testing.T) {
+	fragments := NewFragmentRegistry(makeFragmentSchema(t))
+	if err := fragments.Add(`fragment AddressFields on Address { city }`); err != nil {
+		t.Fatal(err)
+	}
+	version := fragments.Version()
+
+	for name, content := range map[string]string{
+		"unknown field":  `fragment UserFields on User { email }`,
+		"unknown type":   `fragment PostFields on Post { id }`,
+		"unknown spread": `fragment UserFields on User { ...ProfileFields }`,
+		"scalar type":    `fragment IDFields on ID { id }`,
+		"partly invalid": `fragment UserName on User { name } fragment UserEmail on User { email }`,
+	} {
+		if err := fragments.Add(content); err == nil {
+			t.Errorf("%s: expected the fragments to be rejected", name)
+		}
+	}
+	if names := strings.Join(fragments.Names(), ","); names != "AddressFields" || fragments.Version() != version {
+		t.Errorf("expected rejected fragments not to be added, got %s", names)
+	}
+
+	// added fragments can spread the fragments already in the registry
+	if err := fragments.Add(`fragment UserFields on User { id address { ...AddressFields } }`); err != nil {
+		t.Errorf("expected the fragment to be added: %v", err)
+	}
+}
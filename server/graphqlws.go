@@ -6,15 +6,26 @@ import (
 
 	"github.com/gorilla/websocket"
 	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	tools "github.com/rohit20001221/graphql-go-tools"
 	"github.com/rohit20001221/graphql-go-tools/server/graphqlws"
 )
 
 func (s *Server) newGraphQLWSConnection(ctx context.Context, r *http.Request, ws *websocket.Conn) {
 	// Establish a GraphQL WebSocket connection
 	graphqlws.NewConnection(ws, graphqlws.ConnectionConfig{
-		Authenticate: s.options.WS.AuthenticateFunc,
-		Logger:       s.log,
+		Authenticate:          s.options.WS.AuthenticateFunc,
+		Logger:                s.log,
+		ReadLimit:             s.options.WS.ReadLimit,
+		ConnectionInitTimeout: s.options.WS.ConnectionInitTimeout,
+		KeepAliveInterval:     s.options.WS.KeepAliveInterval,
 		EventHandlers: graphqlws.ConnectionEventHandlers{
+			Init: func(conn graphqlws.Connection) {
+				s.mgr.OpenConn(conn.ID())
+			},
 			Close: func(conn graphqlws.Connection) {
 				s.log.Debugf("closing websocket: %s", conn.ID())
 				s.mgr.DelConn(conn.ID())
@@ -26,33 +37,89 @@ func (s *Server) newGraphQLWSConnection(ctx context.Context, r *http.Request, ws
 			) []error {
 				s.log.Debugf("start operations %s on connection %s", opID, conn.ID())
 
+				// apply the same persisted query and length checks as http requests
+				opts := &RequestOptions{
+					Query:         data.Query,
+					Variables:     data.Variables,
+					OperationName: data.OperationName,
+					Extensions:    data.Extensions,
+				}
+				if err := s.checkRequest(connContext(conn), opts); err != nil {
+					return []error{gqlerrors.FormatError(err)}
+				}
+				if start := s.options.WS.StartOperationFunc; start != nil {
+					if err := start(connContext(conn)); err != nil {
+						return []error{gqlerrors.FormatError(err)}
+					}
+				}
+
+				// wait for admission when the server is busy
+				if s.admission != nil {
+					priority := 0
+					if s.options.Admission.Classifier != nil {
+						priority = s.options.Admission.Classifier(r, opts)
+					}
+					if !s.admission.admitStart(connContext(conn), priority) {
+						s.log.Debugf("rejected operation %s on connection %s, server is overloaded", opID, conn.ID())
+						return []error{gqlerrors.FormatError(errOverloaded)}
+					}
+				}
+				query := opts.Query
+
 				rootObject := map[string]interface{}{}
 				if s.options.RootValueFunc != nil {
 					rootObject = s.options.RootValueFunc(ctx, r)
 				}
-				ctx, cancelFunc := context.WithCancel(context.WithValue(context.Background(), ConnKey, conn))
+				// operations inherit the context of the connection set by AuthenticateFunc
+				ctx, cancelFunc := context.WithCancel(context.WithValue(connContext(conn), ConnKey, conn))
+
+				// create a service scope for the operation
+				var scope *tools.Scope
+				if s.options.Container != nil {
+					ctx, scope = s.options.Container.NewScope(ctx)
+				}
+
 				resultChannel := graphql.Subscribe(graphql.Params{
 					Schema:         s.schema,
-					RequestString:  data.Query,
+					RequestString:  query,
 					VariableValues: data.Variables,
 					OperationName:  data.OperationName,
 					Context:        ctx,
 					RootObject:     rootObject,
 				})
 
-				s.mgr.Add(&ResultChan{
+				rc := &ResultChan{
 					ch:         resultChannel,
 					cancelFunc: cancelFunc,
 					ctx:        ctx,
 					cid:        conn.ID(),
 					oid:        opID,
-				})
+				}
+				s.mgr.Add(rc)
+
+				// parse the query once to order each result
+				var document *ast.Document
+				operationName := data.OperationName
+				if s.options.OrderedResponses {
+					document, _ = parser.Parse(parser.ParseParams{
+						Source: &source.Source{
+							Body: []byte(query),
+							Name: "GraphQL request",
+						},
+					})
+				}
 
 				go func() {
+					if scope != nil {
+						defer s.closeScope(scope)
+					}
+
+					// remove the operation once it has finished unless it was replaced
+					defer s.mgr.Remove(rc)
+
 					for {
 						select {
 						case <-ctx.Done():
-							s.mgr.Del(conn.ID(), opID)
 							return
 						case res, more := <-resultChannel:
 							if !more {
@@ -68,8 +135,13 @@ func (s *Server) newGraphQLWSConnection(ctx context.Context, r *http.Request, ws
 								}
 							}
 
+							data := res.Data
+							if document != nil && data != nil {
+								data = tools.OrderResultData(document, operationName, data)
+							}
+
 							conn.SendData(opID, &graphqlws.DataMessagePayload{
-								Data:   res.Data,
+								Data:   data,
 								Errors: errs,
 							})
 						}
@@ -85,3 +157,11 @@ func (s *Server) newGraphQLWSConnection(ctx context.Context, r *http.Request, ws
 		},
 	})
 }
+
+// gets the context of a connection or the background context
+func connContext(conn graphqlws.Connection) context.Context {
+	if ctx := conn.Context(); ctx != nil {
+		return ctx
+	}
+	return context.Background()
+}
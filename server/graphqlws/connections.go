@@ -47,6 +47,7 @@ type StartMessagePayload struct {
 	Query         string                 `json:"query"`
 	Variables     map[string]interface{} `json:"variables"`
 	OperationName string                 `json:"operationName"`
+	Extensions    map[string]interface{} `json:"extensions"`
 }
 
 // DataMessagePayload defines the result data of an operation.
@@ -78,6 +79,10 @@ type AuthenticateFunc func(data map[string]interface{}, conn Connection) (contex
 // Event handlers allow other system components to react to events such
 // as the connection closing or an operation being started or stopped.
 type ConnectionEventHandlers struct {
+	// Init is called once when the connection is acknowledged after a
+	// successful connection_init.
+	Init func(Connection)
+
 	// Close is called whenever the connection is closed, regardless of
 	// whether this happens because of an error or a deliberate termination
 	// by the client.
@@ -102,6 +107,17 @@ type ConnectionConfig struct {
 	Logger        logger.Logger
 	Authenticate  AuthenticateFunc
 	EventHandlers ConnectionEventHandlers
+
+	// ReadLimit is the maximum size of incoming messages, defaults to 4096 bytes
+	ReadLimit int64
+
+	// ConnectionInitTimeout closes connections that are not initialized
+	// within the timeout, disabled when zero
+	ConnectionInitTimeout time.Duration
+
+	// KeepAliveInterval sends keep alive messages after the connection
+	// is acknowledged, disabled when zero
+	KeepAliveInterval time.Duration
 }
 
 // Connection is an interface to represent GraphQL WebSocket connections.
@@ -137,6 +153,8 @@ type connection struct {
 	closeMutex *sync.Mutex
 	closed     bool
 	context    context.Context
+	initOnce   sync.Once
+	initDone   chan struct{}
 }
 
 func operationMessageForType(messageType string) OperationMessage {
@@ -158,9 +176,14 @@ func NewConnection(ws *websocket.Conn, config ConnectionConfig) Connection {
 	conn.closed = false
 	conn.closeMutex = &sync.Mutex{}
 	conn.outgoing = make(chan OperationMessage)
+	conn.initDone = make(chan struct{})
 
 	go conn.writeLoop()
 	go conn.readLoop()
+
+	if config.ConnectionInitTimeout > 0 {
+		go conn.initTimeout(config.ConnectionInitTimeout)
+	}
 	conn.logger.Infof("Created connection")
 
 	return conn
@@ -215,6 +238,62 @@ func (conn *connection) sendOperationErrors(opID string, errs []error) {
 	conn.closeMutex.Unlock()
 }
 
+// sends a message unless the connection is closed
+func (conn *connection) send(msg OperationMessage) bool {
+	conn.closeMutex.Lock()
+	defer conn.closeMutex.Unlock()
+
+	if conn.closed {
+		return false
+	}
+	conn.outgoing <- msg
+	return true
+}
+
+// acknowledges the connection and starts sending keep alive messages
+func (conn *connection) acknowledge() {
+	conn.outgoing <- operationMessageForType(gqlConnectionAck)
+
+	conn.initOnce.Do(func() {
+		close(conn.initDone)
+		if conn.config.EventHandlers.Init != nil {
+			conn.config.EventHandlers.Init(conn)
+		}
+		if conn.config.KeepAliveInterval > 0 {
+			go conn.keepAlive(conn.config.KeepAliveInterval)
+		}
+	})
+}
+
+// closes the websocket if the connection is not initialized within the timeout
+func (conn *connection) initTimeout(timeout time.Duration) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-conn.initDone:
+	case <-timer.C:
+		conn.logger.Warnf("connection %s was not initialized within %s", conn.id, timeout)
+		conn.ws.WriteControl(
+			websocket.CloseMessage,
+			websocket.FormatCloseMessage(4408, "connection initialisation timeout"),
+			time.Now().Add(writeTimeout),
+		)
+		conn.ws.Close()
+	}
+}
+
+func (conn *connection) keepAlive(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if !conn.send(operationMessageForType(gqlConnectionKeepAlive)) {
+			return
+		}
+	}
+}
+
 func (conn *connection) close() {
 	// Close the write loop by closing the outgoing messages channels
 	conn.closeMutex.Lock()
@@ -260,7 +339,11 @@ func (conn *connection) writeLoop() {
 func (conn *connection) readLoop() {
 	// Close the WebSocket connection when leaving the read loop
 	defer conn.ws.Close()
-	conn.ws.SetReadLimit(readLimit)
+	if conn.config.ReadLimit > 0 {
+		conn.ws.SetReadLimit(conn.config.ReadLimit)
+	} else {
+		conn.ws.SetReadLimit(readLimit)
+	}
 
 	for {
 		// Read the next message received from the client
@@ -315,10 +398,10 @@ func (conn *connection) readLoop() {
 						conn.outgoing <- msg
 					} else {
 						conn.context = ctx
-						conn.outgoing <- operationMessageForType(gqlConnectionAck)
+						conn.acknowledge()
 					}
 				} else {
-					conn.outgoing <- operationMessageForType(gqlConnectionAck)
+					conn.acknowledge()
 				}
 			}
 
@@ -0,0 +1,143 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/graphql-go/graphql"
+	tools "github.com/rohit20001221/graphql-go-tools"
+	"github.com/rohit20001221/graphql-go-tools/server/graphqlws"
+)
+
+func makeSubscriptionSchema(t *testing.T) graphql.Schema {
+	schema, err := tools.MakeExecutableSchema(tools.ExecutableSchema{
+		TypeDefs: `
+type Query {
+	hello: String
+}
+
+type Subscription {
+	tick: String
+}`,
+		Resolvers: tools.ResolverMap{
+			"Subscription": &tools.ObjectResolver{
+				Fields: tools.FieldResolveMap{
+					"tick": &tools.FieldResolve{
+						Subscribe: func(p graphql.ResolveParams) (interface{}, error) {
+							ch := make(chan interface{}, 1)
+							ch <- "tock"
+							close(ch)
+							return ch, nil
+						},
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							return p.Source, nil
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to make schema: %v", err)
+	}
+	return schema
+}
+
+// starts an operation and returns the first data or error message
+func startWSOperation(t *testing.T, url string, payload map[string]interface{}) graphqlws.OperationMessage {
+	var msg graphqlws.OperationMessage
+	if err := json.Unmarshal(startWSOperationRaw(t, url, payload), &msg); err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+// starts an operation and returns the encoded first data or error message
+func startWSOperationRaw(t *testing.T, url string, payload map[string]interface{}) []byte {
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(url, "http"), http.Header{
+		"Sec-WebSocket-Protocol": []string{"graphql-ws"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+	ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	ws.WriteJSON(graphqlws.OperationMessage{Type: "connection_init", Payload: map[string]interface{}{}})
+	ws.WriteJSON(graphqlws.OperationMessage{ID: "1", Type: "start", Payload: payload})
+
+	for {
+		_, data, err := ws.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var msg graphqlws.OperationMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			t.Fatal(err)
+		}
+		if msg.Type == "data" || msg.Type == "error" {
+			return data
+		}
+	}
+}
+
+func TestGraphQLWSCheckRequest(t *testing.T) {
+	query := "subscription { tick }"
+	hash := HashQuery(query)
+
+	s := New(makeSubscriptionSchema(t), &Options{
+		WS:             &WSOptions{},
+		MaxQueryLength: 64,
+		PersistedQueries: &PersistedQueryOptions{
+			Store: PersistedQueryMap{hash: query},
+			Only:  true,
+		},
+	})
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	msg := startWSOperation(t, srv.URL, map[string]interface{}{
+		"extensions": map[string]interface{}{
+			"persistedQuery": map[string]interface{}{"version": 1, "sha256Hash": hash},
+		},
+	})
+	data, _ := json.Marshal(msg.Payload)
+	if msg.Type != "data" || !strings.Contains(string(data), `"tick":"tock"`) {
+		t.Errorf("expected the persisted query to run, got %s %s", msg.Type, data)
+	}
+	if opened := s.ConnectionStats().ConnectionsOpened; opened != 1 {
+		t.Errorf("expected the acknowledged connection to be counted, got %d", opened)
+	}
+
+	msg = startWSOperation(t, srv.URL, map[string]interface{}{"query": query})
+	data, _ = json.Marshal(msg.Payload)
+	if msg.Type != "error" || !strings.Contains(string(data), "only persisted queries are allowed") {
+		t.Errorf("expected queries that are not persisted to be rejected, got %s %s", msg.Type, data)
+	}
+
+	srv = httptest.NewServer(New(makeSubscriptionSchema(t), &Options{WS: &WSOptions{}, MaxQueryLength: 16}))
+	defer srv.Close()
+
+	msg = startWSOperation(t, srv.URL, map[string]interface{}{"query": query})
+	data, _ = json.Marshal(msg.Payload)
+	if msg.Type != "error" || !strings.Contains(string(data), "maximum length") {
+		t.Errorf("expected long queries to be rejected, got %s %s", msg.Type, data)
+	}
+}
+
+func TestGraphQLWSOrderedResponses(t *testing.T) {
+	srv := httptest.NewServer(New(makeOrderedSchema(t), &Options{WS: &WSOptions{}, OrderedResponses: true}))
+	defer srv.Close()
+
+	data := startWSOperationRaw(t, srv.URL, map[string]interface{}{
+		"query": "subscription { update { zeta alpha } }",
+	})
+	if !strings.Contains(string(data), `"payload":{"data":{"update":{"zeta":"z","alpha":"a"}}`) {
+		t.Errorf("expected the payload keys in selection set order, got %s", data)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
@@ -11,6 +13,7 @@ import (
 
 	"github.com/graphql-go/graphql"
 	"github.com/graphql-go/graphql/gqlerrors"
+	tools "github.com/rohit20001221/graphql-go-tools"
 )
 
 // RequestOptions options
@@ -18,6 +21,7 @@ type RequestOptions struct {
 	Query         string                 `json:"query" url:"query" schema:"query"`
 	Variables     map[string]interface{} `json:"variables" url:"variables" schema:"variables"`
 	OperationName string                 `json:"operationName" url:"operationName" schema:"operationName"`
+	Extensions    map[string]interface{} `json:"extensions" url:"extensions" schema:"extensions"`
 }
 
 // a workaround for getting`variables` as a JSON string
@@ -29,16 +33,21 @@ type requestOptionsCompatibility struct {
 
 func getFromForm(values url.Values) *RequestOptions {
 	query := values.Get("query")
-	if query != "" {
+	extensionsStr := values.Get("extensions")
+	if query != "" || extensionsStr != "" {
 		// get variables map
 		variables := make(map[string]interface{}, len(values))
 		variablesStr := values.Get("variables")
 		json.Unmarshal([]byte(variablesStr), &variables)
 
+		extensions := map[string]interface{}{}
+		json.Unmarshal([]byte(extensionsStr), &extensions)
+
 		return &RequestOptions{
 			Query:         query,
 			Variables:     variables,
 			OperationName: values.Get("operationName"),
+			Extensions:    extensions,
 		}
 	}
 
@@ -168,9 +177,53 @@ func GetRequestOptions(r *http.Request) *RequestOptions {
 // ContextHandler provides an entrypoint into executing graphQL queries with a
 // user-provided context.
 func (s *Server) ContextHandler(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	// limit the request body, the body is read before parsing since the
+	// request options cannot report a body without a Content-Length as too large
+	if s.options.MaxBodyBytes > 0 && r.Body != nil {
+		if r.ContentLength > s.options.MaxBodyBytes {
+			http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, s.options.MaxBodyBytes))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+			} else {
+				http.Error(w, "failed to read request body", http.StatusBadRequest)
+			}
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
 	// get query
 	opts := NewRequestOptions(r)
 
+	// wait for admission when the server is busy, page loads are not counted
+	if s.admission != nil && !s.rendersPage(r) {
+		priority := 0
+		if s.options.Admission.Classifier != nil {
+			priority = s.options.Admission.Classifier(r, opts)
+		}
+
+		release, ok := s.admission.acquire(ctx, priority)
+		if !ok {
+			s.log.Debugf("rejected request, server is overloaded")
+			s.rejectOverloaded(w)
+			return
+		}
+		defer release()
+	}
+
+	// create a service scope for the request
+	if s.options.Container != nil {
+		var scope *tools.Scope
+		ctx, scope = s.options.Container.NewScope(ctx)
+		defer s.closeScope(scope)
+	}
+
 	// execute graphql query
 	params := graphql.Params{
 		Schema:         s.schema,
@@ -182,7 +235,14 @@ func (s *Server) ContextHandler(ctx context.Context, w http.ResponseWriter, r *h
 	if s.options.RootValueFunc != nil {
 		params.RootObject = s.options.RootValueFunc(ctx, r)
 	}
-	result := graphql.Do(params)
+
+	var result *graphql.Result
+	if err := s.checkRequest(ctx, opts); err != nil {
+		result = &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	} else {
+		params.RequestString = opts.Query
+		result = graphql.Do(params)
+	}
 
 	if formatErrorFunc := s.options.FormatErrorFunc; formatErrorFunc != nil && len(result.Errors) > 0 {
 		formatted := make([]gqlerrors.FormattedError, len(result.Errors))
@@ -192,34 +252,38 @@ func (s *Server) ContextHandler(ctx context.Context, w http.ResponseWriter, r *h
 		result.Errors = formatted
 	}
 
-	if s.options.GraphiQL != nil {
-		acceptHeader := r.Header.Get("Accept")
-		_, raw := r.URL.Query()["raw"]
-		if !raw && !strings.Contains(acceptHeader, "application/json") && strings.Contains(acceptHeader, "text/html") {
+	if s.rendersPage(r) {
+		if s.options.GraphiQL != nil {
 			renderGraphiQL(s.options.GraphiQL, w, r, params)
-			return
-		}
-	} else if s.options.Playground != nil {
-		acceptHeader := r.Header.Get("Accept")
-		_, raw := r.URL.Query()["raw"]
-		if !raw && !strings.Contains(acceptHeader, "application/json") && strings.Contains(acceptHeader, "text/html") {
+		} else {
 			renderPlayground(s.options.Playground, w, r)
-			return
 		}
+		return
 	}
 
 	// use proper JSON Header
 	w.Header().Add("Content-Type", "application/json; charset=utf-8")
+	if s.options.Fragments != nil {
+		w.Header().Set(FragmentsVersionHeader, s.options.Fragments.Version())
+	}
+
+	// encode a copy with ordered data so callbacks receive the original result
+	encoded := result
+	if s.options.OrderedResponses && result.Data != nil {
+		ordered := *result
+		ordered.Data = tools.OrderQueryResultData(opts.Query, opts.OperationName, result.Data)
+		encoded = &ordered
+	}
 
 	var buff []byte
 	if s.options.Pretty {
 		w.WriteHeader(http.StatusOK)
-		buff, _ = json.MarshalIndent(result, "", "\t")
+		buff, _ = json.MarshalIndent(encoded, "", "\t")
 
 		w.Write(buff)
 	} else {
 		w.WriteHeader(http.StatusOK)
-		buff, _ = json.Marshal(result)
+		buff, _ = json.Marshal(encoded)
 
 		w.Write(buff)
 	}
@@ -229,6 +293,37 @@ func (s *Server) ContextHandler(ctx context.Context, w http.ResponseWriter, r *h
 	}
 }
 
+// determines if the request loads the GraphiQL or Playground page
+func (s *Server) rendersPage(r *http.Request) bool {
+	if s.options.GraphiQL == nil && s.options.Playground == nil {
+		return false
+	}
+	acceptHeader := r.Header.Get("Accept")
+	_, raw := r.URL.Query()["raw"]
+	return !raw && !strings.Contains(acceptHeader, "application/json") && strings.Contains(acceptHeader, "text/html")
+}
+
+// resolves persisted queries, checks the query limits and appends shared fragments
+func (s *Server) checkRequest(ctx context.Context, opts *RequestOptions) error {
+	if err := s.resolvePersistedQuery(ctx, opts); err != nil {
+		return err
+	}
+
+	if s.options.MaxQueryLength > 0 && len(opts.Query) > s.options.MaxQueryLength {
+		return fmt.Errorf("query exceeds the maximum length of %d", s.options.MaxQueryLength)
+	}
+
+	opts.Query = s.options.Fragments.Expand(opts.Query)
+	return nil
+}
+
+// closes a service scope logging any cleanup errors
+func (s *Server) closeScope(scope *tools.Scope) {
+	if err := scope.Close(); err != nil {
+		s.log.Errorf("failed to clean up services: %v", err)
+	}
+}
+
 func (s *Server) WSHandler(ctx context.Context, w http.ResponseWriter, r *http.Request) {
 	// Establish a WebSocket connection
 	var ws, err = s.upgrader.Upgrade(w, r, nil)
@@ -0,0 +1,130 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	tools "github.com/rohit20001221/graphql-go-tools"
+)
+
+func TestMaxBodyBytes(t *testing.T) {
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"hello": &graphql.Field{
+					Type: graphql.String,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return "world", nil
+					},
+				},
+			},
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := New(schema, &Options{MaxBodyBytes: 32})
+	large := `{"query":"{ hello }","variables":{"padding":"` + strings.Repeat("x", 64) + `"}}`
+
+	for _, tt := range []struct {
+		name          string
+		body          string
+		contentLength int64
+		code          int
+	}{
+		{"small", `{"query":"{ hello }"}`, -1, http.StatusOK},
+		{"content length", large, int64(len(large)), http.StatusRequestEntityTooLarge},
+		{"no content length", large, -1, http.StatusRequestEntityTooLarge},
+	} {
+		// readers hide the body size, the content length is unknown unless set
+		r := httptest.NewRequest(http.MethodPost, "/graphql", io.MultiReader(strings.NewReader(tt.body)))
+		r.Header.Set("Content-Type", ContentTypeJSON)
+		r.ContentLength = tt.contentLength
+
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, r)
+		if w.Code != tt.code {
+			t.Errorf("%s: expected status %d, got %d %s", tt.name, tt.code, w.Code, w.Body.String())
+		}
+		if tt.code == http.StatusOK && !strings.Contains(w.Body.String(), `"hello":"world"`) {
+			t.Errorf("%s: unexpected response %s", tt.name, w.Body.String())
+		}
+	}
+}
+
+func makeOrderedSchema(t *testing.T) graphql.Schema {
+	update := map[string]interface{}{"zeta": "z", "alpha": "a"}
+	schema, err := tools.MakeExecutableSchema(tools.ExecutableSchema{
+		TypeDefs: `
+type Update {
+	zeta: String
+	alpha: String
+}
+
+type Query {
+	zeta: String
+	alpha: String
+	update: Update
+}
+
+type Subscription {
+	update: Update
+}`,
+		Resolvers: tools.ResolverMap{
+			"Query": &tools.ObjectResolver{
+				Fields: tools.FieldResolveMap{
+					"zeta":   &tools.FieldResolve{Resolve: func(p graphql.ResolveParams) (interface{}, error) { return "z", nil }},
+					"alpha":  &tools.FieldResolve{Resolve: func(p graphql.ResolveParams) (interface{}, error) { return "a", nil }},
+					"update": &tools.FieldResolve{Resolve: func(p graphql.ResolveParams) (interface{}, error) { return update, nil }},
+				},
+			},
+			"Subscription": &tools.ObjectResolver{
+				Fields: tools.FieldResolveMap{
+					"update": &tools.FieldResolve{
+						Subscribe: func(p graphql.ResolveParams) (interface{}, error) {
+							ch := make(chan interface{}, 1)
+							ch <- update
+							close(ch)
+							return ch, nil
+						},
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							return p.Source, nil
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to make schema: %v", err)
+	}
+	return schema
+}
+
+func TestOrderedResponses(t *testing.T) {
+	schema := makeOrderedSchema(t)
+	query := `{"query":"{ zeta update { zeta alpha } first: alpha }"}`
+
+	for _, tt := range []struct {
+		ordered  bool
+		expected string
+	}{
+		{true, `{"data":{"zeta":"z","update":{"zeta":"z","alpha":"a"},"first":"a"}}`},
+		{false, `{"data":{"first":"a","update":{"alpha":"a","zeta":"z"},"zeta":"z"}}`},
+	} {
+		r := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(query))
+		r.Header.Set("Content-Type", ContentTypeJSON)
+
+		w := httptest.NewRecorder()
+		New(schema, &Options{OrderedResponses: tt.ordered}).ServeHTTP(w, r)
+		if body := strings.TrimSpace(w.Body.String()); body != tt.expected {
+			t.Errorf("ordered %t: expected %s, got %s", tt.ordered, tt.expected, body)
+		}
+	}
+}
@@ -1,5 +1,11 @@
 package logger
 
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
 type Logger interface {
 	Infof(format string, data ...interface{})
 	Debugf(format string, data ...interface{})
@@ -13,3 +19,61 @@ func (n *NoopLogger) Infof(format string, data ...interface{})  {}
 func (n *NoopLogger) Debugf(format string, data ...interface{}) {}
 func (n *NoopLogger) Errorf(format string, data ...interface{}) {}
 func (n *NoopLogger) Warnf(format string, data ...interface{})  {}
+
+// Level a log level
+type Level int
+
+// log levels
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// ParseLevel parses a log level name
+func ParseLevel(level string) (Level, error) {
+	switch strings.ToLower(level) {
+	case "debug":
+		return LevelDebug, nil
+	case "info", "":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	}
+	return LevelInfo, fmt.Errorf("invalid log level %q", level)
+}
+
+// StdLogger logs messages at or above a level with the standard log package
+type StdLogger struct {
+	Level Level
+}
+
+// NewStdLogger creates a new standard logger
+func NewStdLogger(level Level) *StdLogger {
+	return &StdLogger{Level: level}
+}
+
+func (l *StdLogger) logf(level Level, prefix, format string, data ...interface{}) {
+	if level >= l.Level {
+		log.Printf(prefix+format, data...)
+	}
+}
+
+func (l *StdLogger) Infof(format string, data ...interface{}) {
+	l.logf(LevelInfo, "INFO ", format, data...)
+}
+
+func (l *StdLogger) Debugf(format string, data ...interface{}) {
+	l.logf(LevelDebug, "DEBUG ", format, data...)
+}
+
+func (l *StdLogger) Errorf(format string, data ...interface{}) {
+	l.logf(LevelError, "ERROR ", format, data...)
+}
+
+func (l *StdLogger) Warnf(format string, data ...interface{}) {
+	l.logf(LevelWarn, "WARN ", format, data...)
+}
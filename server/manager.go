@@ -3,13 +3,45 @@ package server
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 
 	"github.com/graphql-go/graphql"
 )
 
+// number of shards, a power of 2
+const chanMgrShards = 64
+
+// ChanMgr tracks the open websocket connections and their subscription
+// operations.
+// Connections are spread over shards by id so operations on different
+// connections rarely contend for the same lock. The zero value is ready to use
 type ChanMgr struct {
-	mx    sync.Mutex
+	shards [chanMgrShards]chanMgrShard
+
+	connections       atomic.Int64
+	operations        atomic.Int64
+	connectionsOpened atomic.Int64
+	connectionsClosed atomic.Int64
+	operationsStarted atomic.Int64
+	operationsStopped atomic.Int64
+}
+
+type chanMgrShard struct {
+	mx    sync.RWMutex
 	conns map[string]map[string]*ResultChan
+	open  map[string]struct{}
+}
+
+// ChanMgrStats connection and operation counters. Connections are counted
+// from the connection acknowledgement until the websocket closes, operations
+// from start until stop
+type ChanMgrStats struct {
+	Connections       int64 `json:"connections"`
+	Operations        int64 `json:"operations"`
+	ConnectionsOpened int64 `json:"connectionsOpened"`
+	ConnectionsClosed int64 `json:"connectionsClosed"`
+	OperationsStarted int64 `json:"operationsStarted"`
+	OperationsStopped int64 `json:"operationsStopped"`
 }
 
 type ResultChan struct {
@@ -20,61 +52,201 @@ type ResultChan struct {
 	oid        string
 }
 
-func (c *ChanMgr) Add(rc *ResultChan) { // Add(cid, oid string, ch chan *graphql.Result) {
-	c.mx.Lock()
-	defer c.mx.Unlock()
+// NewChanMgr creates a new connection manager
+func NewChanMgr() *ChanMgr {
+	return &ChanMgr{}
+}
 
-	conn, ok := c.conns[rc.cid]
+// gets the shard of a connection using fnv-1a
+func (c *ChanMgr) shard(cid string) *chanMgrShard {
+	hash := uint32(2166136261)
+	for i := 0; i < len(cid); i++ {
+		hash ^= uint32(cid[i])
+		hash *= 16777619
+	}
+	return &c.shards[hash&(chanMgrShards-1)]
+}
+
+// Add adds an operation, an existing operation with the same id on the
+// connection is cancelled and replaced
+func (c *ChanMgr) Add(rc *ResultChan) {
+	s := c.shard(rc.cid)
+	s.mx.Lock()
+
+	if s.conns == nil {
+		s.conns = make(map[string]map[string]*ResultChan)
+	}
+
+	conn, ok := s.conns[rc.cid]
 	if !ok {
 		conn = make(map[string]*ResultChan)
-		c.conns[rc.cid] = conn
+		s.conns[rc.cid] = conn
 	}
 
+	existing, replaced := conn[rc.oid]
 	conn[rc.oid] = rc
+	s.mx.Unlock()
+
+	c.operationsStarted.Add(1)
+	if replaced {
+		c.operationsStopped.Add(1)
+		existing.cancel()
+	} else {
+		c.operations.Add(1)
+	}
 }
 
-func (c *ChanMgr) DelConn(cid string) bool {
-	c.mx.Lock()
-	defer c.mx.Unlock()
+// OpenConn counts a connection as open, connections are closed by DelConn
+func (c *ChanMgr) OpenConn(cid string) {
+	s := c.shard(cid)
+	s.mx.Lock()
+
+	if s.open == nil {
+		s.open = make(map[string]struct{})
+	}
+	_, ok := s.open[cid]
+	s.open[cid] = struct{}{}
+	s.mx.Unlock()
 
-	conn, ok := c.conns[cid]
 	if !ok {
-		return false
+		c.connections.Add(1)
+		c.connectionsOpened.Add(1)
 	}
+}
 
-	for oid, rc := range conn {
-		if rc.cancelFunc != nil {
-			rc.cancelFunc()
-		}
-		delete(conn, oid)
+// DelConn closes a connection and cancels and removes all of its operations
+func (c *ChanMgr) DelConn(cid string) bool {
+	s := c.shard(cid)
+	s.mx.Lock()
+
+	_, open := s.open[cid]
+	delete(s.open, cid)
+	conn, ok := s.conns[cid]
+	delete(s.conns, cid)
+	s.mx.Unlock()
+
+	if open {
+		c.connections.Add(-1)
+		c.connectionsClosed.Add(1)
+	}
+	if !ok {
+		return open
+	}
+
+	c.operations.Add(-int64(len(conn)))
+	c.operationsStopped.Add(int64(len(conn)))
+
+	for _, rc := range conn {
+		rc.cancel()
 	}
 
-	delete(conn, cid)
 	return true
 }
 
+// Del cancels and removes an operation
 func (c *ChanMgr) Del(cid, oid string) bool {
-	c.mx.Lock()
-	defer c.mx.Unlock()
+	return c.remove(cid, oid, nil)
+}
+
+// Remove cancels and removes an operation if it has not been replaced
+func (c *ChanMgr) Remove(rc *ResultChan) bool {
+	return c.remove(rc.cid, rc.oid, rc)
+}
+
+// removes an operation, when match is set the operation is only removed if
+// it is the stored operation
+func (c *ChanMgr) remove(cid, oid string, match *ResultChan) bool {
+	s := c.shard(cid)
+	s.mx.Lock()
 
-	conn, ok := c.conns[cid]
+	conn, ok := s.conns[cid]
 	if !ok {
+		s.mx.Unlock()
 		return false
 	}
 
 	rc, ok := conn[oid]
-	if !ok {
+	if !ok || (match != nil && rc != match) {
+		s.mx.Unlock()
 		return false
 	}
 
-	if rc.cancelFunc != nil {
-		rc.cancelFunc()
-	}
 	delete(conn, oid)
-
-	if len(c.conns[cid]) == 0 {
-		delete(c.conns, cid)
+	if len(conn) == 0 {
+		delete(s.conns, cid)
 	}
+	s.mx.Unlock()
+
+	c.operations.Add(-1)
+	c.operationsStopped.Add(1)
 
+	rc.cancel()
 	return true
 }
+
+// Get gets an operation
+func (c *ChanMgr) Get(cid, oid string) (*ResultChan, bool) {
+	s := c.shard(cid)
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+
+	rc, ok := s.conns[cid][oid]
+	return rc, ok
+}
+
+// Range calls fn for each operation until fn returns false. Each shard is
+// copied before fn is called so fn may add or remove operations
+func (c *ChanMgr) Range(fn func(rc *ResultChan) bool) {
+	var ops []*ResultChan
+	for i := range c.shards {
+		s := &c.shards[i]
+
+		ops = ops[:0]
+		s.mx.RLock()
+		for _, conn := range s.conns {
+			for _, rc := range conn {
+				ops = append(ops, rc)
+			}
+		}
+		s.mx.RUnlock()
+
+		for _, rc := range ops {
+			if !fn(rc) {
+				return
+			}
+		}
+	}
+}
+
+// Stats gets the connection and operation counters
+func (c *ChanMgr) Stats() ChanMgrStats {
+	return ChanMgrStats{
+		Connections:       c.connections.Load(),
+		Operations:        c.operations.Load(),
+		ConnectionsOpened: c.connectionsOpened.Load(),
+		ConnectionsClosed: c.connectionsClosed.Load(),
+		OperationsStarted: c.operationsStarted.Load(),
+		OperationsStopped: c.operationsStopped.Load(),
+	}
+}
+
+// ConnID gets the connection id
+func (rc *ResultChan) ConnID() string {
+	return rc.cid
+}
+
+// OperationID gets the operation id
+func (rc *ResultChan) OperationID() string {
+	return rc.oid
+}
+
+// Context gets the operation context
+func (rc *ResultChan) Context() context.Context {
+	return rc.ctx
+}
+
+func (rc *ResultChan) cancel() {
+	if rc.cancelFunc != nil {
+		rc.cancelFunc()
+	}
+}
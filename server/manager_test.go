@@ -0,0 +1,145 @@
+package server
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+func newTestResultChan(cid, oid string) *ResultChan {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ResultChan{
+		ctx:        ctx,
+		cancelFunc: cancel,
+		cid:        cid,
+		oid:        oid,
+	}
+}
+
+func TestChanMgrCleanup(t *testing.T) {
+	mgr := NewChanMgr()
+	mgr.OpenConn("a")
+	mgr.OpenConn("b")
+	mgr.OpenConn("idle")
+
+	a1 := newTestResultChan("a", "1")
+	a2 := newTestResultChan("a", "2")
+	b1 := newTestResultChan("b", "1")
+	mgr.Add(a1)
+	mgr.Add(a2)
+	mgr.Add(b1)
+
+	if stats := mgr.Stats(); stats.Connections != 3 || stats.Operations != 3 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	// replacing an operation cancels the previous one and a stale remove is ignored
+	replacement := newTestResultChan("a", "1")
+	mgr.Add(replacement)
+	if a1.ctx.Err() == nil {
+		t.Error("expected the replaced operation to be cancelled")
+	}
+	if mgr.Remove(a1) {
+		t.Error("expected removing a replaced operation to be ignored")
+	}
+	if rc, ok := mgr.Get("a", "1"); !ok || rc != replacement {
+		t.Error("expected the replacement operation to be stored")
+	}
+
+	if !mgr.DelConn("a") {
+		t.Fatal("expected connection a to be deleted")
+	}
+	if replacement.ctx.Err() == nil || a2.ctx.Err() == nil {
+		t.Error("expected the operations of connection a to be cancelled")
+	}
+	if mgr.DelConn("a") {
+		t.Error("expected connection a to be removed")
+	}
+
+	if !mgr.Del("b", "1") || b1.ctx.Err() == nil {
+		t.Error("expected operation b/1 to be deleted and cancelled")
+	}
+
+	// connections stay open without operations and restarting an operation
+	// does not reopen them
+	b2 := newTestResultChan("b", "2")
+	mgr.Add(b2)
+	mgr.Remove(b2)
+	if stats := mgr.Stats(); stats.Connections != 2 || stats.ConnectionsOpened != 3 || stats.Operations != 0 {
+		t.Errorf("unexpected stats %+v", mgr.Stats())
+	}
+	if !mgr.DelConn("b") || !mgr.DelConn("idle") {
+		t.Error("expected the connections without operations to be closed")
+	}
+
+	for i := range mgr.shards {
+		if n := len(mgr.shards[i].conns) + len(mgr.shards[i].open); n != 0 {
+			t.Errorf("expected shard %d to be empty, found %d connections", i, n)
+		}
+	}
+
+	expected := ChanMgrStats{
+		ConnectionsOpened: 3,
+		ConnectionsClosed: 3,
+		OperationsStarted: 5,
+		OperationsStopped: 5,
+	}
+	if stats := mgr.Stats(); stats != expected {
+		t.Errorf("expected stats %+v, got %+v", expected, stats)
+	}
+}
+
+func BenchmarkChanMgrAddRemove(b *testing.B) {
+	mgr := NewChanMgr()
+	var id atomic.Int64
+
+	b.RunParallel(func(pb *testing.PB) {
+		cid := strconv.FormatInt(id.Add(1), 10)
+		i := 0
+		for pb.Next() {
+			rc := &ResultChan{cid: cid, oid: strconv.Itoa(i % 8)}
+			mgr.Add(rc)
+			mgr.Remove(rc)
+			i++
+		}
+	})
+}
+
+func BenchmarkChanMgrConnections(b *testing.B) {
+	mgr := NewChanMgr()
+	var id atomic.Int64
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			cid := strconv.FormatInt(id.Add(1), 10)
+			mgr.Add(&ResultChan{cid: cid, oid: "1"})
+			mgr.Add(&ResultChan{cid: cid, oid: "2"})
+			mgr.DelConn(cid)
+		}
+	})
+}
+
+func BenchmarkChanMgrBroadcast(b *testing.B) {
+	mgr := NewChanMgr()
+	for i := 0; i < 10000; i++ {
+		mgr.Add(&ResultChan{cid: strconv.Itoa(i), oid: "1"})
+	}
+
+	var id atomic.Int64
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		cid := "bench-" + strconv.FormatInt(id.Add(1), 10)
+		for pb.Next() {
+			// broadcast while connections are added and removed
+			count := 0
+			mgr.Range(func(rc *ResultChan) bool {
+				count++
+				return true
+			})
+			rc := &ResultChan{cid: cid, oid: "1"}
+			mgr.Add(rc)
+			mgr.Remove(rc)
+		}
+	})
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// PersistedQueryStore looks up persisted queries by their sha256 hash
+type PersistedQueryStore interface {
+	Get(ctx context.Context, hash string) (query string, found bool, err error)
+}
+
+// PersistedQueryOptions persisted query options. Clients send the hash in the
+// request extensions as {"persistedQuery": {"sha256Hash": "..."}}
+type PersistedQueryOptions struct {
+	Store PersistedQueryStore
+	Only  bool // reject queries that are not in the store
+}
+
+// PersistedQueryMap an in-memory persisted query store of hash to query
+type PersistedQueryMap map[string]string
+
+// Get gets a query by hash
+func (m PersistedQueryMap) Get(ctx context.Context, hash string) (string, bool, error) {
+	query, ok := m[hash]
+	return query, ok, nil
+}
+
+// LoadPersistedQueries loads a JSON object of hash to query from a file
+func LoadPersistedQueries(path string) (PersistedQueryMap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	queries := PersistedQueryMap{}
+	if err := json.Unmarshal(data, &queries); err != nil {
+		return nil, fmt.Errorf("failed to parse persisted queries %s: %v", path, err)
+	}
+	return queries, nil
+}
+
+// HashQuery returns the sha256 hash of a query used by persisted queries
+func HashQuery(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:])
+}
+
+// gets the persisted query hash from the request extensions
+func persistedQueryHash(extensions map[string]interface{}) string {
+	persisted, ok := extensions["persistedQuery"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	hash, _ := persisted["sha256Hash"].(string)
+	return hash
+}
+
+// replaces the query of a persisted query request
+func (s *Server) resolvePersistedQuery(ctx context.Context, opts *RequestOptions) error {
+	persisted := s.options.PersistedQueries
+	if persisted == nil || persisted.Store == nil {
+		return nil
+	}
+
+	hash := persistedQueryHash(opts.Extensions)
+	if hash == "" {
+		if persisted.Only {
+			return errors.New("only persisted queries are allowed")
+		}
+		return nil
+	}
+
+	if opts.Query != "" && HashQuery(opts.Query) != hash {
+		return errors.New("provided sha does not match query")
+	}
+
+	query, found, err := persisted.Store.Get(ctx, hash)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case found:
+		opts.Query = query
+	case opts.Query == "" || persisted.Only:
+		return errors.New("PersistedQueryNotFound")
+	}
+	return nil
+}
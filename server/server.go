@@ -4,10 +4,12 @@ import (
 	"context"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/graphql-go/graphql"
 	"github.com/graphql-go/graphql/gqlerrors"
+	tools "github.com/rohit20001221/graphql-go-tools"
 	"github.com/rohit20001221/graphql-go-tools/server/graphqlws"
 	"github.com/rohit20001221/graphql-go-tools/server/logger"
 )
@@ -19,15 +21,19 @@ const (
 	ContentTypeFormURLEncoded = "application/x-www-form-urlencoded"
 )
 
+// DefaultEndpoint the default path of the graphql endpoint
+const DefaultEndpoint = "/graphql"
+
 // ConnKey the connection key
 var ConnKey interface{} = "conn"
 
 type Server struct {
-	schema   graphql.Schema
-	log      logger.Logger
-	options  *Options
-	upgrader websocket.Upgrader
-	mgr      *ChanMgr
+	schema    graphql.Schema
+	log       logger.Logger
+	options   *Options
+	upgrader  websocket.Upgrader
+	mgr       *ChanMgr
+	admission *admissionController
 }
 
 func New(schema graphql.Schema, options *Options) *Server {
@@ -35,7 +41,7 @@ func New(schema graphql.Schema, options *Options) *Server {
 		options.Logger = &logger.NoopLogger{}
 	}
 
-	return &Server{
+	s := &Server{
 		schema:  schema,
 		log:     options.Logger,
 		options: options,
@@ -43,10 +49,14 @@ func New(schema graphql.Schema, options *Options) *Server {
 			CheckOrigin:  func(r *http.Request) bool { return true },
 			Subprotocols: []string{"graphql-ws"},
 		},
-		mgr: &ChanMgr{
-			conns: make(map[string]map[string]*ResultChan),
-		},
+		mgr: NewChanMgr(),
 	}
+
+	if options.Admission != nil {
+		s.admission = newAdmissionController(options.Admission)
+	}
+
+	return s
 }
 
 type RootValueFunc func(ctx context.Context, r *http.Request) map[string]interface{}
@@ -68,10 +78,27 @@ type Options struct {
 	WS                 *WSOptions
 	Playground         *PlaygroundOptions
 	GraphiQL           *GraphiQLOptions
+	Container          *tools.Container // services resolved with tools.Get, scoped per request or websocket operation
+	CORS               *CORSOptions
+	MaxBodyBytes       int64 // maximum request body size, unlimited when zero
+	MaxQueryLength     int   // maximum query length, unlimited when zero
+	PersistedQueries   *PersistedQueryOptions
+	Endpoint           string // path of the graphql endpoint served by Handler, defaults to /graphql
+	HealthEndpoint     string // optional path of a health check endpoint served by Handler
+	Admission          *AdmissionOptions
+	OrderedResponses   bool              // encode response keys in selection set order
+	Fragments          *FragmentRegistry // shared fragments appended to documents spreading them
 }
 
 type WSOptions struct {
-	AuthenticateFunc graphqlws.AuthenticateFunc
+	AuthenticateFunc      graphqlws.AuthenticateFunc
+	ReadLimit             int64         // maximum message size, defaults to 4096 bytes
+	ConnectionInitTimeout time.Duration // close connections not initialized within the timeout
+	KeepAliveInterval     time.Duration // interval of keep alive messages
+
+	// StartOperationFunc is called with the connection context before each
+	// operation starts, an error rejects the operation
+	StartOperationFunc func(ctx context.Context) error
 }
 
 func IsWSUpgrade(r *http.Request) bool {
@@ -82,7 +109,16 @@ func IsWSUpgrade(r *http.Request) bool {
 
 // ServeHTTP provides an entrypoint into executing graphQL queries.
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.options.CORS != nil && s.handleCORS(w, r) {
+		return
+	}
+
 	if IsWSUpgrade(r) {
+		if s.options.WS == nil {
+			http.Error(w, "websocket subscriptions are not enabled", http.StatusBadRequest)
+			return
+		}
+
 		s.log.Debugf("Upgrading connection to websocket")
 		ctx := r.Context()
 		if s.options.WSContextFunc != nil {
@@ -97,3 +133,27 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		s.ContextHandler(ctx, w, r)
 	}
 }
+
+// ConnectionStats gets the counters of acknowledged websocket connections and
+// their subscription operations
+func (s *Server) ConnectionStats() ChanMgrStats {
+	return s.mgr.Stats()
+}
+
+// Handler serves the graphql endpoint and the optional health endpoint
+func (s *Server) Handler() http.Handler {
+	endpoint := s.options.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(endpoint, s)
+	if s.options.HealthEndpoint != "" {
+		mux.HandleFunc(s.options.HealthEndpoint, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.Write([]byte("ok"))
+		})
+	}
+	return mux
+}
@@ -0,0 +1,215 @@
+package tools
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+)
+
+// StrictResultError a resolver result that does not fit the field type
+type StrictResultError struct {
+	Path    string      // response path of the invalid value
+	Field   string      // Type.field that returned the value
+	Value   interface{} // the offending value
+	Message string
+}
+
+// Error returns the error message
+func (e *StrictResultError) Error() string {
+	return fmt.Sprintf("strict result: %s at %q %s, got %T(%#v)", e.Field, e.Path, e.Message, e.Value, e.Value)
+}
+
+// wraps the resolvers of each object field to validate their results against
+// the field type, used in development to find values graphql-go would
+// otherwise silently complete as null
+func validateResults(schema graphql.Schema) {
+	for name, t := range schema.TypeMap() {
+		object, ok := t.(*graphql.Object)
+		if !ok || strings.HasPrefix(name, "__") {
+			continue
+		}
+
+		for _, field := range object.Fields() {
+			field.Resolve = strictResolveFn(object.Name(), field)
+		}
+	}
+}
+
+// creates a resolve function validating the result of the field resolver
+func strictResolveFn(typeName string, field *graphql.FieldDefinition) graphql.FieldResolveFn {
+	resolve := field.Resolve
+	defaultResolve := resolve == nil || isDefaultResolveFn(resolve)
+	if resolve == nil {
+		resolve = graphql.DefaultResolveFn
+	}
+
+	fieldName := typeName + "." + field.Name
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		result, err := resolve(p)
+		if err != nil {
+			return result, err
+		}
+
+		v := &resultValidator{params: p, field: fieldName}
+
+		// a missing map key resolves to null with the default resolver
+		if _, ok := field.Type.(*graphql.NonNull); ok && defaultResolve {
+			if source, ok := p.Source.(map[string]interface{}); ok {
+				if _, ok := source[field.Name]; !ok {
+					return nil, v.error(responsePathString(p.Info.Path), p.Source, fmt.Sprintf("non-null field %q is missing from the source map", field.Name))
+				}
+			}
+		}
+
+		// validate the results of thunks when they are called
+		if thunk, ok := result.(func() (interface{}, error)); ok {
+			return func() (interface{}, error) {
+				result, err := thunk()
+				if err != nil {
+					return result, err
+				}
+				return result, v.validate(field.Type, result, responsePathString(p.Info.Path))
+			}, nil
+		}
+
+		return result, v.validate(field.Type, result, responsePathString(p.Info.Path))
+	}
+}
+
+// validates a resolver result
+type resultValidator struct {
+	params graphql.ResolveParams
+	field  string
+}
+
+func (v *resultValidator) error(path string, value interface{}, message string) error {
+	return &StrictResultError{
+		Path:    path,
+		Field:   v.field,
+		Value:   value,
+		Message: message,
+	}
+}
+
+// validates a value against an output type
+func (v *resultValidator) validate(t graphql.Type, value interface{}, path string) error {
+	if nonNull, ok := t.(*graphql.NonNull); ok {
+		if isNullValue(value) {
+			return v.error(path, value, fmt.Sprintf("returned null for non-null type %s", nonNull))
+		}
+		return v.validate(nonNull.OfType, value, path)
+	}
+
+	if isNullValue(value) {
+		return nil
+	}
+
+	switch t := t.(type) {
+	case *graphql.List:
+		rv := reflect.ValueOf(value)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			return v.error(path, value, fmt.Sprintf("expected a slice or array for list type %s", t))
+		}
+		for i := 0; i < rv.Len(); i++ {
+			if err := v.validate(t.OfType, rv.Index(i).Interface(), fmt.Sprintf("%s.%d", path, i)); err != nil {
+				return err
+			}
+		}
+
+	case *graphql.Scalar:
+		if t.Serialize(value) == nil {
+			return v.error(path, value, fmt.Sprintf("cannot be serialized by scalar %s", t.Name()))
+		}
+
+	case *graphql.Enum:
+		if t.Serialize(value) == nil {
+			values := []string{}
+			for _, def := range t.Values() {
+				values = append(values, def.Name)
+			}
+			return v.error(path, value, fmt.Sprintf("is not a value of enum %s, expected the value of one of %s", t.Name(), strings.Join(values, ", ")))
+		}
+
+	case *graphql.Object:
+		if t.IsTypeOf != nil && !t.IsTypeOf(graphql.IsTypeOfParams{
+			Value:   value,
+			Info:    v.params.Info,
+			Context: v.params.Context,
+		}) {
+			return v.error(path, value, fmt.Sprintf("is not of type %s", t.Name()))
+		}
+
+	case graphql.Abstract:
+		if v.resolveType(t, value) == nil {
+			return v.error(path, value, fmt.Sprintf("does not resolve to a possible type of %s", t))
+		}
+	}
+
+	return nil
+}
+
+// resolves the runtime type of an abstract value
+func (v *resultValidator) resolveType(abstract graphql.Abstract, value interface{}) *graphql.Object {
+	params := graphql.ResolveTypeParams{
+		Value:   value,
+		Info:    v.params.Info,
+		Context: v.params.Context,
+	}
+
+	var object *graphql.Object
+	switch t := abstract.(type) {
+	case *graphql.Union:
+		if t.ResolveType != nil {
+			object = t.ResolveType(params)
+		}
+	case *graphql.Interface:
+		if t.ResolveType != nil {
+			object = t.ResolveType(params)
+		}
+	}
+
+	schema := v.params.Info.Schema
+	if object != nil {
+		if !schema.IsPossibleType(abstract, object) {
+			return nil
+		}
+		return object
+	}
+
+	for _, possibleType := range schema.PossibleTypes(abstract) {
+		if possibleType.IsTypeOf != nil && possibleType.IsTypeOf(graphql.IsTypeOfParams{
+			Value:   value,
+			Info:    v.params.Info,
+			Context: v.params.Context,
+		}) {
+			return possibleType
+		}
+	}
+
+	return nil
+}
+
+// determines if a value is nil or a nil pointer, map, slice or interface
+func isNullValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface, reflect.Func, reflect.Chan:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// formats a response path as a dot separated string
+func responsePathString(path *graphql.ResponsePath) string {
+	keys := []string{}
+	for p := path; p != nil; p = p.Prev {
+		keys = append([]string{fmt.Sprintf("%v", p.Key)}, keys...)
+	}
+	return strings.Join(keys, ".")
+}
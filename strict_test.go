@@ -0,0 +1,111 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+)
+
+func TestStrictResults(t *testing.T) {
+	type user struct {
+		Name string
+	}
+
+	schema, err := MakeExecutableSchema(ExecutableSchema{
+		TypeDefs: `
+enum Role {
+	ADMIN
+	USER
+}
+
+interface Node {
+	id: ID!
+}
+
+type Post implements Node {
+	id: ID!
+	title: String!
+}
+
+type Query {
+	role: Role
+	roles: [Role]
+	count: Int
+	post: Post
+	node: Node
+}`,
+		StrictResults: true,
+		Resolvers: ResolverMap{
+			"Role": &EnumResolver{
+				Values: map[string]interface{}{
+					"ADMIN": "admin",
+					"USER":  "user",
+				},
+			},
+			"Node": &InterfaceResolver{
+				ResolveType: func(p graphql.ResolveTypeParams) *graphql.Object {
+					if _, ok := p.Value.(map[string]interface{}); ok {
+						return p.Info.Schema.Type("Post").(*graphql.Object)
+					}
+					return nil
+				},
+			},
+			"Query": &ObjectResolver{
+				Fields: FieldResolveMap{
+					"role": &FieldResolve{
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							return "superuser", nil
+						},
+					},
+					"roles": &FieldResolve{
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							return []string{"admin", "guest"}, nil
+						},
+					},
+					"count": &FieldResolve{
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							return "many", nil
+						},
+					},
+					"post": &FieldResolve{
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							return map[string]interface{}{"id": "1"}, nil
+						},
+					},
+					"node": &FieldResolve{
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							return &user{Name: "bob"}, nil
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		`{ role }`:           `Query.role at "role" is not a value of enum Role`,
+		`{ roles }`:          `Query.roles at "roles.1" is not a value of enum Role`,
+		`{ count }`:          `Query.count at "count" cannot be serialized by scalar Int`,
+		`{ post { title } }`: `Post.title at "post.title" non-null field "title" is missing from the source map`,
+		`{ node { id } }`:    `Query.node at "node" does not resolve to a possible type of Node, got *tools.user`,
+	}
+
+	if r := graphql.Do(graphql.Params{Schema: schema, RequestString: `{ post { id } }`}); r.HasErrors() {
+		t.Errorf("expected valid results to pass, got %v", r.Errors)
+	}
+
+	for query, expected := range tests {
+		r := graphql.Do(graphql.Params{Schema: schema, RequestString: query})
+		if len(r.Errors) != 1 {
+			t.Errorf("%s: expected 1 error, got %v", query, r.Errors)
+			continue
+		}
+		if !strings.Contains(r.Errors[0].Message, expected) {
+			t.Errorf("%s: expected error containing %q, got %q", query, expected, r.Errors[0].Message)
+		}
+	}
+}
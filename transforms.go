@@ -0,0 +1,137 @@
+package tools
+
+import (
+	"fmt"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/kinds"
+)
+
+// built-in transforms run after any user supplied DocumentTransforms
+var builtinDocumentTransforms = []func(*ast.Document) (*ast.Document, error){
+	expandRelayMutations,
+	expandAsyncJobs,
+	expandErrorsAsData,
+}
+
+// applies the user supplied and built-in document transforms
+func (c *ExecutableSchema) transformDocument(document *ast.Document) (*ast.Document, error) {
+	transforms := append([]func(*ast.Document) (*ast.Document, error){}, c.DocumentTransforms...)
+	transforms = append(transforms, builtinDocumentTransforms...)
+
+	for i, transform := range transforms {
+		transformed, err := transform(document)
+		if err != nil {
+			return nil, err
+		}
+		if transformed == nil {
+			return nil, fmt.Errorf("document transform %d returned a nil document", i)
+		}
+		document = transformed
+	}
+
+	return document, nil
+}
+
+// ExpandDocument applies the built-in document transforms, adding the types
+// and fields generated by @relayMutation, @asyncJob and @errorsAsData
+func ExpandDocument(document *ast.Document) (*ast.Document, error) {
+	return (&ExecutableSchema{}).transformDocument(document)
+}
+
+// FindDefinition finds a type or directive definition by name, type extensions are not returned
+func FindDefinition(document *ast.Document, name string) ast.Node {
+	for _, def := range document.Definitions {
+		if def.GetKind() != kinds.TypeExtensionDefinition && getNodeName(def) == name {
+			return def
+		}
+	}
+	return nil
+}
+
+// FindExtensions finds the type extensions for a type name
+func FindExtensions(document *ast.Document, name string) []*ast.TypeExtensionDefinition {
+	extensions := []*ast.TypeExtensionDefinition{}
+	for _, def := range document.Definitions {
+		if ext, ok := def.(*ast.TypeExtensionDefinition); ok && ext.Definition.Name.Value == name {
+			extensions = append(extensions, ext)
+		}
+	}
+	return extensions
+}
+
+// AddDefinition adds a definition to the document. Type and directive definitions
+// must have a name that is not already defined, type extensions are always added
+func AddDefinition(document *ast.Document, definition ast.Node) error {
+	if definition == nil {
+		return fmt.Errorf("cannot add a nil definition")
+	}
+
+	switch definition.GetKind() {
+	case kinds.TypeExtensionDefinition:
+	case kinds.SchemaDefinition:
+		for _, def := range document.Definitions {
+			if def.GetKind() == kinds.SchemaDefinition {
+				return fmt.Errorf("document already contains a schema definition")
+			}
+		}
+	default:
+		name := getNodeName(definition)
+		if name == "" {
+			return fmt.Errorf("cannot add a definition of kind %s", definition.GetKind())
+		}
+		if FindDefinition(document, name) != nil {
+			return fmt.Errorf("definition %q already exists", name)
+		}
+	}
+
+	document.Definitions = append(document.Definitions, definition)
+	return nil
+}
+
+// RemoveDefinition removes a type or directive definition along with any type
+// extensions of it. Returns false if no definition was found
+func RemoveDefinition(document *ast.Document, name string) bool {
+	found := false
+	definitions := []ast.Node{}
+
+	for _, def := range document.Definitions {
+		switch d := def.(type) {
+		case *ast.TypeExtensionDefinition:
+			if d.Definition.Name.Value == name {
+				continue
+			}
+		default:
+			if getNodeName(def) == name {
+				found = true
+				continue
+			}
+		}
+		definitions = append(definitions, def)
+	}
+
+	document.Definitions = definitions
+	return found
+}
+
+// ReplaceDefinition replaces the type or directive definition with the same name,
+// the definition keeps its position in the document
+func ReplaceDefinition(document *ast.Document, definition ast.Node) error {
+	if definition == nil {
+		return fmt.Errorf("cannot replace with a nil definition")
+	}
+
+	name := getNodeName(definition)
+	if name == "" {
+		return fmt.Errorf("cannot replace a definition of kind %s", definition.GetKind())
+	}
+
+	for i, def := range document.Definitions {
+		if def.GetKind() != kinds.TypeExtensionDefinition && getNodeName(def) == name {
+			document.Definitions[i] = definition
+			return nil
+		}
+	}
+
+	return fmt.Errorf("definition %q not found", name)
+}
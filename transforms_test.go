@@ -0,0 +1,108 @@
+package tools
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+func TestDocumentTransforms(t *testing.T) {
+	typeDefs := `
+type Foo {
+	name: String
+}
+
+type Bar {
+	name: String
+}
+
+extend type Bar {
+	id: ID
+}
+
+type Query {
+	foo: Foo
+}`
+
+	schema, err := MakeExecutableSchema(ExecutableSchema{
+		TypeDefs: typeDefs,
+		DocumentTransforms: []func(*ast.Document) (*ast.Document, error){
+			func(document *ast.Document) (*ast.Document, error) {
+				if !RemoveDefinition(document, "Bar") {
+					t.Error("expected Bar to be removed")
+				}
+				if len(FindExtensions(document, "Bar")) != 0 {
+					t.Error("expected Bar extensions to be removed")
+				}
+				return document, nil
+			},
+			func(document *ast.Document) (*ast.Document, error) {
+				foo, ok := FindDefinition(document, "Foo").(*ast.ObjectDefinition)
+				if !ok {
+					t.Fatal("expected to find Foo")
+				}
+
+				foo.Fields = append(foo.Fields, ast.NewFieldDefinition(&ast.FieldDefinition{
+					Name: ast.NewName(&ast.Name{Value: "id"}),
+					Type: ast.NewNamed(&ast.Named{Name: ast.NewName(&ast.Name{Value: "ID"})}),
+				}))
+
+				if err := AddDefinition(document, foo); err == nil {
+					t.Error("expected an error adding a duplicate definition")
+				}
+
+				return document, ReplaceDefinition(document, foo)
+			},
+		},
+		Resolvers: ResolverMap{
+			"Query": &ObjectResolver{
+				Fields: FieldResolveMap{
+					"foo": &FieldResolve{
+						Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+							return map[string]interface{}{"id": "1", "name": "foo"}, nil
+						},
+					},
+				},
+			},
+		},
+	})
+
+	if err != nil {
+		t.Fatalf("failed to make schema: %v", err)
+	}
+
+	if _, ok := schema.TypeMap()["Bar"]; ok {
+		t.Error("expected Bar to be removed from the schema")
+	}
+
+	result := graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: `{ foo { id name } }`,
+	})
+
+	if result.HasErrors() {
+		t.Fatalf("unexpected errors: %v", result.Errors)
+	}
+
+	foo := result.Data.(map[string]interface{})["foo"].(map[string]interface{})
+	if foo["id"] != "1" {
+		t.Errorf("expected id 1, got %v", foo["id"])
+	}
+}
+
+func TestDocumentTransformError(t *testing.T) {
+	_, err := MakeExecutableSchema(ExecutableSchema{
+		TypeDefs: `type Query { foo: String }`,
+		DocumentTransforms: []func(*ast.Document) (*ast.Document, error){
+			func(document *ast.Document) (*ast.Document, error) {
+				return nil, errors.New("transform failed")
+			},
+		},
+	})
+
+	if err == nil || err.Error() != "transform failed" {
+		t.Errorf("expected transform error, got %v", err)
+	}
+}
@@ -8,7 +8,8 @@ import (
 	"github.com/graphql-go/graphql/language/kinds"
 )
 
-// builds a scalar from ast
+// builds a scalar from ast. Without a ScalarResolver the scalar is bound to an
+// imported or built-in scalar with the same name or one from package scalars
 func (c *registry) buildScalarFromAST(definition *ast.ScalarDefinition) error {
 	name := definition.Name.Value
 	scalarConfig := graphql.ScalarConfig{
@@ -20,6 +21,27 @@ func (c *registry) buildScalarFromAST(definition *ast.ScalarDefinition) error {
 		scalarConfig.ParseLiteral = r.(*ScalarResolver).ParseLiteral
 		scalarConfig.ParseValue = r.(*ScalarResolver).ParseValue
 		scalarConfig.Serialize = r.(*ScalarResolver).Serialize
+	} else {
+		scalar, ok := c.types[name].(*graphql.Scalar)
+		if !ok {
+			scalar, ok = namedScalars[name]
+		}
+		if !ok {
+			return fmt.Errorf("no resolver found for scalar %q, supply a *ScalarResolver or *graphql.Scalar", name)
+		}
+
+		// keep the existing scalar unless directives may modify it
+		if len(definition.Directives) == 0 {
+			c.types[name] = scalar
+			return nil
+		}
+
+		if scalarConfig.Description == "" {
+			scalarConfig.Description = scalar.Description()
+		}
+		scalarConfig.Serialize = scalar.Serialize
+		scalarConfig.ParseValue = scalar.ParseValue
+		scalarConfig.ParseLiteral = scalar.ParseLiteral
 	}
 
 	if err := c.applyDirectives(applyDirectiveParams{
@@ -85,6 +107,8 @@ func (c *registry) buildEnumValueFromAST(definition *ast.EnumValueDefinition, en
 		config:     &valueConfig,
 		directives: definition.Directives,
 		node:       definition,
+		parentName: enumName,
+		parentKind: kinds.EnumDefinition,
 	}); err != nil {
 		return nil, err
 	}
@@ -105,7 +129,7 @@ func (c *registry) buildInputObjectFromAST(definition *ast.InputObjectDefinition
 	// use thunks only when allowed
 	if _, ok := c.dependencyMap[name]; ok {
 		var fields graphql.InputObjectConfigFieldMapThunk = func() graphql.InputObjectConfigFieldMap {
-			fieldMap, err := c.buildInputObjectFieldMapFromAST(definition.Fields)
+			fieldMap, err := c.buildInputObjectFieldMapFromAST(definition.Fields, name)
 			if err != nil {
 				return nil
 			}
@@ -113,7 +137,7 @@ func (c *registry) buildInputObjectFromAST(definition *ast.InputObjectDefinition
 		}
 		inputConfig.Fields = fields
 	} else {
-		fieldMap, err := c.buildInputObjectFieldMapFromAST(definition.Fields)
+		fieldMap, err := c.buildInputObjectFieldMapFromAST(definition.Fields, name)
 		if err != nil {
 			return err
 		}
@@ -133,10 +157,10 @@ func (c *registry) buildInputObjectFromAST(definition *ast.InputObjectDefinition
 }
 
 // builds an input object field map from ast
-func (c *registry) buildInputObjectFieldMapFromAST(fields []*ast.InputValueDefinition) (graphql.InputObjectConfigFieldMap, error) {
+func (c *registry) buildInputObjectFieldMapFromAST(fields []*ast.InputValueDefinition, inputName string) (graphql.InputObjectConfigFieldMap, error) {
 	fieldMap := graphql.InputObjectConfigFieldMap{}
 	for _, fieldDef := range fields {
-		field, err := c.buildInputObjectFieldFromAST(fieldDef)
+		field, err := c.buildInputObjectFieldFromAST(fieldDef, inputName)
 		if err != nil {
 			return nil, err
 		}
@@ -146,7 +170,7 @@ func (c *registry) buildInputObjectFieldMapFromAST(fields []*ast.InputValueDefin
 }
 
 // builds an input object field from an AST
-func (c *registry) buildInputObjectFieldFromAST(definition *ast.InputValueDefinition) (*graphql.InputObjectFieldConfig, error) {
+func (c *registry) buildInputObjectFieldFromAST(definition *ast.InputValueDefinition, inputName string) (*graphql.InputObjectFieldConfig, error) {
 	inputType, err := c.buildComplexType(definition.Type)
 	if err != nil {
 		return nil, err
@@ -167,6 +191,8 @@ func (c *registry) buildInputObjectFieldFromAST(definition *ast.InputValueDefini
 		config:     &field,
 		directives: definition.Directives,
 		node:       definition,
+		parentName: inputName,
+		parentKind: kinds.InputObjectDefinition,
 	}); err != nil {
 		return nil, err
 	}
@@ -348,8 +374,8 @@ func (c *registry) buildInterfaceFromAST(definition *ast.InterfaceDefinition) er
 	return nil
 }
 
-// builds an arg from an ast
-func (c *registry) buildArgFromAST(definition *ast.InputValueDefinition) (*graphql.ArgumentConfig, error) {
+// builds an arg from an ast, the parent is the type or directive defining it
+func (c *registry) buildArgFromAST(definition *ast.InputValueDefinition, parentKind, parentName, fieldName string) (*graphql.ArgumentConfig, error) {
 	inputType, err := c.buildComplexType(definition.Type)
 	if err != nil {
 		return nil, err
@@ -370,6 +396,9 @@ func (c *registry) buildArgFromAST(definition *ast.InputValueDefinition) (*graph
 		config:     &arg,
 		directives: definition.Directives,
 		node:       definition,
+		fieldName:  fieldName,
+		parentName: parentName,
+		parentKind: parentKind,
 	}); err != nil {
 		return nil, err
 	}
@@ -395,7 +424,7 @@ func (c *registry) buildFieldFromAST(definition *ast.FieldDefinition, kind, type
 
 	for _, arg := range definition.Arguments {
 		if arg != nil {
-			argValue, err := c.buildArgFromAST(arg)
+			argValue, err := c.buildArgFromAST(arg, kind, typeName, definition.Name.Value)
 			if err != nil {
 				return nil, err
 			}